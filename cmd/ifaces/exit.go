@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/services/annotations"
+	"github.com/dexterp/ifaces/internal/services/decouple"
+	"github.com/dexterp/ifaces/internal/services/dupes"
+	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/dexterp/ifaces/internal/services/implements"
+	"github.com/dexterp/ifaces/internal/services/narrow"
+	"github.com/dexterp/ifaces/internal/services/runner"
+)
+
+// Exit codes by error class, see the README.
+const (
+	exitOK       = 0 // success
+	exitError    = 1 // errors without a class, E.G. invalid templates
+	exitUsage    = 2 // invalid command line options or directive options
+	exitSource   = 3 // source files or specs can not be read or parsed
+	exitNotFound = 4 // no type or method matches the options or directive
+	exitOutput   = 5 // output files can not be read or written
+	exitPlugin   = 6 // a plugin failed or returned invalid files
+)
+
+var (
+	errOutput = errors.New(`can not write to output`)
+	errSource = errors.New(`can not read source`)
+)
+
+// exitCode returns the exit code of the error class of err
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, generate.ErrTypeNotFound), errors.Is(err, generate.ErrRecvNotFound),
+		errors.Is(err, narrow.ErrFuncNotFound), errors.Is(err, narrow.ErrParamNotFound), errors.Is(err, narrow.ErrNoMethods),
+		errors.Is(err, decouple.ErrIfaceNotFound), errors.Is(err, decouple.ErrNoSites),
+		errors.Is(err, implements.ErrIfaceNotFound), errors.Is(err, implements.ErrTypeNotFound),
+		errors.Is(err, dupes.ErrIfaceNotFound), errors.Is(err, dupes.ErrNoDupes):
+		return exitNotFound
+	case errors.Is(err, generate.ErrPlugin), errors.Is(err, generate.ErrPluginFileName):
+		return exitPlugin
+	case errors.Is(err, errOutput):
+		return exitOutput
+	case errors.Is(err, errSource), errors.Is(err, generate.ErrSpec), errors.Is(err, generate.ErrSpecVersion), hasCode(err, diag.CodeParse):
+		return exitSource
+	case hasCode(err, diag.CodeUsage),
+		errors.Is(err, runner.ErrNoCommand), errors.Is(err, runner.ErrSubCommand), errors.Is(err, runner.ErrNoOutput),
+		errors.Is(err, annotations.ErrNoIfaceName):
+		return exitUsage
+	}
+	return exitError
+}
+
+// hasCode returns true if a diagnostic of err has code
+func hasCode(err error, code string) bool {
+	var l diag.List
+	var d *diag.Diagnostic
+	if errors.As(err, &l) {
+		for _, d := range l {
+			if d.Code == code {
+				return true
+			}
+		}
+	} else if errors.As(err, &d) {
+		return d.Code == code
+	}
+	return false
+}
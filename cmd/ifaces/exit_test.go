@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/services/annotations"
+	"github.com/dexterp/ifaces/internal/services/dupes"
+	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/dexterp/ifaces/internal/services/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, exitOK},
+		{errors.New(`invalid template`), exitError},
+		{diag.New(diag.Error, diag.CodeUsage, diag.Position{}, `no source file`), exitUsage},
+		{diag.At(diag.Position{File: `store.go`, Line: 3}, diag.Errorf(diag.CodeUsage, diag.Position{}, `bad option`)), exitUsage},
+		{fmt.Errorf(`store.go:3: %w`, runner.ErrNoCommand), exitUsage},
+		{fmt.Errorf(`store.go:3: %w: narrow`, runner.ErrSubCommand), exitUsage},
+		{fmt.Errorf(`store.go:3: %w`, runner.ErrNoOutput), exitUsage},
+		{fmt.Errorf(`store.go:5: %w`, annotations.ErrNoIfaceName), exitUsage},
+		{fmt.Errorf(`%w: store.go`, errSource), exitSource},
+		{diag.List{diag.New(diag.Error, diag.CodeParse, diag.Position{File: `store.go`, Line: 1}, `expected 'package'`)}, exitSource},
+		{fmt.Errorf(`%w: bad`, generate.ErrSpec), exitSource},
+		{diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w`, generate.ErrTypeNotFound), exitNotFound},
+		{fmt.Errorf(`store.go:3: %w`, generate.ErrRecvNotFound), exitNotFound},
+		{diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w log.Logger`, dupes.ErrNoDupes), exitNotFound},
+		{fmt.Errorf(`%w: disk full`, errOutput), exitOutput},
+		{fmt.Errorf(`gen: %w: exit status 1`, generate.ErrPlugin), exitPlugin},
+	} {
+		assert.Equal(t, tc.code, exitCode(tc.err), tc.err)
+	}
+}
@@ -3,12 +3,15 @@ package main
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/dexterp/ifaces/internal/di"
 	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
 	"github.com/dexterp/ifaces/internal/resources/envs"
 	"github.com/dexterp/ifaces/internal/resources/modinfo"
 	"github.com/dexterp/ifaces/internal/resources/print"
@@ -20,92 +23,336 @@ import (
 func main() {
 	args := getArgs()
 	di.Args = args
+	if args.Explain {
+		di.Level = print.DEBUG
+	}
+	if args.Diagnostics != `` && args.Diagnostics != diag.FormatText {
+		di.Reporter = &diag.Reporter{}
+	}
 	di.Stderr = os.Stderr
 	di.Stdout = os.Stdout
 	r := &run{
-		args:  args,
-		gen:   di.MakeIfaceGen(),
-		print: di.MakePrint(),
+		args:     args,
+		gen:      di.MakeIfaceGen(),
+		print:    di.MakePrint(),
+		reporter: di.Reporter,
+	}
+	err := r.runCmd()
+	if err != nil || r.reporter != nil {
+		fmt.Fprint(os.Stderr, r.diagnostics(err))
 	}
-	r.checkSrcs()
-	r.runGen()
+	os.Exit(exitCode(err))
 }
 
 type run struct {
-	args  *cli.Args
-	gen   generate.GenerateIface
-	print print.PrintIface
+	args     *cli.Args
+	gen      generate.GenerateIface
+	print    print.PrintIface
+	reporter *diag.Reporter
+}
+
+// runCmd runs the sub command
+func (r run) runCmd() error {
+	if r.args.CmdAnnotations {
+		return r.runAnnotations()
+	} else if r.args.CmdRun {
+		return r.runDirectives()
+	} else if r.args.CmdFromSpec {
+		return r.runFromSpec()
+	} else if r.args.CmdNarrow {
+		return r.runNarrow()
+	} else if r.args.CmdDecouple {
+		return r.runDecouple()
+	} else if r.args.CmdImplements {
+		return di.MakeImplements().List(r.args.Pkgs, os.Stdout)
+	} else if r.args.CmdUsage {
+		return di.MakeUsage().Analyse(r.args.Pkgs, os.Stdout)
+	} else if r.args.CmdDupes {
+		return r.runDupes()
+	}
+	err := r.checkSrcs()
+	if err != nil {
+		return err
+	}
+	if r.args.Plugin != `` {
+		return r.runPlugin()
+	} else if r.args.OutTmpl != `` {
+		return r.runGenFiles()
+	}
+	return r.runGen()
+}
+
+func (r run) checkSrcs() error {
+	if r.args.Src == `` && r.args.Module == `` && (envs.Gofile() == `` || envs.Goline() < 1) {
+		return diag.New(diag.Error, diag.CodeUsage, diag.Position{}, `no source file provided. needs -f <file> or -x <mod> option or to run as part of a go generator. exiting`)
+	}
+	return nil
 }
 
-func (r run) checkSrcs() {
-	if r.args.Src == `` && (envs.Gofile() == `` || envs.Goline() < 1) {
-		r.print.Fatalln(`no source file provided. needs -f <file> option or to run as part of a go generator. exiting`)
+// diagnostics returns the reported diagnostics and the diagnostics of err in
+// the --diagnostics format.
+func (r run) diagnostics(err error) string {
+	l := diag.FromError(err)
+	if r.reporter != nil {
+		l = append(r.reporter.List(), l...)
 	}
+	buf := &bytes.Buffer{}
+	_ = diag.Write(buf, r.args.Diagnostics, l)
+	return buf.String()
 }
 
-func (r run) runGen() {
-	srcsList := r.srcList()
-	curGenSrc := r.curGenSrc()
+func (r run) runGen() error {
+	srcsList, err := r.srcList()
+	if err != nil {
+		return err
+	}
+	curGenSrc, err := r.curGenSrc()
+	if err != nil {
+		return err
+	}
+	bufOutput := &bytes.Buffer{}
+	err = r.gen.Generate(srcsList, curGenSrc, r.args.Out, bufOutput)
+	if err != nil {
+		return err
+	}
+	return r.writeOutput(bufOutput)
+}
+
+// runFromSpec generates the interfaces described in a spec file.
+func (r run) runFromSpec() error {
+	f, err := os.Open(r.args.Spec)
+	if err != nil {
+		return fmt.Errorf(`%w: %s`, errSource, err.Error())
+	}
+	defer f.Close()
+	spec, err := generate.ReadSpec(f)
+	if err != nil {
+		return diag.Errorf(diag.CodeError, diag.Position{File: r.args.Spec}, `%w`, err)
+	}
+	curGenSrc, err := r.curGenSrc()
+	if err != nil {
+		return err
+	}
 	bufOutput := &bytes.Buffer{}
-	err := r.gen.Generate(srcsList, curGenSrc, r.args.Out, bufOutput)
-	r.print.HasFatalln(err)
-	var outfile io.Writer
-	var closer func()
+	err = r.gen.GenerateSpec(spec, curGenSrc, r.args.Out, bufOutput)
+	if err != nil {
+		return err
+	}
+	return r.writeOutput(bufOutput)
+}
+
+// runNarrow generates the interface a function needs from a parameter.
+func (r run) runNarrow() error {
+	curGenSrc, err := r.curGenSrc()
+	if err != nil {
+		return err
+	}
+	bufOutput := &bytes.Buffer{}
+	err = di.MakeNarrow().Generate(r.args.Pkgs, curGenSrc, r.args.Out, bufOutput)
+	if err != nil {
+		return err
+	}
+	return r.writeOutput(bufOutput)
+}
+
+// runDecouple rewrites parameters and fields to an interface and lists the
+// files in stdout.
+func (r run) runDecouple() error {
+	files, err := di.MakeDecouple().Rewrite(r.args.Pkgs)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
+}
+
+// runDupes lists the duplicate interfaces, or consolidates the duplicates of
+// --into and lists the files in stdout.
+func (r run) runDupes() error {
+	if r.args.Into == `` {
+		return di.MakeDupes().List(r.args.Pkgs, os.Stdout)
+	}
+	files, err := di.MakeDupes().Consolidate(r.args.Pkgs)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
+}
+
+// writeOutput writes the generated source to the output file and to stdout if
+// -d is set or there is no output file.
+func (r run) writeOutput(bufOutput *bytes.Buffer) error {
+	var writers []io.Writer
 	if r.args.Print || r.args.Out == `` {
-		outfile, closer = r.outWriter(r.args.Out, os.Stdout)
-	} else {
-		outfile, closer = r.outWriter(r.args.Out)
+		writers = append(writers, os.Stdout)
+	}
+	outfile, closer, err := r.outWriter(r.args.Out, writers...)
+	if err != nil {
+		return err
 	}
 	defer closer()
 	_, err = io.Copy(outfile, bufOutput)
-	r.print.HasFatalf(`can not write to output: %v`, err)
+	if err != nil {
+		return fmt.Errorf(`%w: %s`, errOutput, err.Error())
+	}
+	return nil
+}
+
+// runGenFiles writes one output file per type and lists the files in stdout.
+func (r run) runGenFiles() error {
+	current := r.curGenFile
+	if !r.args.Append {
+		current = nil
+	}
+	srcs, err := r.srcList()
+	if err != nil {
+		return err
+	}
+	files, err := r.gen.GenerateFiles(srcs, current)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
 }
 
+// runPlugin sends the parsed interfaces to a plugin, writes the files it
+// returns and lists them in stdout.
+func (r run) runPlugin() error {
+	current := r.curGenFile
+	if !r.args.Append {
+		current = nil
+	}
+	srcs, err := r.srcList()
+	if err != nil {
+		return err
+	}
+	files, err := r.gen.GeneratePlugin(srcs, r.args.Out, current)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
+}
+
+// runAnnotations generates the interfaces annotated in the packages and lists
+// the files in stdout.
+func (r run) runAnnotations() error {
+	files, err := di.MakeAnnotations(r.curGenFile).Generate(r.args.Pkgs)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
+}
+
+// runDirectives runs the ifaces go:generate directives in the packages and
+// lists the files in stdout.
+func (r run) runDirectives() error {
+	files, err := di.MakeRunner(r.curGenFile).Run(r.args.Pkgs)
+	if err != nil {
+		return err
+	}
+	return r.writeFiles(files)
+}
+
+// writeFiles writes files in name order and lists the file names in stdout.
+// Files with a nil source are removed.
+func (r run) writeFiles(files map[string]*bytes.Buffer) error {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if files[name] == nil {
+			err := os.Remove(name)
+			if err != nil {
+				return fmt.Errorf(`%w: can not remove file %s: %s`, errOutput, name, err.Error())
+			}
+			fmt.Fprintln(os.Stdout, name)
+			continue
+		}
+		var writers []io.Writer
+		if r.args.Print {
+			writers = append(writers, os.Stdout)
+		}
+		outfile, closer, err := r.outWriter(name, writers...)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(outfile, files[name])
+		closer()
+		if err != nil {
+			return fmt.Errorf(`%w: %s`, errOutput, err.Error())
+		}
+		fmt.Fprintln(os.Stdout, name)
+	}
+	return nil
+}
+
+// getArgs parses the command line arguments. Exits after printing the usage
+// with -h or with invalid arguments.
 func getArgs() *cli.Args {
 	args, err := cli.ParseArgs(os.Args[1:], version.Version, os.Stdout, os.Stderr)
 	if args == nil && err == nil {
-		os.Exit(0)
+		os.Exit(exitOK)
 	} else if err != nil {
-		os.Exit(127)
+		os.Exit(exitCode(err))
 	}
 	return args
 }
 
-func (r run) srcList() (srcs []srcio.Source) {
+func (r run) srcList() (srcs []srcio.Source, err error) {
 	added := map[string]any{}
 	path := r.args.Src
-	path = r.pathModulePrefix(path)
+	path, err = r.pathModulePrefix(path)
+	if err != nil {
+		return nil, err
+	}
 	path = r.goGeneratePath(&srcs, added, path)
-	r.expandPath(&srcs, added, path)
+	err = r.expandPath(&srcs, added, path)
+	if err != nil {
+		return nil, err
+	}
 	if srcs == nil {
-		r.print.Fatalln(`no valid source files found`)
+		return nil, fmt.Errorf(`%w: no valid source files found`, errSource)
 	}
 	return
 }
 
 // curGenSrc return the contents of any previously generated source file
-func (r run) curGenSrc() *bytes.Buffer {
+func (r run) curGenSrc() (*bytes.Buffer, error) {
+	if r.args.Out == `` || !r.args.Append {
+		return &bytes.Buffer{}, nil
+	}
+	return r.curGenFile(r.args.Out)
+}
+
+// curGenFile return the contents of a previously generated source file. An
+// empty buffer is returned if the file does not exist.
+func (r run) curGenFile(file string) (*bytes.Buffer, error) {
 	cur := &bytes.Buffer{}
-	if r.args.Out != `` && r.args.Append {
-		curFile, err := os.Open(r.args.Out)
-		if err != nil && !os.IsNotExist(err) {
-			r.print.Fatalf("error opening file %s: %v\n", r.args.Out, err)
-		} else {
-			_, err := io.Copy(cur, curFile)
-			r.print.HasFatalf("error reading %s: %v\n", r.args.Out, err)
-		}
+	curFile, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return cur, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: error opening file %s: %s", errOutput, file, err.Error())
 	}
-	return cur
+	defer curFile.Close()
+	_, err = io.Copy(cur, curFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error reading %s: %s", errOutput, file, err.Error())
+	}
+	return cur, nil
 }
 
-func (r run) outWriter(file string, writers ...io.Writer) (io.Writer, func()) {
+func (r run) outWriter(file string, writers ...io.Writer) (io.Writer, func(), error) {
 	var (
 		closers []io.Closer
 		wrtrs   []io.Writer
 	)
 	if file != `` {
 		f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0777)
-		r.print.HasFatalf("can not open file %s: %\n", file, err)
+		if err != nil {
+			return nil, nil, fmt.Errorf(`%w: can not open file %s: %s`, errOutput, file, err.Error())
+		}
 		wrtrs = append(wrtrs, f)
 		closers = append(closers, f)
 	}
@@ -117,41 +364,51 @@ func (r run) outWriter(file string, writers ...io.Writer) (io.Writer, func()) {
 		for _, c := range closers {
 			c.Close()
 		}
-	}
+	}, nil
 }
 
-// pathModulePrefix prefix the module path to the path if it exists
-func (r run) pathModulePrefix(path string) string {
-	if path == `` {
-		return path
-	}
+// pathModulePrefix prefix the module path to the path if it exists. The module
+// directory is returned if path is empty.
+func (r run) pathModulePrefix(path string) (string, error) {
 	// If Module option set, get the module path and add it as a prefix to path
 	var modpath string
 	if r.args.Module != `` {
 		dir, err := os.Getwd()
-		r.print.HasFatalln(err)
+		if err != nil {
+			return ``, err
+		}
+		// Standard library packages do not need a go.mod file
 		mi, err := modinfo.LoadFromParents(dir)
-		r.print.HasFatalf(`error loading go.mod file: %v`, err)
+		if err != nil && modinfo.IsStd(r.args.Module) {
+			mi, err = &modinfo.ModInfo{}, nil
+		}
+		if err != nil {
+			return ``, fmt.Errorf(`%w: error loading go.mod file: %s`, errSource, err.Error())
+		}
 		modpath, err = mi.GetPath(r.args.Module)
-		r.print.HasFatalf(`can not find module directory %s: %v`, r.args.Module, err)
+		if err != nil {
+			return ``, fmt.Errorf(`%w: can not find module directory %s: %s`, errSource, r.args.Module, err.Error())
+		}
 	}
-	if path != `` && modpath != `` {
+	if modpath != `` {
 		path = filepath.Join(modpath, path)
 	}
-	return path
+	return path, nil
 }
 
 // expandPath expands path to a directory
-func (r run) expandPath(srcs *[]srcio.Source, added map[string]any, path string) {
+func (r run) expandPath(srcs *[]srcio.Source, added map[string]any, path string) error {
 	if path == `` {
-		return
+		return nil
 	}
 	// Expand file path to all go source files in the directory
 	info, err := os.Stat(path)
 	if e := errors.Unwrap(err); e != nil {
 		err = e
 	}
-	r.print.HasFatalf("%s: %v\n", path, err)
+	if err != nil {
+		return fmt.Errorf(`%w: %s: %s`, errSource, path, err.Error())
+	}
 
 	path = filepath.Clean(path)
 	var dir string
@@ -167,9 +424,11 @@ func (r run) expandPath(srcs *[]srcio.Source, added map[string]any, path string)
 		dir = filepath.Dir(path)
 	}
 	matches, err := filepath.Glob(filepath.Join(dir, `*.go`))
-	r.print.HasFatalf(`error reading directory %s:`, dir, err)
+	if err != nil {
+		return fmt.Errorf(`%w: error reading directory %s: %s`, errSource, dir, err.Error())
+	}
 	for _, m := range matches {
-		if _, ok := added[m]; ok {
+		if _, ok := added[m]; ok || !srcio.Match(m) {
 			continue
 		}
 		*srcs = append(*srcs, srcio.Source{
@@ -178,6 +437,7 @@ func (r run) expandPath(srcs *[]srcio.Source, added map[string]any, path string)
 		})
 		added[m] = true
 	}
+	return nil
 }
 
 // goGeneratePath add go:generate environment variable to sources list
@@ -1,11 +1,20 @@
 package di
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
 	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/services/annotations"
+	"github.com/dexterp/ifaces/internal/services/decouple"
+	"github.com/dexterp/ifaces/internal/services/dupes"
 	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/dexterp/ifaces/internal/services/implements"
+	"github.com/dexterp/ifaces/internal/services/narrow"
+	"github.com/dexterp/ifaces/internal/services/runner"
+	"github.com/dexterp/ifaces/internal/services/usage"
 )
 
 //
@@ -13,29 +22,79 @@ import (
 //
 
 var (
-	Args   *cli.Args // Args command line options
-	Stderr io.Writer
-	Stdout io.Writer
-	Level  print.Level
+	Args     *cli.Args // Args command line options
+	Stderr   io.Writer
+	Stdout   io.Writer
+	Level    print.Level
+	Reporter *diag.Reporter // Reporter collects warnings for --diagnostics, warnings are printed if nil
 )
 
 func MakeIfaceGen() generate.GenerateIface {
-	return &generate.Generate{
-		Type:      Args.CmdType,
-		Method:    Args.CmdFunc,
-		Comment:   Args.Cmt,
-		Iface:     Args.Iface,
-		MatchFunc: Args.MatchFunc,
-		MatchType: Args.MatchType,
-		Module:    Args.Module,
-		NoFDoc:    Args.NoFDoc,
-		NoTDoc:    Args.NoTDoc,
-		Pkg:       Args.Pkg,
-		Post:      Args.Post,
-		Pre:       Args.Pre,
-		Print:     MakePrint(),
-		Struct:    Args.CmdStruct,
-		TDoc:      Args.TDoc,
+	return NewIfaceGen(Args)
+}
+
+// NewIfaceGen creates a generator from args
+func NewIfaceGen(args *cli.Args) *generate.Generate {
+	gen := generate.FromArgs(args)
+	gen.Print = MakePrint()
+	gen.Reporter = Reporter
+	return gen
+}
+
+func MakeAnnotations(current func(file string) (*bytes.Buffer, error)) annotations.AnnotationsIface {
+	return &annotations.Annotations{
+		Current: current,
+		NewGen:  NewIfaceGen,
+	}
+}
+
+func MakeRunner(current func(file string) (*bytes.Buffer, error)) runner.RunnerIface {
+	return &runner.Runner{
+		Current: current,
+		Jobs:    Args.Jobs,
+		NewGen:  NewIfaceGen,
+		Print:   MakePrint(),
+	}
+}
+
+func MakeNarrow() narrow.NarrowIface {
+	return &narrow.Narrow{
+		Args:     Args,
+		NewGen:   NewIfaceGen,
+		Print:    MakePrint(),
+		Reporter: Reporter,
+	}
+}
+
+func MakeDecouple() decouple.DecoupleIface {
+	return &decouple.Decouple{
+		Args:     Args,
+		Print:    MakePrint(),
+		Reporter: Reporter,
+	}
+}
+
+func MakeImplements() implements.ImplementsIface {
+	return &implements.Implements{
+		Args:     Args,
+		Print:    MakePrint(),
+		Reporter: Reporter,
+	}
+}
+
+func MakeDupes() dupes.DupesIface {
+	return &dupes.Dupes{
+		Args:     Args,
+		Print:    MakePrint(),
+		Reporter: Reporter,
+	}
+}
+
+func MakeUsage() usage.UsageIface {
+	return &usage.Usage{
+		Args:     Args,
+		Print:    MakePrint(),
+		Reporter: Reporter,
 	}
 }
 
@@ -3,15 +3,30 @@ package cli
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"strings"
 	"text/template"
 
 	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
 	"github.com/docopt/docopt-go"
 )
 
+// ErrFormatUnsupported --format is set for a sub command without output formats
+var ErrFormatUnsupported = errors.New(`--format is not supported by`)
+
+// noFormat sub commands without --format, annotations and run take the format
+// of each annotation or directive
+var noFormat = map[string]string{
+	`annotations`:  `set --format in the //ifaces:interface annotations`,
+	`decouple`:     `decouple rewrites Go source, generate the interface with ifaces type --format json instead`,
+	`dupes --into`: `dupes --into rewrites Go source, list the duplicates with ifaces dupes --format json instead`,
+	`run`:          `set --format in the ifaces go:generate directives`,
+}
+
 // usageTmpl is a text/template document. Sub commands are toggled in or out
 // depending on the arguments passed to the usage function.
 
@@ -22,26 +37,50 @@ var usageTmpl string
 // the function and type document options.
 func usage(argv []string) string {
 	var (
+		ann   = cond.StringValPos("annotations", 1, argv)
+		dec   = cond.StringValPos("decouple", 1, argv)
+		dup   = cond.StringValPos("dupes", 1, argv)
 		fun   = cond.StringValPos("func", 1, argv)
+		impl  = cond.StringValPos("implements", 1, argv)
+		nar   = cond.StringValPos("narrow", 1, argv)
+		spec  = cond.StringValPos("from-spec", 1, argv)
+		run   = cond.StringValPos("run", 1, argv)
 		struc = cond.StringValPos("struct", 1, argv)
 		typ   = cond.StringValPos("type", 1, argv)
-		root  = !fun && !struc && !typ
+		use   = cond.StringValPos("usage", 1, argv)
+		root  = !ann && !dec && !dup && !fun && !impl && !nar && !spec && !run && !struc && !typ && !use
 	)
 	t, err := template.New(`cli.gotmpl`).Parse(usageTmpl)
 	if err != nil {
 		panic(err)
 	}
 	data := struct {
-		Func      bool
-		NoOptions bool
-		Root      bool
-		Struct    bool
-		Type      bool
+		Annotations bool
+		Decouple    bool
+		Dupes       bool
+		FromSpec    bool
+		Func        bool
+		Implements  bool
+		Narrow      bool
+		NoOptions   bool
+		Root        bool
+		Run         bool
+		Struct      bool
+		Type        bool
+		Usage       bool
 	}{
-		Func:   fun,
-		Root:   root,
-		Struct: struc,
-		Type:   typ,
+		Annotations: ann,
+		Decouple:    dec,
+		Dupes:       dup,
+		FromSpec:    spec,
+		Func:        fun,
+		Implements:  impl,
+		Narrow:      nar,
+		Root:        root,
+		Run:         run,
+		Struct:      struc,
+		Type:        typ,
+		Usage:       use,
 	}
 
 	buf := &bytes.Buffer{}
@@ -59,58 +98,129 @@ func sliceStr2Any(in []string) (out []any) {
 	return
 }
 
+// ParseArgs parses the command line arguments. The usage is written to stdout
+// and nil is returned for both the arguments and the error if -h is set.
+// Invalid arguments are reported in stderr and returned as usage diagnostics.
 func ParseArgs(argv []string, version string, stdout io.Writer, stderr io.Writer) (*Args, error) {
-	var fnerr error
+	var help bool
 	fn := func(err error, usageStr string) {
 		if cond.EqualAny("-h", sliceStr2Any(argv)...) {
 			fmt.Fprintln(stdout, usage(argv))
-			os.Exit(0)
+			help = true
 		} else {
-			fnerr = err
 			fmt.Fprint(stderr, err.Error())
 			fmt.Fprintln(stderr, `invalid or incomplete options, see "ifaces -h" for cli options`)
 		}
 	}
-	if fnerr != nil {
-		return nil, fnerr
+	// docopt only reports an invalid usage for an option a sub command does not
+	// have, say why --format is left out
+	if len(argv) > 0 && hasOption(argv, `--format`) {
+		cmd := argv[0]
+		if cmd == `dupes` && hasOption(argv, `--into`) {
+			cmd = `dupes --into`
+		}
+		if fix, ok := noFormat[cmd]; ok {
+			err := diag.Errorf(diag.CodeUsage, diag.Position{}, `%w ifaces %s`, ErrFormatUnsupported, cmd).WithFix(fix)
+			_ = diag.Write(stderr, diag.FormatText, diag.List{err})
+			return nil, err
+		}
 	}
 	parser := &docopt.Parser{
 		HelpHandler: fn,
 	}
 	args, err := parser.ParseArgs(usage(argv), argv, version)
-	if err != nil {
-		return nil, err
+	if help {
+		return nil, nil
+	} else if err != nil {
+		return nil, diag.Errorf(diag.CodeUsage, diag.Position{}, `%w`, err)
 	}
 	config := &Args{}
 	err = args.Bind(config)
 	if err != nil {
-		fmt.Fprintf(stderr, `error binding command arguments: %s`, err.Error())
+		fmt.Fprintf(stderr, "error binding command arguments: %s\n", err.Error())
+		return nil, diag.Errorf(diag.CodeUsage, diag.Position{}, `error binding command arguments: %w`, err)
 	}
-	// Disabled comment option for hard coded option.
+	if config.Diagnostics != `` && !cond.EqualAnyString(config.Diagnostics, diag.Formats...) {
+		err = diag.Errorf(diag.CodeUsage, diag.Position{}, `%w "%s", expected one of %s`, diag.ErrFormat, config.Diagnostics, strings.Join(diag.Formats, `, `))
+		fmt.Fprintln(stderr, err.Error())
+		return nil, err
+	}
+	// docopt repeats the last value of a repeated option when a sub command
+	// has more than one usage pattern
+	config.Map = stringx.Unique(config.Map)
+	// The generated code comment is fixed so the go toolchain recognizes the
+	// output as generated, see --header-file and --comment for other comments.
 	config.Cmt = `Code generated by ifaces DO NOT EDIT.`
 	return config, nil
 }
 
+// hasOption returns true if the long option opt, E.G. --format, is set in argv
+func hasOption(argv []string, opt string) bool {
+	for _, a := range argv {
+		if a == `--` {
+			break
+		} else if a == opt || strings.HasPrefix(a, opt+`=`) {
+			return true
+		}
+	}
+	return false
+}
+
 type Args struct {
-	CmdStruct bool   `docopt:"struct"`
-	CmdType   bool   `docopt:"type"`
-	CmdFunc   bool   `docopt:"func"`
-	Out       string `docopt:"-o"`
+	CmdAnnotations bool   `docopt:"annotations"`
+	CmdStruct      bool   `docopt:"struct"`
+	CmdType        bool   `docopt:"type"`
+	CmdFunc        bool   `docopt:"func"`
+	CmdFromSpec    bool   `docopt:"from-spec"`
+	CmdNarrow      bool   `docopt:"narrow"`
+	CmdDecouple    bool   `docopt:"decouple"`
+	CmdImplements  bool   `docopt:"implements"`
+	CmdUsage       bool   `docopt:"usage"`
+	CmdDupes       bool   `docopt:"dupes"`
+	CmdRun         bool   `docopt:"run"`
+	Out            string `docopt:"-o"`
+	OutTmpl        string `docopt:"--out-template"`
 
-	Append    bool   `docopt:"-a"`
-	Cmt       string `docopt:"-c"`
-	Iface     string `docopt:"-i"`
-	FDoc      string `docopt:"--fdoc"`
-	MatchFunc string `docopt:"-m"`
-	MatchType string `docopt:"-t"`
-	Module    string `docopt:"-x"`
-	NoFDoc    bool   `docopt:"--nfdoc"`
-	NoTDoc    bool   `docopt:"--ntdoc"`
-	Pkg       string `docopt:"-p"`
-	Post      string `docopt:"-s"`
-	Pre       string `docopt:"-e"`
-	Print     bool   `docopt:"-d"`
-	Src       string `docopt:"-f"`
-	TDoc      string `docopt:"--tdoc"`
-	NoMethods bool   `docopt:"--nmethod"`
+	Append      bool     `docopt:"-a"`
+	Build       string   `docopt:"--build"`
+	Cmt         string   `docopt:"-c"`
+	Diagnostics string   `docopt:"--diagnostics"`
+	DocWidth    int      `docopt:"--doc-width"`
+	Common      bool     `docopt:"--common"`
+	Config      string   `docopt:"--config"`
+	Exclude     string   `docopt:"--exclude"`
+	Explain     bool     `docopt:"--explain"`
+	ExtraCmt    string   `docopt:"--comment"`
+	Iface       string   `docopt:"-i"`
+	Jobs        int      `docopt:"-j"`
+	Include     string   `docopt:"--include"`
+	Into        string   `docopt:"--into"`
+	FDoc        string   `docopt:"--fdoc"`
+	Format      string   `docopt:"--format"`
+	FromFiles   string   `docopt:"--from-files"`
+	Func        string   `docopt:"--func"`
+	Generalize  bool     `docopt:"--generalize"`
+	HeaderFile  string   `docopt:"--header-file"`
+	MatchFunc   string   `docopt:"-m"`
+	MatchType   string   `docopt:"-t"`
+	Map         []string `docopt:"--map"`
+	Module      string   `docopt:"-x"`
+	NoFDoc      bool     `docopt:"--nfdoc"`
+	NoTDoc      bool     `docopt:"--ntdoc"`
+	Param       string   `docopt:"--param"`
+	Pkg         string   `docopt:"-p"`
+	Plugin      string   `docopt:"--plugin"`
+	PluginParam string   `docopt:"--plugin-param"`
+	Post        string   `docopt:"-s"`
+	Pre         string   `docopt:"-e"`
+	Print       bool     `docopt:"-d"`
+	RoleSplit   bool     `docopt:"--roles"`
+	RoleRules   string   `docopt:"--role-rules"`
+	Spec        string   `docopt:"<spec>"`
+	Src         string   `docopt:"-f"`
+	TDoc        string   `docopt:"--tdoc"`
+	Template    string   `docopt:"--template"`
+	Transitive  bool     `docopt:"--transitive"`
+	NoMethods   bool     `docopt:"--nmethod"`
+	Pkgs        []string `docopt:"<pkg>"`
 }
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"testing"
 
+	"github.com/dexterp/ifaces/internal/resources/diag"
 	"github.com/dexterp/ifaces/internal/resources/testtools/testpaths"
 
 	"github.com/stretchr/testify/assert"
@@ -103,3 +105,289 @@ func TestParseArgs_Func_Optional(t *testing.T) {
 	assert.Zero(t, stdout.String())
 	assert.Zero(t, stderr.String())
 }
+
+func TestParseArgs_Struct_OutTemplate(t *testing.T) {
+	cmd := []string{"ifaces", "struct", "--out-template", "{{ .Type | snake }}_iface.go"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdStruct)
+	assert.Equal(t, "{{ .Type | snake }}_iface.go", args.OutTmpl)
+	assert.Zero(t, args.Out)
+}
+
+func TestParseArgs_Annotations(t *testing.T) {
+	cmd := []string{"ifaces", "annotations", "-d", "./...", "other"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdAnnotations)
+	assert.True(t, args.Print)
+	assert.Equal(t, []string{"./...", "other"}, args.Pkgs)
+}
+
+func TestParseArgs_Run(t *testing.T) {
+	cmd := []string{"ifaces", "run", "-j", "4", "./..."}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdRun)
+	assert.Equal(t, 4, args.Jobs)
+	assert.Equal(t, []string{"./..."}, args.Pkgs)
+}
+
+func TestParseArgs_Type_Roles(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Store", "--roles", "--role-rules", "Reader=Get*;Writer=Set*"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.RoleSplit)
+	assert.Equal(t, "Reader=Get*;Writer=Set*", args.RoleRules)
+}
+
+func TestParseArgs_Type_Common(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Store", "--common", "-f", "store.go", "-t", "Postgres*Store"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.Common)
+	assert.Equal(t, "Postgres*Store", args.MatchType)
+}
+
+func TestParseArgs_Type_Generalize(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Repo", "--generalize", "-f", "repo.go", "-t", "*Repo"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.Generalize)
+	assert.False(t, args.Common)
+}
+
+func TestParseArgs_Type_Docs(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Iface", "--tdoc", "{{.Name}} wraps {{.Source}}", "--fdoc", "{{.OrigDoc}}", "--doc-width", "-1"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "{{.Name}} wraps {{.Source}}", args.TDoc)
+	assert.Equal(t, "{{.OrigDoc}}", args.FDoc)
+	assert.Equal(t, -1, args.DocWidth)
+
+	args, err = ParseArgs([]string{"type", "-i", "Iface"}, ``, stdout, stderr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, args.DocWidth)
+	}
+}
+
+func TestParseArgs_Type_Header(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Iface", "--header-file", "LICENSE.hdr", "--comment", "See store.go", "--build", "linux"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "LICENSE.hdr", args.HeaderFile)
+	assert.Equal(t, "See store.go", args.ExtraCmt)
+	assert.Equal(t, "linux", args.Build)
+	assert.Equal(t, "Code generated by ifaces DO NOT EDIT.", args.Cmt)
+}
+
+func TestParseArgs_Format(t *testing.T) {
+	for _, cmd := range [][]string{
+		{"type", "-i", "Iface", "--format", "json", "-f", "src.go", "-t", "Store"},
+		{"struct", "--format", "json", "-f", "src.go"},
+		{"func", "-i", "Iface", "--format", "json", "-f", "src.go", "-t", "Store", "-m", "Get"},
+		{"implements", "-i", "Iface", "--format", "json"},
+		{"dupes", "--format", "json"},
+	} {
+		args, err := ParseArgs(cmd, ``, stdout, stderr)
+		if assert.NoError(t, err, cmd[0]) {
+			assert.Equal(t, "json", args.Format, cmd[0])
+		}
+	}
+	for _, cmd := range [][]string{
+		{"annotations", "--format", "json"},
+		{"run", "--format=json", "./..."},
+		{"decouple", "-t", "*Store", "-i", "StoreIface", "--format", "json"},
+		{"dupes", "--into", "log.Logger", "--format=json"},
+	} {
+		_, err := ParseArgs(cmd, ``, stdout, stderr)
+		if assert.ErrorIs(t, err, ErrFormatUnsupported, cmd[0]) {
+			assert.Equal(t, diag.CodeUsage, diag.FromError(err)[0].Code, cmd[0])
+		}
+	}
+}
+
+func TestParseArgs_FromSpec(t *testing.T) {
+	cmd := []string{"ifaces", "from-spec", "-o", "ports.go", "-p", "ports", "api.yaml"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdFromSpec)
+	assert.Equal(t, "api.yaml", args.Spec)
+	assert.Equal(t, "ports.go", args.Out)
+	assert.Equal(t, "ports", args.Pkg)
+}
+
+func TestParseArgs_Type_Filters(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Iface", "--include", "Get*,List*", "--exclude", "String", "--from-files", "store*.go"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "Get*,List*", args.Include)
+	assert.Equal(t, "String", args.Exclude)
+	assert.Equal(t, "store*.go", args.FromFiles)
+}
+
+func TestParseArgs_Type_Subset(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-x", "io", "-t", "ReadWriteCloser", "-m", "Read,Close", "-i", "ReadCloser"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "io", args.Module)
+	assert.Equal(t, "ReadWriteCloser", args.MatchType)
+	assert.Equal(t, "Read,Close", args.MatchFunc)
+}
+
+func TestParseArgs_Type_Transitive(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "DBIface", "-f", "db.go", "-t", "DB", "--transitive"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.Transitive)
+}
+
+func TestParseArgs_Type_Map(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Iface", "-f", "src.go", "-t", "Store", "--config", "ifaces.yaml", "--map", "*os.File=io.ReadCloser", "--map", "time.Time=int64"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "ifaces.yaml", args.Config)
+	assert.Equal(t, []string{"*os.File=io.ReadCloser", "time.Time=int64"}, args.Map)
+}
+
+func TestParseArgs_Narrow(t *testing.T) {
+	cmd := []string{"ifaces", "narrow", "--func", "Server.Handle", "--param", "st", "-o", "store_iface.go", "./handler"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdNarrow)
+	assert.Equal(t, "Server.Handle", args.Func)
+	assert.Equal(t, "st", args.Param)
+	assert.Equal(t, "store_iface.go", args.Out)
+	assert.Equal(t, []string{"./handler"}, args.Pkgs)
+}
+
+func TestParseArgs_Decouple(t *testing.T) {
+	cmd := []string{"ifaces", "decouple", "-t", "*Store", "-i", "StoreIface", "./..."}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdDecouple)
+	assert.Equal(t, "*Store", args.MatchType)
+	assert.Equal(t, "StoreIface", args.Iface)
+	assert.Equal(t, []string{"./..."}, args.Pkgs)
+}
+
+func TestParseArgs_Implements(t *testing.T) {
+	cmd := []string{"ifaces", "implements", "-i", "PrintIface", "./..."}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdImplements)
+	assert.Equal(t, "PrintIface", args.Iface)
+	assert.Equal(t, []string{"./..."}, args.Pkgs)
+
+	cmd = []string{"ifaces", "implements", "-t", "*Print"}
+	args, err = ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "*Print", args.MatchType)
+}
+
+func TestParseArgs_Usage(t *testing.T) {
+	cmd := []string{"ifaces", "usage", "--format", "markdown", "./..."}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdUsage)
+	assert.Equal(t, "markdown", args.Format)
+	assert.Equal(t, []string{"./..."}, args.Pkgs)
+}
+
+func TestParseArgs_Dupes(t *testing.T) {
+	cmd := []string{"ifaces", "dupes", "--into", "log.Logger", "./..."}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, args.CmdDupes)
+	assert.Equal(t, "log.Logger", args.Into)
+	assert.Equal(t, []string{"./..."}, args.Pkgs)
+}
+
+func TestParseArgs_Plugin(t *testing.T) {
+	cmd := []string{"ifaces", "type", "-i", "Iface", "-f", "src.go", "-t", "Store", "--plugin", "ifaces-gen-mocks", "--plugin-param", "mocks.go"}
+	args, err := ParseArgs(cmd[1:], ``, stdout, stderr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "ifaces-gen-mocks", args.Plugin)
+	assert.Equal(t, "mocks.go", args.PluginParam)
+}
+
+func TestParseArgs_Explain(t *testing.T) {
+	for _, cmd := range [][]string{
+		{"type", "-i", "Iface", "--explain"},
+		{"struct", "--explain", "-f", "src.go"},
+		{"func", "--explain", "-i", "Iface"},
+		{"run", "--explain", "./..."},
+		{"annotations", "--explain"},
+	} {
+		args, err := ParseArgs(cmd, ``, stdout, stderr)
+		if assert.NoError(t, err, cmd) {
+			assert.True(t, args.Explain, cmd)
+		}
+	}
+}
+
+func TestParseArgs_Diagnostics(t *testing.T) {
+	args, err := ParseArgs([]string{"type", "-i", "Iface", "--diagnostics", "sarif"}, ``, stdout, stderr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sarif", args.Diagnostics)
+	}
+	args, err = ParseArgs([]string{"run", "--diagnostics=json"}, ``, stdout, stderr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "json", args.Diagnostics)
+	}
+	_, err = ParseArgs([]string{"type", "-i", "Iface", "--diagnostics", "xml"}, ``, stdout, stderr)
+	assert.True(t, errors.Is(err, diag.ErrFormat))
+}
+
+func TestParseArgs_Help(t *testing.T) {
+	out := &bytes.Buffer{}
+	args, err := ParseArgs([]string{"type", "-h"}, ``, out, stderr)
+	assert.NoError(t, err)
+	assert.Nil(t, args)
+	assert.Contains(t, out.String(), "ifaces type")
+
+	_, err = ParseArgs([]string{"type", "--unknown"}, ``, stdout, stderr)
+	if assert.Error(t, err) {
+		assert.Equal(t, diag.CodeUsage, diag.FromError(err)[0].Code)
+	}
+}
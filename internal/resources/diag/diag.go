@@ -0,0 +1,211 @@
+// Package diag diagnostics with a severity, code, position, message and
+// suggested fix. Diagnostics are errors and are written in the go vet style or
+// as JSON or SARIF, see Write.
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Severity severity of a diagnostic
+type Severity string
+
+const (
+	Error   Severity = `error`
+	Warning Severity = `warning`
+	Note    Severity = `note`
+)
+
+// Diagnostic codes
+const (
+	CodeError           = `error`            // CodeError error without a specific code
+	CodeParse           = `parse`            // CodeParse Go source can not be parsed
+	CodeTypeNotFound    = `type-not-found`   // CodeTypeNotFound no type matches the options or directive
+	CodeMethodNotFound  = `method-not-found` // CodeMethodNotFound no method matches the options or directive
+	CodeDuplicateMethod = `duplicate-method` // CodeDuplicateMethod a method is added twice with different signatures
+	CodeMethodExcluded  = `method-excluded`  // CodeMethodExcluded a method is left out of a common interface
+	CodeEmbedNotFound   = `embed-not-found`  // CodeEmbedNotFound an embedded interface is not declared in the source files
+	CodeUsage           = `usage`            // CodeUsage invalid command line options
+	CodeFuncNotFound    = `func-not-found`   // CodeFuncNotFound no function or parameter matches the options
+	CodeNotMethod       = `not-a-method`     // CodeNotMethod a name selected on a value is not an exported method of its type
+	CodeUntrackedUse    = `untracked-use`    // CodeUntrackedUse a value is used in a way which is not analysed
+	CodeUntouchable     = `untouchable`      // CodeUntouchable a use of a value is not covered by an interface so its type is kept
+	CodeGenerated       = `generated`        // CodeGenerated a removed declaration is in a generated file
+)
+
+// Position position in a source file. Line and Col are 0 if unknown.
+type Position struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	Col  int    `json:"col,omitempty"`
+}
+
+// String returns the position as file:line:col, leaving out unknown parts
+func (p Position) String() string {
+	parts := []string{}
+	if p.File != `` {
+		parts = append(parts, p.File)
+	}
+	if p.Line > 0 {
+		parts = append(parts, strconv.Itoa(p.Line))
+		if p.Col > 0 {
+			parts = append(parts, strconv.Itoa(p.Col))
+		}
+	}
+	return strings.Join(parts, `:`)
+}
+
+// Diagnostic a problem found by ifaces
+type Diagnostic struct {
+	Severity Severity `json:"severity"`      // Severity severity
+	Code     string   `json:"code"`          // Code diagnostic code, see the Code constants
+	Pos      Position `json:"position"`      // Pos position of the problem
+	Message  string   `json:"message"`       // Message description of the problem
+	Fix      string   `json:"fix,omitempty"` // Fix suggested fix, empty if there is none
+	err      error
+}
+
+// New creates a diagnostic
+func New(sev Severity, code string, pos Position, msg string) *Diagnostic {
+	return &Diagnostic{
+		Severity: sev,
+		Code:     code,
+		Pos:      pos,
+		Message:  msg,
+	}
+}
+
+// Errorf creates an error diagnostic. The message is formatted with
+// fmt.Errorf so an error wrapped with %w is returned by Unwrap.
+func Errorf(code string, pos Position, format string, a ...any) *Diagnostic {
+	err := fmt.Errorf(format, a...)
+	d := New(Error, code, pos, err.Error())
+	d.err = errors.Unwrap(err)
+	return d
+}
+
+// WithFix sets the suggested fix
+func (d *Diagnostic) WithFix(fix string) *Diagnostic {
+	d.Fix = fix
+	return d
+}
+
+// Error returns the diagnostic in the go vet style, "file:line:col: message".
+func (d *Diagnostic) Error() string {
+	if pos := d.Pos.String(); pos != `` {
+		return pos + `: ` + d.Message
+	}
+	return d.Message
+}
+
+// Unwrap returns the wrapped error
+func (d *Diagnostic) Unwrap() error {
+	return d.err
+}
+
+// At returns err as a diagnostic at pos. Diagnostics with a position are
+// returned unchanged. A diagnostic without a position is copied with pos and
+// the message of err, which keeps the context added by wrapping it. Other
+// errors are returned as a diagnostic with CodeError.
+func At(pos Position, err error) error {
+	var (
+		l List
+		d *Diagnostic
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &l):
+		return err
+	case errors.As(err, &d) && d.Pos != Position{}:
+		return err
+	case d != nil:
+		c := *d
+		c.Pos = pos
+		c.Message = err.Error()
+		c.err = err
+		return &c
+	}
+	return Errorf(CodeError, pos, `%w`, err)
+}
+
+// List list of diagnostics
+type List []*Diagnostic
+
+// Error returns the first diagnostic and the number of other diagnostics
+func (l List) Error() string {
+	switch len(l) {
+	case 0:
+		return `no errors`
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf(`%s (and %d more errors)`, l[0].Error(), len(l)-1)
+}
+
+// Unwrap returns the first diagnostic
+func (l List) Unwrap() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l[0]
+}
+
+// FromError returns the diagnostics of err. Errors which are not diagnostics
+// are returned as a diagnostic without a position.
+func FromError(err error) List {
+	if err == nil {
+		return nil
+	}
+	var (
+		l  List
+		d  *Diagnostic
+		el scanner.ErrorList
+	)
+	switch {
+	case errors.As(err, &l):
+		return l
+	case errors.As(err, &d):
+		return List{d}
+	case errors.As(err, &el):
+		return FromScanner(el)
+	}
+	return List{New(Error, CodeError, Position{}, err.Error())}
+}
+
+// FromScanner returns parse diagnostics for the errors of the Go scanner
+func FromScanner(el scanner.ErrorList) (l List) {
+	for _, e := range el {
+		l = append(l, New(Error, CodeParse, Position{
+			File: e.Pos.Filename,
+			Line: e.Pos.Line,
+			Col:  e.Pos.Column,
+		}, e.Msg))
+	}
+	return
+}
+
+// Reporter collects diagnostics. It is safe for concurrent use.
+type Reporter struct {
+	mu   sync.Mutex
+	list List
+}
+
+// Report adds a diagnostic
+func (r *Reporter) Report(d *Diagnostic) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.list = append(r.list, d)
+}
+
+// List returns the reported diagnostics
+func (r *Reporter) List() List {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(List{}, r.list...)
+}
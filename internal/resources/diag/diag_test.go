@@ -0,0 +1,150 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New(`test error`)
+
+func TestPosition_String(t *testing.T) {
+	assert.Equal(t, `store.go:5:2`, Position{File: `store.go`, Line: 5, Col: 2}.String())
+	assert.Equal(t, `store.go:5`, Position{File: `store.go`, Line: 5}.String())
+	assert.Equal(t, `store.go`, Position{File: `store.go`}.String())
+	assert.Equal(t, ``, Position{}.String())
+}
+
+func TestErrorf(t *testing.T) {
+	d := Errorf(CodeTypeNotFound, Position{File: `store.go`, Line: 5}, `%w "%s"`, errTest, `Store`).WithFix(`add -t`)
+	assert.Equal(t, `store.go:5: test error "Store"`, d.Error())
+	assert.Equal(t, Error, d.Severity)
+	assert.Equal(t, `add -t`, d.Fix)
+	assert.True(t, errors.Is(d, errTest))
+	assert.True(t, errors.Is(fmt.Errorf(`run: %w`, d), errTest))
+}
+
+func TestFromError(t *testing.T) {
+	assert.Nil(t, FromError(nil))
+
+	d := Errorf(CodeTypeNotFound, Position{File: `store.go`, Line: 5}, `%w`, errTest)
+	assert.Equal(t, List{d}, FromError(fmt.Errorf(`store.go:5: %w`, d)))
+
+	l := FromError(errTest)
+	if assert.Len(t, l, 1) {
+		assert.Equal(t, CodeError, l[0].Code)
+		assert.Equal(t, `test error`, l[0].Error())
+	}
+
+	_, err := parser.ParseFile(token.NewFileSet(), `store.go`, "package store\n\nfunc (\n", 0)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	l = FromError(err.(scanner.ErrorList))
+	if assert.NotEmpty(t, l) {
+		assert.Equal(t, CodeParse, l[0].Code)
+		assert.Equal(t, `store.go`, l[0].Pos.File)
+		assert.Equal(t, 3, l[0].Pos.Line)
+		assert.NotZero(t, l[0].Pos.Col)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	l := List{
+		Errorf(CodeTypeNotFound, Position{File: `store.go`, Line: 5}, `%w`, errTest).WithFix(`add -t`),
+		New(Warning, CodeDuplicateMethod, Position{File: `iface.go`, Line: 9, Col: 2}, `duplicate`),
+	}
+	buf := &bytes.Buffer{}
+	err := Write(buf, FormatText, l)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "store.go:5: test error\n\tfix: add -t\niface.go:9:2: duplicate\n", buf.String())
+	}
+
+	buf.Reset()
+	err = Write(buf, FormatJSON, l)
+	if assert.NoError(t, err) {
+		out := []map[string]any{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		if assert.Len(t, out, 2) {
+			assert.Equal(t, `error`, out[0][`severity`])
+			assert.Equal(t, `type-not-found`, out[0][`code`])
+			assert.Equal(t, map[string]any{`file`: `store.go`, `line`: float64(5)}, out[0][`position`])
+			assert.Equal(t, `add -t`, out[0][`fix`])
+		}
+	}
+
+	buf.Reset()
+	err = Write(buf, FormatJSON, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "[]\n", buf.String())
+	}
+
+	err = Write(buf, `xml`, l)
+	assert.True(t, errors.Is(err, ErrFormat))
+}
+
+func TestWrite_SARIF(t *testing.T) {
+	l := List{
+		Errorf(CodeTypeNotFound, Position{File: `store.go`, Line: 5}, `%w`, errTest).WithFix(`add -t`),
+		New(Warning, CodeMethodExcluded, Position{}, `excluded`),
+	}
+	buf := &bytes.Buffer{}
+	err := Write(buf, FormatSARIF, l)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	log := &sarifLog{}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), log)) {
+		t.FailNow()
+	}
+	assert.Equal(t, `2.1.0`, log.Version)
+	if !assert.Len(t, log.Runs, 1) {
+		t.FailNow()
+	}
+	run := log.Runs[0]
+	assert.Equal(t, `ifaces`, run.Tool.Driver.Name)
+	assert.Equal(t, []sarifRule{{ID: `method-excluded`}, {ID: `type-not-found`}}, run.Tool.Driver.Rules)
+	if assert.Len(t, run.Results, 2) {
+		assert.Equal(t, `type-not-found`, run.Results[0].RuleID)
+		assert.Equal(t, `error`, run.Results[0].Level)
+		assert.Equal(t, `test error`, run.Results[0].Message.Text)
+		assert.Equal(t, `store.go`, run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, 5, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+		assert.Equal(t, `add -t`, run.Results[0].Properties[`fix`])
+		assert.Equal(t, `warning`, run.Results[1].Level)
+		assert.Empty(t, run.Results[1].Locations)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	r := &Reporter{}
+	assert.Empty(t, r.List())
+	d := New(Warning, CodeDuplicateMethod, Position{}, `duplicate`)
+	r.Report(d)
+	assert.Equal(t, List{d}, r.List())
+}
+
+func TestAt(t *testing.T) {
+	pos := Position{File: `store.go`, Line: 3}
+	assert.Nil(t, At(pos, nil))
+
+	err := At(pos, errTest)
+	assert.Equal(t, `store.go:3: test error`, err.Error())
+	assert.True(t, errors.Is(err, errTest))
+
+	d := Errorf(CodeUsage, Position{}, `%w`, errTest)
+	err = At(pos, fmt.Errorf(`invalid options: %w`, d))
+	assert.Equal(t, `store.go:3: invalid options: test error`, err.Error())
+	assert.Equal(t, CodeUsage, FromError(err)[0].Code)
+	assert.True(t, errors.Is(err, errTest))
+
+	d = Errorf(CodeTypeNotFound, Position{File: `other.go`, Line: 9}, `%w`, errTest)
+	assert.Equal(t, d, At(pos, d))
+}
@@ -0,0 +1,29 @@
+package diag
+
+import (
+	"bytes"
+
+	"github.com/dexterp/ifaces/internal/resources/print"
+)
+
+// Explainf prints a message explaining a decision at the DEBUG level of p, see
+// --explain. Nothing is printed if p is nil.
+func Explainf(p print.PrintIface, format string, a ...any) {
+	if p != nil {
+		p.Debugf(`explain: `+format+"\n", a...)
+	}
+}
+
+// Warn reports d to r. Without a Reporter d is printed as text at the WARN
+// level of p, nothing is printed if p is nil too.
+func Warn(r *Reporter, p print.PrintIface, d *Diagnostic) {
+	if r != nil {
+		r.Report(d)
+		return
+	} else if p == nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	_ = Write(buf, FormatText, List{d})
+	p.Warnf(`%s`, buf.String())
+}
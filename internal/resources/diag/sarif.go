@@ -0,0 +1,114 @@
+package diag
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+const (
+	sarifSchema  = `https://json.schemastore.org/sarif-2.1.0.json`
+	sarifVersion = `2.1.0`
+	sarifName    = `ifaces`
+	sarifInfoURI = `https://github.com/dexterp/ifaces`
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysical `json:"physicalLocation"`
+}
+
+type sarifPhysical struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// newSarif creates a SARIF log with a single run. Codes are the rules and a
+// suggested fix is the "fix" property of a result.
+func newSarif(l List) *sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           sarifName,
+			InformationURI: sarifInfoURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, d := range l {
+		if !rules[d.Code] {
+			rules[d.Code] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Code})
+		}
+		res := sarifResult{
+			RuleID:  d.Code,
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.Pos.File != `` {
+			loc := sarifLocation{PhysicalLocation: sarifPhysical{
+				ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(d.Pos.File)},
+			}}
+			if d.Pos.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Pos.Line, StartColumn: d.Pos.Col}
+			}
+			res.Locations = append(res.Locations, loc)
+		}
+		if d.Fix != `` {
+			res.Properties = map[string]string{`fix`: d.Fix}
+		}
+		run.Results = append(run.Results, res)
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+}
@@ -0,0 +1,59 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Output formats
+const (
+	FormatText  = `text`
+	FormatJSON  = `json`
+	FormatSARIF = `sarif`
+)
+
+var ErrFormat = errors.New(`unknown diagnostics format`)
+
+// Formats output formats accepted by Write
+var Formats = []string{FormatText, FormatJSON, FormatSARIF}
+
+// Write writes the diagnostics in format. FormatText writes one diagnostic per
+// line in the go vet style followed by an indented suggested fix, FormatJSON
+// writes a JSON array and FormatSARIF a SARIF 2.1.0 log. An empty format is
+// FormatText.
+func Write(w io.Writer, format string, l List) error {
+	switch format {
+	case ``, FormatText:
+		return writeText(w, l)
+	case FormatJSON:
+		if l == nil {
+			l = List{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(l)
+	case FormatSARIF:
+		enc := json.NewEncoder(w)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(newSarif(l))
+	}
+	return fmt.Errorf(`%w "%s"`, ErrFormat, format)
+}
+
+func writeText(w io.Writer, l List) error {
+	for _, d := range l {
+		_, err := fmt.Fprintln(w, d.Error())
+		if err != nil {
+			return err
+		}
+		if d.Fix != `` {
+			_, err = fmt.Fprintf(w, "\tfix: %s\n", d.Fix)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
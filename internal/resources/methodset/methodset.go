@@ -0,0 +1,478 @@
+// Package methodset compares the method sets of the types declared in
+// packages with the methods of the interfaces. Signatures are normalised,
+// types are qualified with the import path of their package, the empty
+// interface is written as any and parameter names are dropped.
+package methodset
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	gotypes "go/types"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/modinfo"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/types"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Package parsed package
+type Package struct {
+	Dir   string        // Dir package directory
+	Name  string        // Name package name
+	Path  string        // Path import path of the package, the package name if it can not be found
+	Query *parser.Query // Query declarations of the package
+}
+
+// Method normalised method
+type Method struct {
+	Name    string        // Name method name
+	Sig     string        // Sig normalised signature, E.G. "Get(context.Context, string) (example.com/app/store.User, error)"
+	Display string        // Display signature with the parameter names
+	Pointer bool          // Pointer true for a pointer receiver
+	Pos     diag.Position // Pos position of the method
+}
+
+// Iface interface declaration with the methods of the embedded interfaces
+type Iface struct {
+	Pkg     *Package
+	Type    parser.Type
+	Methods []*Method
+}
+
+// Concrete type declaration which is not an interface with its methods keyed
+// by name
+type Concrete struct {
+	Pkg     *Package
+	Type    parser.Type
+	Methods map[string]*Method
+}
+
+// Result comparison of a method set with an interface
+type Result struct {
+	Missing    []*Method    // Missing interface methods which are not declared
+	Mismatched [][2]*Method // Mismatched declared method and interface method with different signatures
+	Pointer    []*Method    // Pointer methods with a pointer receiver which a value does not have
+}
+
+// Load parses the packages in dirs
+func Load(dirs []string) (pkgs []*Package, err error) {
+	for _, dir := range dirs {
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		p, err := parser.ParseFiles(srcs)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, newPackage(dir, p))
+	}
+	return pkgs, nil
+}
+
+// LoadTests parses the test files of the packages in dirs. Test files of the
+// package are parsed with the package files, test files of an external test
+// package, E.G. "store_test", are parsed on their own.
+func LoadTests(dirs []string) (pkgs []*Package, err error) {
+	for _, dir := range dirs {
+		tests, err := srcio.ReadTestDir(dir)
+		if err != nil {
+			return nil, err
+		} else if len(tests) == 0 {
+			continue
+		}
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		if len(srcs) > 0 {
+			name, err = packageName(srcs[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		var internal, external []srcio.Source
+		for _, t := range tests {
+			n, err := packageName(t)
+			if err != nil {
+				return nil, err
+			}
+			if n == name || name == `` {
+				internal = append(internal, t)
+			} else {
+				external = append(external, t)
+			}
+		}
+		if len(internal) > 0 {
+			internal = append(srcs, internal...)
+		}
+		for _, group := range [][]srcio.Source{internal, external} {
+			if len(group) == 0 {
+				continue
+			}
+			p, err := parser.ParseFiles(group)
+			if err != nil {
+				return nil, err
+			}
+			pkgs = append(pkgs, newPackage(dir, p))
+		}
+	}
+	return pkgs, nil
+}
+
+// newPackage returns the package parsed from dir. The import path of an
+// external test package ends with "_test".
+func newPackage(dir string, p *parser.Parser) *Package {
+	pkg := &Package{Dir: dir, Name: p.Package, Path: p.Package, Query: parser.NewQuery(p)}
+	if imp, err := paths.PathToImport(dir); err == nil {
+		pkg.Path = imp
+		if strings.HasSuffix(p.Package, `_test`) {
+			pkg.Path += `_test`
+		}
+	}
+	return pkg
+}
+
+// packageName returns the package name of a source file
+func packageName(src srcio.Source) (string, error) {
+	f, err := goparser.ParseFile(token.NewFileSet(), src.File, src.Src, goparser.PackageClauseOnly)
+	if err != nil {
+		return ``, diag.Errorf(diag.CodeParse, diag.Position{File: src.File}, `%w`, err)
+	}
+	return f.Name.Name, nil
+}
+
+// String returns the qualified name of the interface, E.G. "print.PrintIface"
+func (i Iface) String() string {
+	return i.Pkg.Name + `.` + i.Type.Name
+}
+
+// Pos returns the position of the interface declaration
+func (i Iface) Pos() diag.Position {
+	return Position(i.Pkg, i.Type.File, i.Type.Line)
+}
+
+// String returns the qualified name of the type, E.G. "print.Print"
+func (c Concrete) String() string {
+	return c.Pkg.Name + `.` + c.Type.Name
+}
+
+// Pos returns the position of the type declaration
+func (c Concrete) Pos() diag.Position {
+	return Position(c.Pkg, c.Type.File, c.Type.Line)
+}
+
+// Ok returns true if the method set implements the interface
+func (r Result) Ok() bool {
+	return len(r.Missing) == 0 && len(r.Mismatched) == 0 && len(r.Pointer) == 0
+}
+
+// Compare compares the method set of c with the methods of i. ptr selects
+// the method set of the pointer type.
+func Compare(c *Concrete, i *Iface, ptr bool) (r Result) {
+	for _, im := range i.Methods {
+		m, ok := c.Methods[im.Name]
+		switch {
+		case !ok:
+			r.Missing = append(r.Missing, im)
+		case m.Sig != im.Sig:
+			r.Mismatched = append(r.Mismatched, [2]*Method{m, im})
+		case m.Pointer && !ptr:
+			r.Pointer = append(r.Pointer, m)
+		}
+	}
+	return
+}
+
+// Declarations returns the interfaces with methods and the types with methods
+// declared in the packages, including unexported methods. The methods of a
+// struct include the methods promoted from its embedded fields. Interfaces and
+// types embedded from packages which are not in pkgs, E.G. io.Reader, are
+// loaded from the imports of the declaring file. Interfaces embedding an
+// interface which can not be found are left out and reported.
+func Declarations(pkgs []*Package, report func(d *diag.Diagnostic)) (ifaces []*Iface, concretes []*Concrete) {
+	s := &scope{pkgs: pkgs, imported: map[string]*Package{}}
+	for _, p := range pkgs {
+		for _, typ := range p.Query.Parser.Types {
+			if typ.Type == types.INTERFACE {
+				methods, ok := s.ifaceMethods(p, typ, map[string]bool{}, report)
+				if ok && len(methods) > 0 {
+					ifaces = append(ifaces, &Iface{Pkg: p, Type: typ, Methods: methods})
+				}
+				continue
+			}
+			c := &Concrete{Pkg: p, Type: typ, Methods: map[string]*Method{}}
+			for name, dm := range s.typeMethods(p, typ, map[string]bool{}) {
+				if dm.m != nil {
+					c.Methods[name] = dm.m
+				}
+			}
+			if len(c.Methods) > 0 {
+				concretes = append(concretes, c)
+			}
+		}
+	}
+	return
+}
+
+// depthMethod method found at a depth of embedded fields, 0 for the methods
+// declared with the type. The method is nil if two embedded fields promote a
+// method of the name at the depth.
+type depthMethod struct {
+	m     *Method
+	depth int
+}
+
+// typeMethods returns the methods of a type with the methods promoted from its
+// embedded fields. A promoted method is shadowed by a method of the same name
+// at a lower depth and ambiguous if two fields promote it at the same depth.
+// The methods of an embedded T with a pointer receiver need a pointer to the
+// type, the methods of an embedded *T and of an embedded interface do not.
+func (s *scope) typeMethods(p *Package, typ parser.Type, seen map[string]bool) map[string]depthMethod {
+	key := p.Dir + `.` + typ.Name
+	methods := map[string]depthMethod{}
+	if seen[key] {
+		return methods
+	}
+	seen[key] = true
+	defer delete(seen, key)
+	for _, m := range p.Query.Parser.ReceiverMethods {
+		if m.TypeName == typ.Name {
+			methods[m.Name] = depthMethod{m: newMethod(p, m)}
+		}
+	}
+	for _, name := range typ.Embeds {
+		ptr := strings.HasPrefix(name, `*`)
+		name, _, _ = strings.Cut(strings.TrimPrefix(name, `*`), `[`)
+		ep, embedded := s.lookup(p, typ.File, name)
+		if embedded == nil {
+			continue
+		}
+		promoted := map[string]depthMethod{}
+		if embedded.Type == types.INTERFACE {
+			ims, _ := s.ifaceMethods(ep, *embedded, map[string]bool{}, func(*diag.Diagnostic) {})
+			for _, m := range ims {
+				promoted[m.Name] = depthMethod{m: m}
+			}
+		} else {
+			promoted = s.typeMethods(ep, *embedded, seen)
+		}
+		for mname, dm := range promoted {
+			if dm.m != nil {
+				m := *dm.m
+				m.Pointer = m.Pointer && !ptr
+				dm.m = &m
+			}
+			dm.depth++
+			cur, ok := methods[mname]
+			switch {
+			case !ok || cur.depth > dm.depth:
+				methods[mname] = dm
+			case cur.depth == dm.depth:
+				methods[mname] = depthMethod{depth: dm.depth}
+			}
+		}
+	}
+	return methods
+}
+
+// ifaceMethods returns the methods of an interface and of the interfaces it
+// embeds. Returns false if an embedded interface can not be found.
+func (s *scope) ifaceMethods(p *Package, typ parser.Type, seen map[string]bool, report func(d *diag.Diagnostic)) (methods []*Method, ok bool) {
+	key := p.Dir + `.` + typ.Name
+	if seen[key] {
+		return nil, true
+	}
+	seen[key] = true
+	for _, name := range typ.Embeds {
+		if name == `error` {
+			methods = append(methods, &Method{Name: `Error`, Sig: `Error() string`, Display: `Error() string`})
+			continue
+		}
+		ep, embedded := s.lookup(p, typ.File, name)
+		if embedded == nil || embedded.Type != types.INTERFACE {
+			report(diag.New(diag.Warning, diag.CodeEmbedNotFound, Position(p, typ.File, typ.Line),
+				fmt.Sprintf(`embedded interface %s of %s is not declared in the packages or their imports, %s is left out`, name, typ.Name, typ.Name),
+			).WithFix(`add the package declaring ` + name + ` to the packages`))
+			return nil, false
+		}
+		embeddedMethods, ok := s.ifaceMethods(ep, *embedded, seen, report)
+		if !ok {
+			return nil, false
+		}
+		methods = append(methods, embeddedMethods...)
+	}
+	for _, m := range p.Query.GetIfaceMethods(typ.Name) {
+		methods = append(methods, newMethod(p, m))
+	}
+	return methods, true
+}
+
+// scope packages the declarations are looked up in, the packages of
+// Declarations and the packages they import
+type scope struct {
+	pkgs     []*Package
+	imported map[string]*Package
+}
+
+// lookup returns the type named name as written in file of package p, E.G.
+// "Reader" or "io.Reader". Qualified names are looked up by the import path of
+// the file imports, or by package name, in the packages and then in the
+// imported package.
+func (s *scope) lookup(p *Package, file, name string) (*Package, *parser.Type) {
+	sel, typ, ok := strings.Cut(name, `.`)
+	if !ok {
+		return p, p.Query.GetTypeByName(name)
+	}
+	imp := qualifiers(p, file)[sel]
+	for _, byPath := range []bool{true, false} {
+		for _, o := range s.pkgs {
+			if byPath && o.Path != imp || !byPath && o.Name != sel {
+				continue
+			} else if t := o.Query.GetTypeByName(typ); t != nil {
+				return o, t
+			}
+		}
+	}
+	if o := s.imports(p, imp); o != nil {
+		if t := o.Query.GetTypeByName(typ); t != nil && match.Capitalized(typ) {
+			return o, t
+		}
+	}
+	return nil, nil
+}
+
+// imports returns the package imported as imp by p, parsed from the module or
+// the standard library, or nil if it can not be found or parsed. Packages are
+// parsed once.
+func (s *scope) imports(p *Package, imp string) *Package {
+	if imp == `` {
+		return nil
+	} else if o, ok := s.imported[imp]; ok {
+		return o
+	}
+	s.imported[imp] = nil
+	dir, err := modinfo.ImportDir(p.Dir, imp)
+	if err != nil {
+		return nil
+	}
+	srcs, err := srcio.ReadDir(dir)
+	if err != nil || len(srcs) == 0 {
+		return nil
+	}
+	parsed, err := parser.ParseFiles(srcs)
+	if err != nil {
+		return nil
+	}
+	o := &Package{Dir: dir, Name: parsed.Package, Path: imp, Query: parser.NewQuery(parsed)}
+	s.imported[imp] = o
+	return o
+}
+
+// newMethod normalises a method of package p
+func newMethod(p *Package, m *parser.Method) *Method {
+	mm := *m
+	mm.Pkg = p.Name
+	return &Method{
+		Name:    m.Name,
+		Sig:     signature(mm, qualifiers(p, m.File)),
+		Display: mm.Signature(),
+		Pointer: m.Pointer,
+		Pos:     Position(p, m.File, m.Line),
+	}
+}
+
+// qualifiers returns the import paths of the package selectors of a file of
+// package p keyed by selector. Imports without a name are selected by the last
+// element of the path.
+func qualifiers(p *Package, file string) map[string]string {
+	quals := map[string]string{p.Name: p.Path}
+	for _, imp := range p.Query.Parser.Imports {
+		if filepath.Base(imp.File) != filepath.Base(file) {
+			continue
+		}
+		name := imp.Name
+		if name == `` {
+			name = path.Base(imp.Path)
+		}
+		quals[name] = imp.Path
+	}
+	return quals
+}
+
+// signature returns the signature of m without the parameter names, the types
+// are normalised with quals
+func signature(m parser.Method, quals map[string]string) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(m.Name + `(`)
+	for n, prm := range m.Params() {
+		if n > 0 {
+			buf.WriteString(`, `)
+		}
+		if prm.Variadic {
+			buf.WriteString(`...`)
+		}
+		buf.WriteString(normalise(prm.Type, quals))
+	}
+	buf.WriteString(`)`)
+	var results []string
+	for _, prm := range m.Results() {
+		results = append(results, normalise(prm.Type, quals))
+	}
+	if len(results) > 0 {
+		buf.WriteString(` (` + strings.Join(results, `, `) + `)`)
+	}
+	return buf.String()
+}
+
+// normalise returns the type expression with the empty interface written as
+// any and the package selectors replaced by the import paths in quals, so
+// interface{} and any, or a package imported with different names, compare
+// equal
+func normalise(typ string, quals map[string]string) string {
+	expr, err := goparser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+	expr = astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		switch v := c.Node().(type) {
+		case *ast.InterfaceType:
+			if len(v.Methods.List) == 0 {
+				c.Replace(ast.NewIdent(`any`))
+			}
+		case *ast.SelectorExpr:
+			if x, ok := v.X.(*ast.Ident); ok && quals[x.Name] != `` {
+				c.Replace(&ast.SelectorExpr{X: ast.NewIdent(quals[x.Name]), Sel: v.Sel})
+				return false
+			}
+		}
+		return true
+	}, nil).(ast.Expr)
+	return gotypes.ExprString(expr)
+}
+
+// Match returns true if the type name of package p matches pattern. The
+// pattern can be qualified with the package name, E.G. "print.PrintIface", and
+// can have wildcards.
+func Match(p *Package, name, pattern string) bool {
+	if sel, typ, ok := strings.Cut(pattern, `.`); ok {
+		return sel == p.Name && match.Match(name, typ)
+	}
+	return match.Match(name, pattern)
+}
+
+// Position returns the position of a line of a file of package p
+func Position(p *Package, file string, line int) diag.Position {
+	return diag.Position{File: filepath.Join(p.Dir, file), Line: line}
+}
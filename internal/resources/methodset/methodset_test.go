@@ -0,0 +1,210 @@
+package methodset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+var src = `package store
+
+type Getter interface {
+	Get(id string) (User, error)
+}
+
+type StoreIface interface {
+	Getter
+	Delete(id string) error
+}
+
+type ReaderIface interface {
+	io.Reader
+}
+
+type User struct{}
+
+type Store struct{}
+
+func (s *Store) Get(key string) (User, error) { return User{}, nil }
+
+func (s Store) Delete(id string) error { return nil }
+
+type Cache struct{}
+
+func (c Cache) Get(id string) (*User, error) { return nil, nil }
+`
+
+var testSrc = `package store_test
+
+type mockStore struct{}
+
+func (m mockStore) Get(id string) (store.User, error) { return store.User{}, nil }
+`
+
+func TestDeclarations(t *testing.T) {
+	dir := t.TempDir()
+	for file, s := range map[string]string{`store.go`: src, `store_test.go`: testSrc} {
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(s), 0600)) {
+			t.FailNow()
+		}
+	}
+	pkgs, err := Load([]string{dir})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var reported []string
+	ifaces, concretes := Declarations(pkgs, func(d *diag.Diagnostic) { reported = append(reported, d.Code) })
+	assert.Equal(t, []string{diag.CodeEmbedNotFound}, reported)
+	if !assert.Len(t, ifaces, 2) || !assert.Len(t, concretes, 2) {
+		t.FailNow()
+	}
+	getter, store := ifaces[0], ifaces[1]
+	assert.Equal(t, `store.StoreIface`, store.String())
+	if assert.Len(t, store.Methods, 2) {
+		assert.Equal(t, `Get(string) (store.User, error)`, store.Methods[0].Sig)
+		assert.Equal(t, `Get(id string) (store.User, error)`, store.Methods[0].Display)
+	}
+
+	st, cache := concretes[0], concretes[1]
+	assert.True(t, Compare(st, store, true).Ok())
+	r := Compare(st, store, false)
+	if assert.Len(t, r.Pointer, 1) {
+		assert.Equal(t, `Get`, r.Pointer[0].Name)
+	}
+	r = Compare(cache, store, true)
+	assert.Len(t, r.Missing, 1)
+	assert.Len(t, r.Mismatched, 1)
+	assert.False(t, Compare(cache, getter, true).Ok())
+
+	tests, err := LoadTests([]string{dir})
+	if !assert.NoError(t, err) || !assert.Len(t, tests, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, `store_test`, tests[0].Name)
+	_, mocks := Declarations(tests, func(*diag.Diagnostic) {})
+	if assert.Len(t, mocks, 1) {
+		assert.True(t, Compare(mocks[0], getter, false).Ok())
+	}
+}
+
+var promotedSrc = `package store
+
+type StoreIface interface {
+	Get(id string) error
+	Delete(id string) error
+}
+
+type Store struct{}
+
+func (s *Store) Get(id string) error { return nil }
+
+func (s Store) Delete(id string) error { return nil }
+
+type Wrapper struct {
+	*Store
+}
+
+type Named struct {
+	Store
+	name string
+}
+
+type Node struct {
+	*Node
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Both struct {
+	Named
+	Wrapper
+	Closer
+}
+
+type Shadow struct {
+	Both
+	Store
+}
+`
+
+func TestDeclarations_Promoted(t *testing.T) {
+	dir := t.TempDir()
+	if !assert.NoError(t, os.WriteFile(filepath.Join(dir, `store.go`), []byte(promotedSrc), 0600)) {
+		t.FailNow()
+	}
+	pkgs, err := Load([]string{dir})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ifaces, concretes := Declarations(pkgs, func(*diag.Diagnostic) {})
+	if !assert.Len(t, ifaces, 2) || !assert.Len(t, concretes, 5) {
+		t.FailNow()
+	}
+	store := ifaces[0]
+	byName := map[string]*Concrete{}
+	for _, c := range concretes {
+		byName[c.Type.Name] = c
+	}
+
+	// the methods of an embedded *Store are in the method set of Wrapper
+	wrapper := byName[`Wrapper`]
+	assert.True(t, Compare(wrapper, store, false).Ok())
+
+	// the pointer methods of an embedded Store are only in the method set of *Named
+	named := byName[`Named`]
+	assert.True(t, Compare(named, store, true).Ok())
+	r := Compare(named, store, false)
+	if assert.Len(t, r.Pointer, 1) {
+		assert.Equal(t, `Get`, r.Pointer[0].Name)
+	}
+
+	// methods at a lower depth shadow promoted methods, methods promoted at
+	// the same depth are ambiguous
+	both := byName[`Both`]
+	assert.Contains(t, both.Methods, `Close`)
+	assert.NotContains(t, both.Methods, `Get`)
+	assert.NotContains(t, both.Methods, `Delete`)
+	assert.Len(t, byName[`Shadow`].Methods, 3)
+}
+
+var aliasSrc = `package store
+
+import stdctx "context"
+
+type Putter interface {
+	Put(ctx stdctx.Context, v interface{}) error
+}
+`
+
+var aliasImplSrc = `package store
+
+import "context"
+
+type Memory struct{}
+
+func (m Memory) Put(ctx context.Context, v any) error { return nil }
+`
+
+func TestDeclarations_Normalised(t *testing.T) {
+	dir := t.TempDir()
+	for file, s := range map[string]string{`putter.go`: aliasSrc, `memory.go`: aliasImplSrc} {
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(s), 0600)) {
+			t.FailNow()
+		}
+	}
+	pkgs, err := Load([]string{dir})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ifaces, concretes := Declarations(pkgs, func(*diag.Diagnostic) {})
+	if !assert.Len(t, ifaces, 1) || !assert.Len(t, concretes, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, `Put(context.Context, any) (error)`, ifaces[0].Methods[0].Sig)
+	assert.True(t, Compare(concretes[0], ifaces[0], false).Ok())
+}
@@ -2,6 +2,7 @@ package modinfo
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +18,7 @@ var ErrLatestNotSupported = errors.New(`@latest version currently not supported`
 // ModInfo parsed go.mod file.
 type ModInfo struct {
 	modfile *modfile.File
+	dir     string
 }
 
 // Load Load a go.mod file. If data is not nil then the data is parsed as the
@@ -28,6 +30,7 @@ func Load(file string, data []byte) (*ModInfo, error) {
 	}
 	return &ModInfo{
 		modfile: f,
+		dir:     filepath.Dir(file),
 	}, nil
 }
 
@@ -63,6 +66,8 @@ func (m ModInfo) GetPath(mod string) (realPath string, err error) {
 		return ``, errors.New(`@latest version currently not supported`)
 	case strings.Contains(mod, `@`):
 		realPath = filepath.Join(envs.Gopath(), `pkg`, `mod`, filepath.FromSlash(mod))
+	case IsStd(mod):
+		realPath = filepath.Join(envs.Goroot(), `src`, filepath.FromSlash(mod))
 	case m.modfile != nil:
 		v, err := m.GetVersion(mod)
 		if err != nil {
@@ -81,6 +86,61 @@ func (m ModInfo) GetPath(mod string) (realPath string, err error) {
 	return
 }
 
+// PackageDir returns the directory of the package imported as imp. The package
+// can be in the module of the go.mod file, the standard library or a required
+// module.
+func (m ModInfo) PackageDir(imp string) (string, error) {
+	if m.modfile != nil && m.modfile.Module != nil {
+		if rest, ok := pathWithin(imp, m.modfile.Module.Mod.Path); ok {
+			return filepath.Join(m.dir, filepath.FromSlash(rest)), nil
+		}
+	}
+	if IsStd(imp) {
+		return m.GetPath(imp)
+	}
+	if m.modfile != nil {
+		for _, r := range m.modfile.Require {
+			if rest, ok := pathWithin(imp, r.Mod.Path); ok {
+				dir, err := m.GetPath(r.Mod.Path + `@` + r.Mod.Version)
+				if err != nil {
+					return ``, err
+				}
+				return filepath.Join(dir, filepath.FromSlash(rest)), nil
+			}
+		}
+	}
+	return ``, ErrNotFound
+}
+
+// ImportDir returns the directory of the package imported as imp from the
+// package in dir. The go.mod file is found in the parent directories of dir,
+// standard library packages do not need a go.mod file.
+func ImportDir(dir, imp string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ``, err
+	}
+	mi, err := LoadFromParents(abs)
+	if err != nil && IsStd(imp) {
+		mi, err = &ModInfo{}, nil
+	}
+	if err != nil {
+		return ``, fmt.Errorf(`error loading go.mod file: %w`, err)
+	}
+	return mi.PackageDir(imp)
+}
+
+// pathWithin returns the path of imp relative to the module path mod and true
+// if imp is mod or a package within mod
+func pathWithin(imp, mod string) (string, bool) {
+	if imp == mod {
+		return ``, true
+	} else if strings.HasPrefix(imp, mod+`/`) {
+		return strings.TrimPrefix(imp, mod+`/`), true
+	}
+	return ``, false
+}
+
 // GetImport returns the import string for srcpath by combining the path of the
 // go.mod file with the go source path to generate the import path. The contents
 // of the go.mod file are passed in as the byte array. If gomodpath and/or data
@@ -134,3 +194,10 @@ func findMod(srcpath string) (modpath string, err error) {
 	}
 	return modpath, nil
 }
+
+// IsStd returns true for standard library packages, which have no dot in the
+// first path element
+func IsStd(mod string) bool {
+	first, _, _ := strings.Cut(mod, `/`)
+	return !strings.Contains(first, `.`)
+}
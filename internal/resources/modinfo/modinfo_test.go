@@ -72,6 +72,19 @@ func TestGetModPath(t *testing.T) {
 	assert.DirExists(t, p)
 }
 
+func TestGetModPath_Std(t *testing.T) {
+	p, err := ModInfo{}.GetPath(`io`)
+	if assert.NoError(t, err) {
+		assert.FileExists(t, filepath.Join(p, `io.go`))
+	}
+	p, err = ModInfo{}.GetPath(`database/sql`)
+	if assert.NoError(t, err) {
+		assert.FileExists(t, filepath.Join(p, `sql.go`))
+	}
+	assert.True(t, IsStd(`net/http`))
+	assert.False(t, IsStd(`github.com/stretchr/testify`))
+}
+
 func TestGetModPath_Version(t *testing.T) {
 	mod := `github.com/stretchr/testify`
 	_, filename, _, ok := runtime.Caller(0)
@@ -96,3 +109,26 @@ func TestGetModPath_latest(t *testing.T) {
 	assert.Equal(t, ErrLatestNotSupported, err)
 	assert.Empty(t, p)
 }
+
+func TestModInfo_PackageDir(t *testing.T) {
+	_, filename, _, ok := runtime.Caller(0)
+	assert.True(t, ok)
+	i, err := LoadFromParents(filename)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	p, err := i.PackageDir(`github.com/dexterp/ifaces/internal/resources/modinfo`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, filepath.Dir(filename), p)
+	}
+	p, err = i.PackageDir(`github.com/stretchr/testify/assert`)
+	if assert.NoError(t, err) {
+		assert.FileExists(t, filepath.Join(p, `assertions.go`))
+	}
+	p, err = i.PackageDir(`net/http`)
+	if assert.NoError(t, err) {
+		assert.FileExists(t, filepath.Join(p, `server.go`))
+	}
+	_, err = i.PackageDir(`example.com/unknown`)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+)
+
+// knownOS values of GOOS recognised in file name suffixes, see go/build
+var knownOS = map[string]bool{
+	`aix`: true, `android`: true, `darwin`: true, `dragonfly`: true, `freebsd`: true, `hurd`: true,
+	`illumos`: true, `ios`: true, `js`: true, `linux`: true, `nacl`: true, `netbsd`: true,
+	`openbsd`: true, `plan9`: true, `solaris`: true, `windows`: true, `zos`: true,
+}
+
+// knownArch values of GOARCH recognised in file name suffixes, see go/build
+var knownArch = map[string]bool{
+	`386`: true, `amd64`: true, `amd64p32`: true, `arm`: true, `armbe`: true, `arm64`: true,
+	`arm64be`: true, `loong64`: true, `mips`: true, `mipsle`: true, `mips64`: true, `mips64le`: true,
+	`mips64p32`: true, `mips64p32le`: true, `ppc`: true, `ppc64`: true, `ppc64le`: true, `riscv`: true,
+	`riscv64`: true, `s390`: true, `s390x`: true, `sparc`: true, `sparc64`: true, `wasm`: true,
+}
+
+// fileNameTags returns the GOOS and GOARCH tags of the file name suffixes the
+// way the go command reads them, E.G. "linux" and "amd64" for
+// "store_linux_amd64.go" and none for "linux.go"
+func fileNameTags(file string) []string {
+	name := strings.TrimSuffix(filepath.Base(file), `.go`)
+	name = strings.TrimSuffix(name, `_test`)
+	i := strings.Index(name, `_`)
+	if i < 0 {
+		return nil
+	}
+	l := strings.Split(name[i:], `_`)
+	n := len(l)
+	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return []string{l[n-2], l[n-1]}
+	} else if knownOS[l[n-1]] || knownArch[l[n-1]] {
+		return []string{l[n-1]}
+	}
+	return nil
+}
+
+// hasTag reports whether tag is a term of the conjunction expr
+func hasTag(expr constraint.Expr, tag string) bool {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		return e.Tag == tag
+	case *constraint.AndExpr:
+		return hasTag(e.X, tag) || hasTag(e.Y, tag)
+	}
+	return false
+}
+
+// andExpr returns x && y, or x if y is nil
+func andExpr(x, y constraint.Expr) constraint.Expr {
+	if y == nil {
+		return x
+	}
+	return &constraint.AndExpr{X: x, Y: y}
+}
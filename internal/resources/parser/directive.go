@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// directivePrefix prefix of ifaces annotations in doc comments
+const directivePrefix = `//ifaces:`
+
+// Directive an ifaces annotation within a doc comment. E.G. the comment
+// "//ifaces:ignore StoreIface" is the directive "ignore" with the argument
+// "StoreIface".
+type Directive struct {
+	Name string   // Name directive name
+	Args []string // Args space separated arguments
+	Text string   // Text directive text following the name
+	Line int      // Line line number of the comment
+}
+
+// Directives list of directives
+type Directives []Directive
+
+// Get returns the directives matching name
+func (d Directives) Get(name string) (out Directives) {
+	for _, dir := range d {
+		if dir.Name == name {
+			out = append(out, dir)
+		}
+	}
+	return
+}
+
+// Ignore returns true if an ignore directive applies to iface. An ignore
+// directive without arguments applies to every interface.
+func (d Directives) Ignore(iface string) bool {
+	for _, dir := range d.Get(`ignore`) {
+		if len(dir.Args) == 0 {
+			return true
+		}
+		for _, a := range dir.Args {
+			if a == iface {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseDirectives returns the ifaces directives in a comment group. Directives
+// are not part of the text returned by ast.CommentGroup.Text.
+func parseDirectives(fset *token.FileSet, cg *ast.CommentGroup) (d Directives) {
+	if cg == nil {
+		return nil
+	}
+	for _, c := range cg.List {
+		if !strings.HasPrefix(c.Text, directivePrefix) {
+			continue
+		}
+		text := strings.TrimPrefix(c.Text, directivePrefix)
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		d = append(d, Directive{
+			Name: fields[0],
+			Args: fields[1:],
+			Text: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), fields[0])),
+			Line: fset.Position(c.Pos()).Line,
+		})
+	}
+	return
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotGeneralizable = errors.New(`signatures can not be generalized`)
+)
+
+// TypeParams type parameters inferred by Generalize. The functions can differ
+// by one set of types, which becomes the type parameter T wherever it is used.
+type TypeParams struct {
+	Names []string   // Names type parameter names
+	Args  [][]string // Args type arguments of each type parameter in the order of the generalized functions
+	index map[string]int
+}
+
+// add returns the name of the type parameter for args. It fails if the type
+// parameter has other type arguments.
+func (t *TypeParams) add(args []string) (string, error) {
+	if t.index == nil {
+		t.index = map[string]int{}
+	}
+	key := strings.Join(args, "\x00")
+	if i, ok := t.index[key]; ok {
+		return t.Names[i], nil
+	} else if len(t.Names) > 0 {
+		return ``, fmt.Errorf(`%w: the types %s differ from the types %s of %s`, ErrNotGeneralizable,
+			strings.Join(args, `, `), strings.Join(t.Args[0], `, `), t.Names[0])
+	}
+	t.index[key] = len(t.Names)
+	t.Names = append(t.Names, `T`)
+	t.Args = append(t.Args, args)
+	return `T`, nil
+}
+
+// truncate removes the type parameters added after the first n
+func (t *TypeParams) truncate(n int) {
+	for _, args := range t.Args[n:] {
+		delete(t.index, strings.Join(args, "\x00"))
+	}
+	t.Names = t.Names[:n]
+	t.Args = t.Args[:n]
+}
+
+// Generalize compares the methods structurally and returns a copy of the first
+// method in which the type expressions that differ between the methods are
+// replaced by the type parameter. Methods can be generalized if they have the
+// same number of parameters and results, the same variadic parameters and
+// differ by the type arguments of params only. Types
+// declared in the parsed source are qualified with pkg if it is not empty.
+// params is left unchanged if the methods can not be generalized.
+func Generalize(methods []*Method, pkg string, params *TypeParams) (m *Method, err error) {
+	if len(methods) == 0 {
+		return nil, ErrNotGeneralizable
+	}
+	n := len(params.Names)
+	defer func() {
+		if err != nil {
+			params.truncate(n)
+		}
+	}()
+	g := &generalizer{
+		q:      methods[0].fn.qualify(pkg),
+		params: params,
+	}
+	fns := make([]*Func, len(methods))
+	for i, m := range methods {
+		fns[i] = m.fn
+	}
+	fn := &Func{
+		Prefixes: map[string]any{},
+		hasType:  fns[0].hasType,
+		pkg:      fns[0].pkg,
+		name:     fns[0].name,
+	}
+	fn.params, err = g.fields(fns, func(f *Func) []*param { return f.params })
+	if err != nil {
+		return nil, err
+	}
+	fn.results, err = g.fields(fns, func(f *Func) []*param { return f.results })
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range append(append([]*param{}, fn.params...), fn.results...) {
+		addPrefixes(p.typ, fn.Prefixes)
+	}
+	c := *methods[0]
+	c.fn = fn
+	c.Prefixes = parseSigPrefixes(fn)
+	return &c, nil
+}
+
+// addPrefixes adds the package selectors used by a type expression to prefixes.
+// Selectors of types replaced by a type parameter are not used.
+func addPrefixes(e typeExpr, prefixes map[string]any) {
+	switch t := e.(type) {
+	case *typ:
+		if t.pkg != `` {
+			prefixes[t.pkg] = struct{}{}
+		}
+	case *typChan:
+		addPrefixes(t.typ, prefixes)
+	case *typMap:
+		addPrefixes(t.key, prefixes)
+		addPrefixes(t.typ, prefixes)
+	case *typSlice:
+		addPrefixes(t.typ, prefixes)
+	case *typSub:
+		addPrefixes(t.typ, prefixes)
+	case *typFunc:
+		for _, p := range append(append([]*param{}, t.params...), t.results...) {
+			addPrefixes(p.typ, prefixes)
+		}
+	}
+}
+
+type generalizer struct {
+	q      qualifier
+	params *TypeParams
+}
+
+func (g generalizer) fields(fns []*Func, list func(f *Func) []*param) (out []*param, err error) {
+	first := list(fns[0])
+	for _, f := range fns[1:] {
+		if len(list(f)) != len(first) {
+			return nil, fmt.Errorf(`%w: different number of parameters or results`, ErrNotGeneralizable)
+		}
+	}
+	for i, p := range first {
+		exprs := make([]typeExpr, len(fns))
+		for j, f := range fns {
+			exprs[j] = list(f)[i].typ
+		}
+		typ, err := g.expr(exprs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &param{
+			name: p.name,
+			typ:  typ,
+		})
+	}
+	return out, nil
+}
+
+// expr returns the generalized expression of exprs. Nodes which are equal are
+// compared by their children, otherwise the expression is replaced by a type
+// parameter.
+func (g generalizer) expr(exprs []typeExpr) (typeExpr, error) {
+	ellipsis := ellipsisOf(exprs[0])
+	for _, e := range exprs {
+		if e == nil {
+			return nil, fmt.Errorf(`%w: unsupported type expression`, ErrNotGeneralizable)
+		} else if ellipsisOf(e) != ellipsis {
+			return nil, fmt.Errorf(`%w: variadic mismatch`, ErrNotGeneralizable)
+		}
+	}
+	switch first := exprs[0].(type) {
+	case *typ:
+		same := true
+		for _, e := range exprs[1:] {
+			t, ok := e.(*typ)
+			same = same && ok && t.star == first.star && t.pkg == first.pkg && t.name == first.name
+		}
+		if same {
+			return first, nil
+		}
+	case *typInterface:
+		same := true
+		for _, e := range exprs[1:] {
+			t, ok := e.(*typInterface)
+			same = same && ok && t.star == first.star
+		}
+		if same {
+			return first, nil
+		}
+	case *typSlice:
+		elts := []typeExpr{}
+		for _, e := range exprs {
+			if t, ok := e.(*typSlice); ok && t.star == first.star {
+				elts = append(elts, t.typ)
+			}
+		}
+		if len(elts) == len(exprs) {
+			elt, err := g.expr(elts)
+			if err != nil {
+				return nil, err
+			}
+			return &typSlice{ellipsis: first.ellipsis, star: first.star, typ: elt}, nil
+		}
+	case *typMap:
+		keys, values := []typeExpr{}, []typeExpr{}
+		for _, e := range exprs {
+			if t, ok := e.(*typMap); ok && t.star == first.star {
+				keys = append(keys, t.key)
+				values = append(values, t.typ)
+			}
+		}
+		if len(keys) == len(exprs) {
+			key, err := g.expr(keys)
+			if err != nil {
+				return nil, err
+			}
+			value, err := g.expr(values)
+			if err != nil {
+				return nil, err
+			}
+			return &typMap{ellipsis: first.ellipsis, star: first.star, key: key, typ: value}, nil
+		}
+	case *typChan:
+		values := []typeExpr{}
+		for _, e := range exprs {
+			if t, ok := e.(*typChan); ok && t.star == first.star && t.recv == first.recv && t.send == first.send {
+				values = append(values, t.typ)
+			}
+		}
+		if len(values) == len(exprs) {
+			value, err := g.expr(values)
+			if err != nil {
+				return nil, err
+			}
+			return &typChan{ellipsis: first.ellipsis, star: first.star, recv: first.recv, send: first.send, typ: value}, nil
+		}
+	case *typFunc:
+		fns := []*Func{}
+		for _, e := range exprs {
+			if t, ok := e.(*typFunc); ok && t.star == first.star {
+				fns = append(fns, &Func{params: t.params, results: t.results})
+			}
+		}
+		if len(fns) == len(exprs) {
+			params, err := g.fields(fns, func(f *Func) []*param { return f.params })
+			if err != nil {
+				return nil, err
+			}
+			results, err := g.fields(fns, func(f *Func) []*param { return f.results })
+			if err != nil {
+				return nil, err
+			}
+			return &typFunc{ellipsis: first.ellipsis, star: first.star, params: params, results: results}, nil
+		}
+	}
+	args := make([]string, len(exprs))
+	for i, e := range exprs {
+		args[i] = strings.TrimPrefix(e.string(g.q), `...`)
+	}
+	name, err := g.params.add(args)
+	if err != nil {
+		return nil, err
+	}
+	return &typParam{
+		ellipsis: ellipsis,
+		name:     name,
+	}, nil
+}
+
+// ellipsisOf returns the ellipsis of a type expression
+func ellipsisOf(e typeExpr) string {
+	switch t := e.(type) {
+	case *typ:
+		return t.ellipsis
+	case *typChan:
+		return t.ellipsis
+	case *typFunc:
+		return t.ellipsis
+	case *typInterface:
+		return t.ellipsis
+	case *typMap:
+		return t.ellipsis
+	case *typSlice:
+		return t.ellipsis
+	case *typParam:
+		return t.ellipsis
+	case *typSub:
+		return t.ellipsis
+	}
+	return ``
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneralize(t *testing.T) {
+	src := `package mypkg
+
+type User struct{}
+
+type Order struct{}
+
+type UserRepo struct{}
+
+func (r *UserRepo) Get(id string) (*User, error) { return nil, nil }
+
+func (r *UserRepo) List(ids ...string) ([]*User, map[string]*User) { return nil, nil }
+
+func (r *UserRepo) Key(u *User) int { return 0 }
+
+func (r *UserRepo) Find(q string) *User { return nil }
+
+type OrderRepo struct{}
+
+func (r *OrderRepo) Get(key string) (*Order, error) { return nil, nil }
+
+func (r *OrderRepo) List(ids ...string) ([]*Order, map[string]*Order) { return nil, nil }
+
+func (r *OrderRepo) Key(o *Order) string { return "" }
+
+func (r *OrderRepo) Find(q ...string) *Order { return nil }
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	q := NewQuery(p)
+	method := func(typ, name string) *Method {
+		for _, m := range q.GetRecvsByType(typ) {
+			if m.Name == name {
+				return m
+			}
+		}
+		return nil
+	}
+	pair := func(name string) []*Method {
+		return []*Method{method(`UserRepo`, name), method(`OrderRepo`, name)}
+	}
+
+	params := &TypeParams{}
+	m, err := Generalize(pair(`Get`), ``, params)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `Get(id string) (T, error)`, m.Signature())
+	}
+	m, err = Generalize(pair(`List`), ``, params)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `List(ids ...string) ([]T, map[string]T)`, m.Signature())
+	}
+	_, err = Generalize(pair(`Key`), ``, params)
+	assert.ErrorIs(t, err, ErrNotGeneralizable)
+	assert.Equal(t, []string{`T`}, params.Names)
+	assert.Equal(t, [][]string{{`*User`, `*Order`}}, params.Args)
+
+	_, err = Generalize(pair(`Find`), ``, params)
+	assert.ErrorIs(t, err, ErrNotGeneralizable)
+	assert.Len(t, params.Names, 1)
+
+	params = &TypeParams{}
+	_, err = Generalize(pair(`Key`), ``, params)
+	assert.ErrorIs(t, err, ErrNotGeneralizable)
+	assert.Empty(t, params.Names)
+
+	params = &TypeParams{}
+	m, err = Generalize(pair(`Get`), `mypkg`, params)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `Get(id string) (T, error)`, m.Signature())
+	}
+	assert.Equal(t, [][]string{{`*mypkg.User`, `*mypkg.Order`}}, params.Args)
+}
+
+func TestParser_TypeParams(t *testing.T) {
+	src := `package mypkg
+
+type Repo[T any, K comparable] interface {
+	Get(id K) (T, error)
+}
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	typ := NewQuery(p).GetTypeByName(`Repo`)
+	if assert.NotNil(t, typ) {
+		assert.Equal(t, `[T any, K comparable]`, typ.TypeParams)
+	}
+}
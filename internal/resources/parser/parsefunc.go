@@ -25,12 +25,26 @@ func IfaceToFunc(f *ast.Field, hasType typecheck.HasType) *Func {
 
 type Func struct {
 	Prefixes map[string]any
+	hasType  typecheck.HasType
 	pkg      string
 	name     string
 	params   []*param
 	results  []*param
 }
 
+// Param function parameter or result
+type Param struct {
+	Name     string // Name parameter name, empty if the parameter is unnamed
+	Type     string // Type type expression, the element type if variadic
+	Variadic bool   // Variadic true if the parameter is variadic
+	Pkg      string // Pkg package of a named type, empty for builtin and composite types
+}
+
+// qualifier returns the name of a type as written in the generated source. sel
+// is the package selector of the type in the parsed source and is empty for
+// unqualified types.
+type qualifier func(sel, name string) string
+
 // Package set package name
 func (f *Func) Package(pkg string) *Func {
 	f.pkg = pkg
@@ -38,42 +52,93 @@ func (f *Func) Package(pkg string) *Func {
 }
 
 func (f *Func) String() string {
+	return f.Signature(f.pkg)
+}
+
+// Signature returns the function signature. Types declared in the parsed
+// source are qualified with pkg if it is not empty.
+func (f *Func) Signature(pkg string) string {
+	q := f.qualify(pkg)
 	buf := &bytes.Buffer{}
 	buf.WriteString(f.name)
-	buf.WriteString(f.stringParams())
-	buf.WriteString(f.stringReturns())
+	buf.WriteString(stringParams(f.params, q))
+	buf.WriteString(stringReturns(f.results, q))
 	return buf.String()
 }
 
-func (f *Func) stringParams() string {
+// Params returns the function parameters. Types declared in the parsed source
+// are qualified with pkg if it is not empty.
+func (f *Func) Params(pkg string) []Param {
+	return toParams(f.params, f.qualify(pkg))
+}
+
+// Results returns the function results. Types declared in the parsed source are
+// qualified with pkg if it is not empty.
+func (f *Func) Results(pkg string) []Param {
+	return toParams(f.results, f.qualify(pkg))
+}
+
+func (f *Func) qualify(pkg string) qualifier {
+	return func(sel, name string) string {
+		if sel != `` {
+			return sel + `.` + name
+		} else if pkg != `` && f.hasType != nil && f.hasType(name) {
+			return pkg + `.` + name
+		}
+		return name
+	}
+}
+
+func toParams(params []*param, q qualifier) (out []Param) {
+	for _, p := range params {
+		prm := Param{
+			Name: p.name,
+		}
+		if p.typ != nil {
+			expr := p.typ.string(q)
+			prm.Variadic = strings.HasPrefix(expr, `...`)
+			prm.Type = strings.TrimPrefix(expr, `...`)
+			if t, ok := p.typ.(*typ); ok {
+				name := q(t.pkg, t.name)
+				if i := strings.LastIndex(name, `.`); i >= 0 {
+					prm.Pkg = name[:i]
+				}
+			}
+		}
+		out = append(out, prm)
+	}
+	return
+}
+
+func stringParams(params []*param, q qualifier) string {
 	buf := &bytes.Buffer{}
-	if len(f.params) == 0 {
+	if len(params) == 0 {
 		buf.WriteString(`()`)
 	} else {
 		l := []string{}
-		for _, p := range f.params {
-			l = append(l, p.string())
+		for _, p := range params {
+			l = append(l, p.string(q))
 		}
 		buf.WriteString(`(` + strings.Join(l, `, `) + `)`)
 	}
 	return buf.String()
 }
 
-func (f *Func) stringReturns() string {
+func stringReturns(results []*param, q qualifier) string {
 	buf := &bytes.Buffer{}
-	switch len(f.results) {
+	switch len(results) {
 	case 0:
 		return ``
 	case 1:
-		if strings.Contains(f.results[0].string(), " ") {
-			buf.WriteString(` (` + f.results[0].string() + `)`)
+		if strings.Contains(results[0].string(q), " ") {
+			buf.WriteString(` (` + results[0].string(q) + `)`)
 		} else {
-			buf.WriteString(` ` + f.results[0].string())
+			buf.WriteString(` ` + results[0].string(q))
 		}
 	default:
 		l := []string{}
-		for _, p := range f.results {
-			l = append(l, p.string())
+		for _, p := range results {
+			l = append(l, p.string(q))
 		}
 		buf.WriteString(` (` + strings.Join(l, `, `) + `)`)
 	}
@@ -81,9 +146,8 @@ func (f *Func) stringReturns() string {
 }
 
 type funcparse struct {
-	hasType   typecheck.HasType
-	pkgIfNone *string
-	prefixes  map[string]any
+	hasType  typecheck.HasType
+	prefixes map[string]any
 }
 
 func (p funcparse) recvMethod(f *ast.FuncDecl) *Func {
@@ -95,9 +159,9 @@ func (p funcparse) recvMethod(f *ast.FuncDecl) *Func {
 	)
 	fn := &Func{
 		name:     name,
+		hasType:  p.hasType,
 		Prefixes: map[string]any{},
 	}
-	p.pkgIfNone = &fn.pkg
 	p.prefixes = fn.Prefixes
 
 	if f.Type.Params != nil {
@@ -120,9 +184,9 @@ func (p funcparse) ifaceMethod(f *ast.Field) *Func {
 	}
 	fn := &Func{
 		name:     funcName,
+		hasType:  p.hasType,
 		Prefixes: map[string]any{},
 	}
-	p.pkgIfNone = &fn.pkg
 	p.prefixes = fn.Prefixes
 	if ft.Params != nil {
 		fn.params = p.params(ft.Params.List)
@@ -146,7 +210,13 @@ func (p *funcparse) parseExpr(n ast.Expr) typeExpr {
 	if t := p.typSlice(n, ``, ``); t != nil {
 		return t
 	}
-	return p.typMap(n, ``, ``)
+	if t := p.typFunc(n, ``, ``); t != nil {
+		return t
+	}
+	if t := p.typMap(n, ``, ``); t != nil {
+		return t
+	}
+	return nil
 }
 
 func (p *funcparse) params(fields []*ast.Field) (prms []*param) {
@@ -181,12 +251,10 @@ func (p *funcparse) typ(expr ast.Expr, ellip, star, pkg string) *typ {
 			p.prefixes[pkg] = struct{}{}
 		}
 		return &typ{
-			ellipsis:  ellip,
-			hasType:   p.hasType,
-			star:      star,
-			pkgIfNone: p.pkgIfNone,
-			pkg:       pkg,
-			name:      v.Name,
+			ellipsis: ellip,
+			star:     star,
+			pkg:      pkg,
+			name:     v.Name,
 		}
 	}
 	return nil
@@ -214,6 +282,28 @@ func (p *funcparse) typChan(expr ast.Expr, ellip, star string) *typChan {
 	return nil
 }
 
+func (p *funcparse) typFunc(expr ast.Expr, ellip, star string) *typFunc {
+	switch v := expr.(type) {
+	case *ast.Ellipsis:
+		return p.typFunc(v.Elt, `...`, star)
+	case *ast.StarExpr:
+		return p.typFunc(v.X, ellip, `*`)
+	case *ast.FuncType:
+		f := &typFunc{
+			ellipsis: ellip,
+			star:     star,
+		}
+		if v.Params != nil {
+			f.params = p.params(v.Params.List)
+		}
+		if v.Results != nil {
+			f.results = p.params(v.Results.List)
+		}
+		return f
+	}
+	return nil
+}
+
 func (p *funcparse) typeInterface(expr ast.Expr, ellip, star string) *typInterface {
 	switch v := expr.(type) {
 	case *ast.Ellipsis:
@@ -269,30 +359,22 @@ type param struct {
 	typ  typeExpr
 }
 
-func (p param) string() string {
+func (p param) string(q qualifier) string {
 	if p.typ != nil {
-		return strings.Join(stringx.NotEmpty(p.name, p.typ.string()), ` `)
+		return strings.Join(stringx.NotEmpty(p.name, p.typ.string(q)), ` `)
 	}
 	return p.name
 }
 
 type typ struct {
-	ellipsis  string // ellipsis expression, `...` if set or empty
-	hasType   typecheck.HasType
-	star      string  // star expression, `*` if set or empty
-	pkgIfNone *string // set the package name if empty
-	pkg       string  // package to which the type belongs
-	name      string  // type name
-}
-
-func (t typ) string() string {
-	pkg := t.pkg
-	if pkg != `` {
-		pkg = pkg + `.`
-	} else if *t.pkgIfNone != `` && t.hasType(t.name) {
-		pkg = *t.pkgIfNone + `.`
-	}
-	return t.ellipsis + t.star + pkg + t.name
+	ellipsis string // ellipsis expression, `...` if set or empty
+	star     string // star expression, `*` if set or empty
+	pkg      string // package to which the type belongs
+	name     string // type name
+}
+
+func (t typ) string(q qualifier) string {
+	return t.ellipsis + t.star + q(t.pkg, t.name)
 }
 
 type typChan struct {
@@ -303,8 +385,19 @@ type typChan struct {
 	typ      typeExpr
 }
 
-func (t typChan) string() string {
-	return t.ellipsis + t.star + t.recv + `chan` + t.send + ` ` + t.typ.string()
+func (t typChan) string(q qualifier) string {
+	return t.ellipsis + t.star + t.recv + `chan` + t.send + ` ` + t.typ.string(q)
+}
+
+type typFunc struct {
+	ellipsis string // ellipsis expression, `...` if set or empty
+	star     string // star expression, `*` if set or empty
+	params   []*param
+	results  []*param
+}
+
+func (t typFunc) string(q qualifier) string {
+	return t.ellipsis + t.star + `func` + stringParams(t.params, q) + stringReturns(t.results, q)
 }
 
 type typInterface struct {
@@ -312,7 +405,7 @@ type typInterface struct {
 	star     string // star expression, `*` if set or empty
 }
 
-func (t typInterface) string() string {
+func (t typInterface) string(q qualifier) string {
 	return t.ellipsis + t.star + `interface{}`
 }
 
@@ -323,8 +416,8 @@ type typMap struct {
 	typ      typeExpr
 }
 
-func (t typMap) string() string {
-	return t.ellipsis + t.star + `map[` + t.key.string() + `]` + t.typ.string()
+func (t typMap) string(q qualifier) string {
+	return t.ellipsis + t.star + `map[` + t.key.string(q) + `]` + t.typ.string(q)
 }
 
 type typSlice struct {
@@ -333,10 +426,20 @@ type typSlice struct {
 	typ      typeExpr
 }
 
-func (t typSlice) string() string {
-	return t.ellipsis + t.star + `[]` + t.typ.string()
+func (t typSlice) string(q qualifier) string {
+	return t.ellipsis + t.star + `[]` + t.typ.string(q)
 }
 
 type typeExpr interface {
-	string() string
+	string(q qualifier) string
+}
+
+// typParam type parameter created by Generalize
+type typParam struct {
+	ellipsis string // ellipsis expression, `...` if set or empty
+	name     string // type parameter name
+}
+
+func (t typParam) string(q qualifier) string {
+	return t.ellipsis + t.name
 }
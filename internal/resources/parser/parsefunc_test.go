@@ -84,6 +84,15 @@ func TestRecvToFunc_ParamsMapSlice(t *testing.T) {
 	assert.Equal(t, inSig, f.String())
 }
 
+func TestRecvToFunc_ParamsFunc(t *testing.T) {
+	astFuncDecl, inSig, err := makeFuncType(`ParamsFunc`, `fn func(file string) (*bytes.Buffer, error)`, `map[string]func() error`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	f := RecvToFunc(astFuncDecl, hasTypeMock(``))
+	assert.Equal(t, inSig, f.String())
+}
+
 func TestRecvToFunc_ReturnsGroupSlice(t *testing.T) {
 	astFuncDecl, inSig, err := makeFuncType(`ReturnsGroupSlice`, `in ...string`, `a, b, c []string`)
 	if !assert.NoError(t, err) {
@@ -176,3 +185,21 @@ func hasTypeMock(typ string) func(string) bool {
 		return typ == chk
 	}
 }
+
+func TestFunc_Params(t *testing.T) {
+	astFuncDecl, _, err := makeFuncType(`Params`, `ctx context.Context, t *MyType, items ...[]string`, `n int, err error`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	f := RecvToFunc(astFuncDecl, hasTypeMock(`MyType`))
+	assert.Equal(t, []Param{
+		{Name: `ctx`, Type: `context.Context`, Pkg: `context`},
+		{Name: `t`, Type: `*pkg.MyType`, Pkg: `pkg`},
+		{Name: `items`, Type: `[]string`, Variadic: true},
+	}, f.Params(`pkg`))
+	assert.Equal(t, []Param{
+		{Name: `n`, Type: `int`},
+		{Name: `err`, Type: `error`},
+	}, f.Results(`pkg`))
+	assert.Equal(t, `*MyType`, f.Params(``)[1].Type)
+}
@@ -2,13 +2,17 @@ package parser
 
 import (
 	"go/ast"
+	"go/build/constraint"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	gotypes "go/types"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
 	"github.com/dexterp/ifaces/internal/resources/srcio"
 	"github.com/dexterp/ifaces/internal/resources/typecheck"
 	"github.com/dexterp/ifaces/internal/resources/types"
@@ -23,6 +27,10 @@ type Parser struct {
 	// Package package name
 	Package string
 
+	// Builds build constraint expressions of the files with a "//go:build"
+	// line, keyed by the base file name
+	Builds map[string]string
+
 	// Comment comments which match the prefix "//go:generate ifaces"
 	Comments []Comment
 
@@ -52,6 +60,7 @@ func Parse(file string, src any, line int) (*Parser, error) {
 	}
 	return &Parser{
 		Package:          p.pkg,
+		Builds:           p.builds,
 		Comments:         p.comments,
 		InterfaceMethods: p.ifaceMethods,
 		Imports:          p.imports,
@@ -71,6 +80,7 @@ func ParseFiles(srcs []srcio.Source) (*Parser, error) {
 	}
 	return &Parser{
 		Package:          p.pkg,
+		Builds:           p.builds,
 		Comments:         p.comments,
 		InterfaceMethods: p.ifaceMethods,
 		Imports:          p.imports,
@@ -81,6 +91,7 @@ func ParseFiles(srcs []srcio.Source) (*Parser, error) {
 
 type parse struct {
 	pkg          string
+	builds       map[string]string
 	comments     []Comment
 	ifaceMethods []*Method
 	imports      []*Import
@@ -103,9 +114,12 @@ func (p *parse) hasTypeCheck() typecheck.HasType {
 func (p *parse) parse(path string, src any, line int) error {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
-	if err != nil {
-		return err
+	if el, ok := err.(scanner.ErrorList); ok {
+		return diag.FromScanner(el)
+	} else if err != nil {
+		return diag.Errorf(diag.CodeError, diag.Position{File: path}, `%w`, err)
 	}
+	p.parseBuild(f, path)
 	p.parseAstFile(fset, f, path)
 	p.parseComments(fset, f.Comments, path)
 	p.parseImports(f.Imports, path)
@@ -135,16 +149,62 @@ func (p *parse) parseAstGenDecl(fset *token.FileSet, astGenDecl *ast.GenDecl, fi
 		if !ok {
 			continue
 		}
-		p.parseType(fset, astGenDecl, ts, file)
+		var embeds []string
 		switch v := ts.Type.(type) {
 		case *ast.InterfaceType:
 			for _, astField := range v.Methods.List {
+				if len(astField.Names) == 0 {
+					embeds = append(embeds, gotypes.ExprString(astField.Type))
+					continue
+				}
 				p.parseInterfaceMethod(fset, ts, astField, file)
 			}
+		case *ast.StructType:
+			for _, astField := range v.Fields.List {
+				if len(astField.Names) == 0 {
+					embeds = append(embeds, gotypes.ExprString(astField.Type))
+				}
+			}
 		}
+		p.parseType(fset, astGenDecl, ts, file, embeds)
 	}
 }
 
+// parseBuild records the build constraint of a file, the "//go:build" line
+// and the constraint of the _GOOS and _GOARCH file name suffixes, E.G. "linux"
+// for "store_linux.go"
+func (p *parse) parseBuild(f *ast.File, file string) {
+	var expr constraint.Expr
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package || expr != nil {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			e, err := constraint.Parse(c.Text)
+			if err == nil {
+				expr = e
+				break
+			}
+		}
+	}
+	tags := fileNameTags(file)
+	for i := len(tags) - 1; i >= 0; i-- {
+		if !hasTag(expr, tags[i]) {
+			expr = andExpr(&constraint.TagExpr{Tag: tags[i]}, expr)
+		}
+	}
+	if expr == nil {
+		return
+	}
+	if p.builds == nil {
+		p.builds = map[string]string{}
+	}
+	p.builds[filepath.Base(file)] = expr.String()
+}
+
 // parseComments
 func (p *parse) parseComments(fset *token.FileSet, cgs []*ast.CommentGroup, file string) {
 	for _, cg := range cgs {
@@ -182,13 +242,15 @@ func (p *parse) parseImports(imp []*ast.ImportSpec, file string) {
 func (p *parse) parseInterfaceMethod(fset *token.FileSet, ts *ast.TypeSpec, astField *ast.Field, file string) {
 	fn := IfaceToFunc(astField, p.hasTypeCheck())
 	p.ifaceMethods = append(p.ifaceMethods, &Method{
-		Doc:      astField.Doc.Text(),
-		File:     filepath.Base(file),
-		Line:     fset.Position(astField.Pos()).Line,
-		Name:     astField.Names[0].String(),
-		fn:       fn,
-		TypeName: ts.Name.String(),
-		HasType:  p.hasTypeCheck(),
+		Directives: parseDirectives(fset, astField.Doc),
+		Doc:        astField.Doc.Text(),
+		File:       filepath.Base(file),
+		Line:       fset.Position(astField.Pos()).Line,
+		Name:       astField.Names[0].String(),
+		Prefixes:   parseSigPrefixes(fn),
+		fn:         fn,
+		TypeName:   ts.Name.String(),
+		HasType:    p.hasTypeCheck(),
 	})
 }
 
@@ -198,14 +260,17 @@ func (p *parse) parseReceiverMethods(fset *token.FileSet, astFuncDecl *ast.FuncD
 	}
 	fn := RecvToFunc(astFuncDecl, p.hasTypeCheck())
 	p.recvMethods = append(p.recvMethods, &Method{
-		Doc:      strings.TrimSuffix(astFuncDecl.Doc.Text(), "\n"),
-		File:     filepath.Base(file),
-		Line:     fset.Position(astFuncDecl.Pos()).Line,
-		Name:     astFuncDecl.Name.String(),
-		Prefixes: parseSigPrefixes(fn),
-		fn:       fn,
-		TypeName: parseReceiverMethodsTypeName(*astFuncDecl),
-		HasType:  p.hasTypeCheck(),
+		Directives: parseDirectives(fset, astFuncDecl.Doc),
+		Doc:        strings.TrimSuffix(astFuncDecl.Doc.Text(), "\n"),
+		File:       filepath.Base(file),
+		Line:       fset.Position(astFuncDecl.Pos()).Line,
+		Name:       astFuncDecl.Name.String(),
+		Pointer:    parseReceiverPointer(*astFuncDecl),
+		Prefixes:   parseSigPrefixes(fn),
+		Receiver:   true,
+		fn:         fn,
+		TypeName:   parseReceiverMethodsTypeName(*astFuncDecl),
+		HasType:    p.hasTypeCheck(),
 	})
 }
 
@@ -231,16 +296,44 @@ func parseReceiverMethodsTypeName(astFuncDecl ast.FuncDecl) string {
 	return ``
 }
 
-func (p *parse) parseType(fset *token.FileSet, astGenDecl *ast.GenDecl, astTypeSpec *ast.TypeSpec, file string) {
+func parseReceiverPointer(astFuncDecl ast.FuncDecl) bool {
+	if len(astFuncDecl.Recv.List) != 1 {
+		return false
+	}
+	_, ok := astFuncDecl.Recv.List[0].Type.(*ast.StarExpr)
+	return ok
+}
+
+func (p *parse) parseType(fset *token.FileSet, astGenDecl *ast.GenDecl, astTypeSpec *ast.TypeSpec, file string, embeds []string) {
 	p.types = append(p.types, Type{
-		Doc:  strings.TrimSuffix(astGenDecl.Doc.Text(), "\n"),
-		File: filepath.Base(file),
-		Line: fset.Position(astTypeSpec.Pos()).Line,
-		Name: strings.TrimSuffix(astTypeSpec.Name.String(), "\n"),
-		Type: parseTypeType(astTypeSpec),
+		Directives: parseDirectives(fset, astGenDecl.Doc),
+		Embeds:     embeds,
+		Doc:        strings.TrimSuffix(astGenDecl.Doc.Text(), "\n"),
+		File:       filepath.Base(file),
+		Line:       fset.Position(astTypeSpec.Pos()).Line,
+		Name:       strings.TrimSuffix(astTypeSpec.Name.String(), "\n"),
+		Type:       parseTypeType(astTypeSpec),
+		TypeParams: parseTypeParams(astTypeSpec),
 	})
 }
 
+// parseTypeParams returns the type parameter list of a generic type, e.g.
+// "[T any]", or empty
+func parseTypeParams(astTypeSpec *ast.TypeSpec) string {
+	if astTypeSpec.TypeParams == nil || len(astTypeSpec.TypeParams.List) == 0 {
+		return ``
+	}
+	l := []string{}
+	for _, f := range astTypeSpec.TypeParams.List {
+		names := []string{}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		l = append(l, strings.Join(names, `, `)+` `+gotypes.ExprString(f.Type))
+	}
+	return `[` + strings.Join(l, `, `) + `]`
+}
+
 func parseTypeType(astTypeSpec *ast.TypeSpec) int {
 	switch astTypeSpec.Type.(type) {
 	case *ast.InterfaceType:
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"path/filepath"
+
 	"github.com/dexterp/ifaces/internal/resources/match"
 )
 
@@ -22,8 +24,10 @@ func (q *Query) GetIfaceMethods(iface string) (methods []*Method) {
 	return
 }
 
-// GetRecvByLine
+// GetRecvByLine returns the receiver at or after line. Files are compared by
+// base name.
 func (q *Query) GetRecvByLine(file string, line int) (recv *Method) {
+	file = filepath.Base(file)
 	end := q.NextComment(file, line)
 	for _, m := range q.Parser.ReceiverMethods {
 		if m.File == file && end == 0 && m.Line >= line {
@@ -57,8 +61,10 @@ func (q *Query) GetRecvsByType(typ string) (recvs []*Method) {
 }
 
 // GetTypeByLine GetTypeByLine returns the type at or after line. returns nil if the end of
-// file is reached or it encounters a iface generator comment.
+// file is reached or it encounters a iface generator comment. Files are compared
+// by base name.
 func (q Query) GetTypeByLine(file string, line int) *Type {
+	file = filepath.Base(file)
 	end := q.NextComment(file, line)
 	for _, t := range q.Parser.Types {
 		if file == t.File && end == 0 && t.Line >= line {
@@ -104,7 +110,7 @@ func (q *Query) GetTypesByType(typ int) (ts []Type) {
 // line number. Returns 0 if not found.
 func (q Query) NextComment(file string, line int) (end int) {
 	for _, c := range q.Parser.Comments {
-		if c.File == file && c.Line > line {
+		if filepath.Base(c.File) == filepath.Base(file) && c.Line > line && (end == 0 || c.Line < end) {
 			end = c.Line
 		}
 	}
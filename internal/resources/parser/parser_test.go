@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/dexterp/ifaces/internal/resources/srcio"
 	"github.com/dexterp/ifaces/internal/resources/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,6 +94,26 @@ func TestParser_GetIfaceMethods(t *testing.T) {
 	assert.Equal(t, `Warnf(format string, a ...any)`, methods[1].Signature())
 }
 
+func TestParser_Embeds(t *testing.T) {
+	src := `package mypkg
+
+type ReadCloser interface {
+	io.Reader
+	Closer
+	Close() error
+}
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	typ := NewQuery(p).GetTypeByName(`ReadCloser`)
+	if assert.NotNil(t, typ) {
+		assert.Equal(t, []string{`io.Reader`, `Closer`}, typ.Embeds)
+	}
+	assert.Len(t, NewQuery(p).GetIfaceMethods(`ReadCloser`), 1)
+}
+
 func TestParser_GetRecvByLine(t *testing.T) {
 	src := `package mypkg
 
@@ -147,6 +168,27 @@ func TestParser_GetTypeByLine(t *testing.T) {
 	assert.Equal(t, types.STRUCT, typ.Type, `wrong type`)
 }
 
+func TestParser_NextComment(t *testing.T) {
+	src := `package mypkg
+
+//` + `go:generate ifaces type -o a_iface.go -i A
+
+//` + `go:generate ifaces type -o b_iface.go -i B
+
+//` + `go:generate ifaces type -o c_iface.go -i C
+type C struct{}
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	q := NewQuery(p)
+	assert.Equal(t, 5, q.NextComment(`src.go`, 3))
+	assert.Equal(t, 7, q.NextComment(`src.go`, 5))
+	assert.Equal(t, 0, q.NextComment(`src.go`, 7))
+	assert.Nil(t, q.GetTypeByLine(`src.go`, 3))
+}
+
 func TestParser_GetTypeRecvs(t *testing.T) {
 	p, err := Parse(`src.go`, []byte(varSrc()), 0)
 	if err != nil {
@@ -157,6 +199,7 @@ func TestParser_GetTypeRecvs(t *testing.T) {
 		assert.Regexp(t, `Parse\(.*\)`, recvs[0].Signature())
 		assert.Regexp(t, `Count\(\)`, recvs[1].Signature())
 		assert.Regexp(t, `Add\(.*\)`, recvs[2].Signature())
+		assert.True(t, recvs[0].Pointer)
 	}
 }
 
@@ -182,3 +225,64 @@ func TestParser_parseGeneratorCmts(t *testing.T) {
 		assert.Equal(t, 9, (p.Comments)[1].Line)
 	}
 }
+
+func TestParser_Directives(t *testing.T) {
+	src := `package mypkg
+
+// Store store
+//ifaces:ignore
+type Store struct {
+}
+
+// String string
+//
+//ifaces:ignore StoreIface  OtherIface
+func (s *Store) String() string {
+	return ""
+}
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	typ := NewQuery(p).GetTypeByName(`Store`)
+	if assert.NotNil(t, typ) {
+		assert.Equal(t, `Store store`, typ.Doc)
+		assert.True(t, typ.Directives.Ignore(`Any`))
+	}
+	recvs := NewQuery(p).GetRecvsByType(`Store`)
+	if assert.Len(t, recvs, 1) {
+		assert.Equal(t, `String string`, recvs[0].Doc)
+		assert.Equal(t, Directives{{Name: `ignore`, Args: []string{`StoreIface`, `OtherIface`}, Text: `StoreIface  OtherIface`, Line: 10}}, recvs[0].Directives)
+		assert.True(t, recvs[0].Directives.Ignore(`OtherIface`))
+		assert.False(t, recvs[0].Directives.Ignore(`Store`))
+	}
+}
+
+func TestParser_Builds(t *testing.T) {
+	src := `// Copyright
+
+//go:build linux && (amd64 || arm64)
+
+package mypkg
+
+//go:build ignored
+`
+	p, err := ParseFiles([]srcio.Source{
+		{File: `/src/store_linux.go`, Src: src},
+		{File: `/src/store.go`, Src: "package mypkg\n"},
+		{File: `/src/store_windows_amd64.go`, Src: "package mypkg\n"},
+		{File: `/src/store_arm64_test.go`, Src: "//go:build !cgo\n\npackage mypkg\n"},
+		{File: `/src/linux.go`, Src: "package mypkg\n"},
+		{File: `/src/store_other.go`, Src: "package mypkg\n"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := map[string]string{
+		`store_linux.go`:         `linux && (amd64 || arm64)`,
+		`store_windows_amd64.go`: `windows && amd64`,
+		`store_arm64_test.go`:    `arm64 && !cgo`,
+	}
+	assert.Equal(t, expected, p.Builds)
+}
@@ -26,38 +26,51 @@ type Prefix struct {
 
 // Type type declaration
 type Type struct {
-	Doc  string
-	File string // File originating file
-	Line int
-	Name string
-	Type int
+	Directives Directives // Directives ifaces annotations in the type document
+	Doc        string
+	Embeds     []string // Embeds embedded types of an interface or embedded fields of a struct, E.G. "*Store"
+	File       string   // File originating file
+	Line       int
+	Name       string
+	Type       int
+	TypeParams string // TypeParams type parameter list of a generic type, e.g. "[T any]"
 }
 
 // Method receiver or interface method
 type Method struct {
-	Doc      string
-	File     string // File originating file
-	fn       *Func
-	Line     int
-	Name     string
-	Prefixes []string
-	Pkg      string
-	TypeName string
-	HasType  typecheck.HasType
+	Directives Directives // Directives ifaces annotations in the method document
+	Doc        string
+	File       string // File originating file
+	fn         *Func
+	Line       int
+	Name       string
+	Pointer    bool // Pointer true if the receiver is a pointer
+	Prefixes   []string
+	Receiver   bool // Receiver true for receiver methods, false for interface methods
+	Pkg        string
+	TypeName   string
+	HasType    typecheck.HasType
 }
 
 // Signature return the function signature
 func (i Method) Signature() string {
-	s := i.fn.Package(i.Pkg).String()
-	return s
+	return i.fn.Signature(i.Pkg)
+}
+
+// Params return the function parameters
+func (i Method) Params() []Param {
+	return i.fn.Params(i.Pkg)
+}
+
+// Results return the function results
+func (i Method) Results() []Param {
+	return i.fn.Results(i.Pkg)
 }
 
-// NeedsImport
+// NeedsImport returns true if the signature refers to types declared in the
+// parsed source and Pkg is set, so the source package must be imported.
 func (i Method) NeedsImport() bool {
-	//f := parsefunc.ToFuncDecl(i.Sig, i.Pkg, i.HasType)
-	//return f.NeedsImport
-	// TODO - Implement or refactor this method
-	return false
+	return i.Pkg != `` && i.fn.Signature(i.Pkg) != i.fn.Signature(``)
 }
 
 // ImportPrefixes
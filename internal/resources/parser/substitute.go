@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"strings"
+)
+
+var (
+	ErrSubstitute = errors.New(`invalid type substitution`)
+)
+
+// Substitution replaces type expressions in method signatures
+type Substitution struct {
+	exprs map[string]typeExpr
+}
+
+// NewSubstitution creates a substitution from subs. The keys of subs are type
+// expressions as written in the parsed source, E.G. "*Tx" or "time.Time", and
+// the values are the type expressions written in their place. Types of the
+// replacements declared in the parsed source, E.G. "Item=Entity", are qualified
+// like the types of the source.
+func NewSubstitution(subs map[string]string) (*Substitution, error) {
+	s := &Substitution{
+		exprs: map[string]typeExpr{},
+	}
+	for from, to := range subs {
+		e, err := parser.ParseExpr(to)
+		if err != nil {
+			return nil, fmt.Errorf(`%w %s=%s: %s`, ErrSubstitute, from, to, err.Error())
+		}
+		t := (&funcparse{prefixes: map[string]any{}}).parseExpr(e)
+		if t == nil {
+			return nil, fmt.Errorf(`%w %s=%s: unsupported type expression`, ErrSubstitute, from, to)
+		}
+		s.exprs[strings.ReplaceAll(from, ` `, ``)] = t
+	}
+	return s, nil
+}
+
+// Apply returns a copy of m with the type expressions replaced
+func (s *Substitution) Apply(m *Method) *Method {
+	fn := &Func{
+		Prefixes: map[string]any{},
+		hasType:  m.fn.hasType,
+		pkg:      m.fn.pkg,
+		name:     m.fn.name,
+		params:   s.params(m.fn.params),
+		results:  s.params(m.fn.results),
+	}
+	for _, p := range append(append([]*param{}, fn.params...), fn.results...) {
+		addPrefixes(p.typ, fn.Prefixes)
+	}
+	c := *m
+	c.fn = fn
+	c.Prefixes = parseSigPrefixes(fn)
+	return &c
+}
+
+// ResultTypes returns the names of the types declared in the parsed source
+// which are referred to by the results.
+func (i Method) ResultTypes() (names []string) {
+	seen := map[string]bool{}
+	for _, p := range i.fn.results {
+		walkTypes(p.typ, func(t *typ) {
+			if t.pkg == `` && i.fn.hasType != nil && i.fn.hasType(t.name) && !seen[t.name] {
+				seen[t.name] = true
+				names = append(names, t.name)
+			}
+		})
+	}
+	return
+}
+
+// walkTypes calls fn for each named type in a type expression
+func walkTypes(e typeExpr, fn func(t *typ)) {
+	switch t := e.(type) {
+	case *typ:
+		fn(t)
+	case *typChan:
+		walkTypes(t.typ, fn)
+	case *typMap:
+		walkTypes(t.key, fn)
+		walkTypes(t.typ, fn)
+	case *typSlice:
+		walkTypes(t.typ, fn)
+	case *typFunc:
+		for _, p := range append(append([]*param{}, t.params...), t.results...) {
+			walkTypes(p.typ, fn)
+		}
+	}
+}
+
+func (s *Substitution) params(params []*param) (out []*param) {
+	for _, p := range params {
+		out = append(out, &param{
+			name: p.name,
+			typ:  s.expr(p.typ),
+		})
+	}
+	return
+}
+
+// expr returns a copy of e with the substituted expressions replaced
+func (s *Substitution) expr(e typeExpr) typeExpr {
+	if e == nil {
+		return nil
+	}
+	ellipsis := ellipsisOf(e)
+	key := strings.TrimPrefix(e.string(unqualified), `...`)
+	if to, ok := s.exprs[strings.ReplaceAll(key, ` `, ``)]; ok {
+		return &typSub{ellipsis: ellipsis, typ: to}
+	}
+	switch t := e.(type) {
+	case *typChan:
+		c := *t
+		c.typ = s.expr(t.typ)
+		return &c
+	case *typMap:
+		c := *t
+		c.key = s.expr(t.key)
+		c.typ = s.expr(t.typ)
+		return &c
+	case *typSlice:
+		c := *t
+		c.typ = s.expr(t.typ)
+		return &c
+	case *typFunc:
+		c := *t
+		c.params = s.params(t.params)
+		c.results = s.params(t.results)
+		return &c
+	}
+	return e
+}
+
+// typSub type expression replaced by a Substitution
+type typSub struct {
+	ellipsis string   // ellipsis expression, `...` if set or empty
+	typ      typeExpr // typ replacement
+}
+
+func (t typSub) string(q qualifier) string {
+	return t.ellipsis + t.typ.string(q)
+}
+
+// unqualified returns the name of a type as written in the parsed source
+func unqualified(sel, name string) string {
+	if sel != `` {
+		return sel + `.` + name
+	}
+	return name
+}
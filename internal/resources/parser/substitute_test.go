@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstitute(t *testing.T) {
+	src := `package mypkg
+
+import "time"
+
+type Tx struct{}
+
+type DB struct{}
+
+func (d *DB) Begin(at time.Time, opts ...*Tx) (*Tx, error) { return nil, nil }
+
+func (d *DB) Txs() ([]*Tx, map[string]Tx, func(*Tx) error) { return nil, nil, nil }
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	recvs := NewQuery(p).GetRecvsByType(`DB`)
+	subs := map[string]string{`*Tx`: `TxIface`, `Tx`: `TxIface`, `time.Time`: `civil.DateTime`}
+
+	s, err := NewSubstitution(subs)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	begin := s.Apply(recvs[0])
+	begin.Pkg = `mypkg`
+	assert.Equal(t, `Begin(at civil.DateTime, opts ...TxIface) (TxIface, error)`, begin.Signature())
+	assert.Equal(t, []string{`civil`}, begin.Prefixes)
+	assert.False(t, begin.NeedsImport())
+
+	txs := s.Apply(recvs[1])
+	assert.Equal(t, `Txs() ([]TxIface, map[string]TxIface, func(TxIface) error)`, txs.Signature())
+	assert.Equal(t, `Txs() ([]*Tx, map[string]Tx, func(*Tx) error)`, recvs[1].Signature())
+
+	_, err = NewSubstitution(map[string]string{`*Tx`: `[`})
+	assert.ErrorIs(t, err, ErrSubstitute)
+}
+
+func TestMethod_ResultTypes(t *testing.T) {
+	src := `package mypkg
+
+type Tx struct{}
+
+type Row struct{}
+
+type DB struct{}
+
+func (d *DB) Begin(r *Row) (*Tx, []Row, *Tx, error) { return nil, nil, nil, nil }
+`
+	p, err := Parse(`src.go`, []byte(src), 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	recvs := NewQuery(p).GetRecvsByType(`DB`)
+	assert.Equal(t, []string{`Tx`, `Row`}, recvs[0].ResultTypes())
+}
@@ -61,3 +61,57 @@ func PathToImport(path string) (imp string, err error) {
 
 	return modinfo.GetImport(``, nil, path)
 }
+
+// PackageDirs expands package patterns to the directories which contain Go
+// source files. A pattern ending in "/..." includes all sub directories except
+// testdata, vendor and directories beginning with "." or "_". The current
+// directory is used if no patterns are given.
+func PackageDirs(patterns ...string) (dirs []string, err error) {
+	if len(patterns) == 0 {
+		patterns = []string{`.`}
+	}
+	added := map[string]any{}
+	add := func(dir string) {
+		if _, ok := added[dir]; ok {
+			return
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, `*.go`))
+		if len(matches) > 0 {
+			added[dir] = struct{}{}
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, pattern := range patterns {
+		root := filepath.ToSlash(pattern)
+		recursive := root == `...` || strings.HasSuffix(root, `/...`)
+		root = filepath.Clean(strings.TrimSuffix(strings.TrimSuffix(root, `...`), `/`))
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf(`not a directory: %s`, root)
+		}
+		if !recursive {
+			add(root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (name == `testdata` || name == `vendor` || strings.HasPrefix(name, `.`) || strings.HasPrefix(name, `_`)) {
+				return filepath.SkipDir
+			}
+			add(path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
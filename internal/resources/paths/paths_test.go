@@ -1,6 +1,7 @@
 package paths
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -41,3 +42,27 @@ func TestPathToImport_ModDir(t *testing.T) {
 	assert.Equal(t, `github.com/stretchr/testify/assert`, i)
 
 }
+
+func TestPackageDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{`a`, `a/b`, `a/testdata`, `a/.hidden`, `a/empty`} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0755)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		if dir != `a/empty` {
+			err = os.WriteFile(filepath.Join(root, dir, `x.go`), []byte(`package x`), 0600)
+			assert.NoError(t, err)
+		}
+	}
+	dirs, err := PackageDirs(filepath.Join(root, `a`) + `/...`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, `a`), filepath.Join(root, `a`, `b`)}, dirs)
+
+	dirs, err = PackageDirs(filepath.Join(root, `a`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, `a`)}, dirs)
+
+	_, err = PackageDirs(filepath.Join(root, `missing`))
+	assert.Error(t, err)
+}
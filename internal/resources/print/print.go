@@ -64,6 +64,15 @@ func (p *Print) Level(lvl Level) {
 	p.lvl = lvl
 }
 
+// Debugf print debug message
+func (p Print) Debugf(format string, a ...any) {
+	if DEBUG >= p.lvl {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(p.stderr, format, a...)
+	}
+}
+
 // Errorf print error
 func (p Print) Errorf(format string, a ...any) {
 	if ERROR >= p.lvl {
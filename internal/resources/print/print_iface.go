@@ -6,6 +6,8 @@ package print
 type PrintIface interface {
 	// Level set level
 	Level(lvl Level)
+	// Debugf print debug message
+	Debugf(format string, a ...any)
 	// Errorf print error
 	Errorf(format string, a ...any)
 	// HasErrorf same as Errorf function but only prints if a holds an error value.
@@ -19,6 +19,17 @@ var (
 	})
 )
 
+func TestPrint_Debugf(t *testing.T) {
+	bufStderr.Reset()
+	print.Level(DEBUG)
+	print.Debugf("this is a %s\n", `trace`)
+	assert.Equal(t, "this is a trace\n", bufStderr.String())
+	print.Level(DEBUG + 1)
+	bufStderr.Reset()
+	print.Debugf("this is a %s\n", `trace`)
+	assert.Equal(t, "", bufStderr.String())
+}
+
 func TestPrint_Errorf(t *testing.T) {
 	bufStderr.Reset()
 	print.Level(ERROR)
@@ -2,7 +2,12 @@ package srcio
 
 import (
 	"bytes"
+	"go/build"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 type Source struct {
@@ -17,3 +22,47 @@ type Destination struct {
 	Current *bytes.Buffer
 	Output  io.Writer
 }
+
+// ReadDir returns the Go source files in dir, excluding test files, with the
+// source loaded into Src. Files excluded by their build constraints are
+// skipped, see Match.
+func ReadDir(dir string) (srcs []Source, err error) {
+	return readDir(dir, false)
+}
+
+// ReadTestDir returns the Go test files in dir with the source loaded into
+// Src. Files excluded by their build constraints are skipped, see Match.
+func ReadTestDir(dir string) (srcs []Source, err error) {
+	return readDir(dir, true)
+}
+
+func readDir(dir string, tests bool) (srcs []Source, err error) {
+	matches, err := filepath.Glob(filepath.Join(dir, `*.go`))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	for _, m := range matches {
+		if strings.HasSuffix(m, `_test.go`) != tests || !Match(m) {
+			continue
+		}
+		b, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, Source{
+			File: m,
+			Src:  b,
+		})
+	}
+	return srcs, nil
+}
+
+// Match reports whether the Go source file is part of the build of the current
+// GOOS, GOARCH and build tags, by its "//go:build" line and its _GOOS and
+// _GOARCH file name suffixes. A file which can not be read matches, the read
+// error is left to the caller.
+func Match(file string) bool {
+	ok, err := build.Default.MatchFile(filepath.Dir(file), filepath.Base(file))
+	return ok || err != nil
+}
@@ -1,6 +1,8 @@
 package stringx
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -90,6 +92,66 @@ func NotEmpty(s ...string) (o []string) {
 	return
 }
 
+// SplitArgs split a command line into arguments using the same rules as go
+// generate. Arguments are separated by white space and double quoted arguments
+// are Go string literals. An error is returned for an invalid quoted string.
+func SplitArgs(line string) (args []string, err error) {
+	line = strings.TrimSpace(line)
+	for line != `` {
+		if line[0] == '"' {
+			end := 1
+			for ; end < len(line); end++ {
+				if line[end] == '\\' {
+					end++
+				} else if line[end] == '"' {
+					break
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf(`unterminated quoted string: %s`, line)
+			}
+			arg, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf(`invalid quoted string %s: %w`, line[:end+1], err)
+			}
+			args = append(args, arg)
+			line = strings.TrimLeftFunc(line[end+1:], unicode.IsSpace)
+			continue
+		}
+		end := strings.IndexFunc(line, unicode.IsSpace)
+		if end < 0 {
+			end = len(line)
+		}
+		args = append(args, line[:end])
+		line = strings.TrimLeftFunc(line[end:], unicode.IsSpace)
+	}
+	return args, nil
+}
+
+// SplitList split a comma separated list. Items are trimmed of white space and
+// empty items are omitted.
+func SplitList(list string) (o []string) {
+	for _, x := range strings.Split(list, `,`) {
+		if x = strings.TrimSpace(x); x != `` {
+			o = append(o, x)
+		}
+	}
+	return
+}
+
+// Unique returns the strings in l without duplicates in the order they first
+// appear
+func Unique(l []string) (o []string) {
+	seen := map[string]bool{}
+	for _, x := range l {
+		if !seen[x] {
+			seen[x] = true
+			o = append(o, x)
+		}
+	}
+	return
+}
+
 // StripVersion strip version from paths prefixed with GOPATH
 func StripVersion(path string) string {
 	var (
@@ -109,3 +171,61 @@ func StripVersion(path string) string {
 	}
 	return path
 }
+
+// SnakeCase convert an identifier such as MyHTTPServer to snake case, E.G.
+// my_http_server.
+func SnakeCase(ident string) string {
+	r := []rune(ident)
+	out := []rune{}
+	for i, c := range r {
+		if c == '-' || c == ' ' {
+			c = '_'
+		}
+		if unicode.IsUpper(c) && i > 0 {
+			prev := r[i-1]
+			next := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToLower(c))
+	}
+	return string(out)
+}
+
+// PascalCase convert a snake case, kebab case or camel case identifier to
+// pascal case, E.G. my_struct becomes MyStruct.
+func PascalCase(ident string) string {
+	out := []rune{}
+	upper := true
+	for _, c := range ident {
+		if c == '_' || c == '-' || c == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// CamelCase convert a snake case, kebab case or pascal case identifier to
+// camel case, E.G. my_struct becomes myStruct.
+func CamelCase(ident string) string {
+	r := []rune(PascalCase(ident))
+	for i := range r {
+		if i > 0 && unicode.IsUpper(r[i]) && (i+1 == len(r) || unicode.IsUpper(r[i+1])) {
+			r[i] = unicode.ToLower(r[i])
+			continue
+		}
+		if i == 0 {
+			r[i] = unicode.ToLower(r[i])
+			continue
+		}
+		break
+	}
+	return string(r)
+}
@@ -28,7 +28,43 @@ func TestNotEmpty(t *testing.T) {
 	assert.Equal(t, []string{`a`, `b`}, NotEmpty(``, `a`, ``, `b`))
 }
 
+func TestSplitArgs(t *testing.T) {
+	args, err := SplitArgs(` type -i  Iface --tdoc "a \"quoted\" doc" -o x.go`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`type`, `-i`, `Iface`, `--tdoc`, `a "quoted" doc`, `-o`, `x.go`}, args)
+	_, err = SplitArgs(`-i "Iface`)
+	assert.Error(t, err)
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{`Get*`, `List`}, SplitList(` Get*, ,List`))
+	assert.Nil(t, SplitList(``))
+}
+
 func TestStripVersion(t *testing.T) {
 	assert.Equal(t, `github.com/author/pkg-go`, StripVersion(`github.com/author/pkg-go@v1.0.0`))
 	assert.Equal(t, `github.com/author/pkg-go/pkg`, StripVersion(`github.com/author/pkg-go@v1.0.0/pkg`))
 }
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, `my_struct`, SnakeCase(`MyStruct`))
+	assert.Equal(t, `my_http_server`, SnakeCase(`MyHTTPServer`))
+	assert.Equal(t, `server2_go`, SnakeCase(`Server2Go`))
+	assert.Equal(t, `my_struct`, SnakeCase(`my-struct`))
+}
+
+func TestPascalCase(t *testing.T) {
+	assert.Equal(t, `MyStruct`, PascalCase(`my_struct`))
+	assert.Equal(t, `MyStruct`, PascalCase(`myStruct`))
+}
+
+func TestCamelCase(t *testing.T) {
+	assert.Equal(t, `myStruct`, CamelCase(`MyStruct`))
+	assert.Equal(t, `myStruct`, CamelCase(`my_struct`))
+	assert.Equal(t, `httpServer`, CamelCase(`HTTPServer`))
+}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, []string{`a`, `b`, `c`}, Unique([]string{`a`, `b`, `a`, `c`, `b`}))
+	assert.Nil(t, Unique(nil))
+}
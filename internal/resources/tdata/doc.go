@@ -0,0 +1,66 @@
+package tdata
+
+import (
+	"go/doc/comment"
+	"strings"
+
+	"github.com/mitchellh/go-wordwrap"
+)
+
+// DefaultWidth default wrap width of documents
+const DefaultWidth = 76
+
+const deprecatedPrefix = `Deprecated:`
+
+// FormatDoc renders a document as Go comment lines. The document is parsed as a
+// Go doc comment, lists, code blocks, headings and links keep their structure
+// and paragraphs are wrapped at width. A width of zero or less keeps the line
+// breaks of the paragraphs.
+func FormatDoc(doc string, width int) string {
+	if strings.TrimSpace(doc) == `` {
+		return ``
+	}
+	var p comment.Parser
+	d := p.Parse(doc)
+	if width > 0 {
+		for _, b := range d.Content {
+			if para, ok := b.(*comment.Paragraph); ok {
+				text := wordwrap.WrapString(strings.Join(strings.Fields(paragraphText(para)), ` `), uint(width))
+				para.Text = []comment.Text{comment.Plain(text)}
+			}
+		}
+	}
+	pr := &comment.Printer{}
+	buf := &strings.Builder{}
+	for _, l := range strings.Split(strings.TrimSuffix(string(pr.Comment(d)), "\n"), "\n") {
+		switch {
+		case l == ``:
+			buf.WriteString("//\n")
+		case strings.HasPrefix(l, "\t"):
+			buf.WriteString("//" + l + "\n")
+		default:
+			buf.WriteString("// " + l + "\n")
+		}
+	}
+	return buf.String()
+}
+
+// Deprecated returns the "Deprecated:" paragraph of a document or an empty
+// string.
+func Deprecated(doc string) string {
+	var p comment.Parser
+	for _, b := range p.Parse(doc).Content {
+		if para, ok := b.(*comment.Paragraph); ok {
+			if text := paragraphText(para); strings.HasPrefix(text, deprecatedPrefix) {
+				return text
+			}
+		}
+	}
+	return ``
+}
+
+// paragraphText returns the source text of a paragraph
+func paragraphText(para *comment.Paragraph) string {
+	pr := &comment.Printer{}
+	return strings.TrimSuffix(string(pr.Comment(&comment.Doc{Content: []comment.Block{para}})), "\n")
+}
@@ -0,0 +1,57 @@
+package tdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDoc(t *testing.T) {
+	doc := `Store stores items in a database
+and caches them.
+
+Options:
+  - Cache caches items
+  - TTL expires items
+
+Example:
+
+	s := NewStore()
+
+See [Cache] and https://example.com.
+
+Deprecated: use [Repo] instead.
+`
+	expected := `// Store stores items in a
+// database and caches them.
+//
+// Options:
+//   - Cache caches items
+//   - TTL expires items
+//
+// Example:
+//
+//	s := NewStore()
+//
+// See [Cache] and
+// https://example.com.
+//
+// Deprecated: use [Repo]
+// instead.
+`
+	assert.Equal(t, expected, FormatDoc(doc, 30))
+	assert.Equal(t, "// Store stores items in a database\n// and caches them.\n", FormatDoc("Store stores items in a database\nand caches them.", -1))
+	assert.Equal(t, ``, FormatDoc(" \n", 30))
+}
+
+func TestDeprecated(t *testing.T) {
+	assert.Equal(t, "Deprecated: use Repo\ninstead.", Deprecated("Store stores.\n\nDeprecated: use Repo\ninstead.\n"))
+	assert.Equal(t, ``, Deprecated("Store stores.\n"))
+}
+
+func TestMethod_Doc(t *testing.T) {
+	m := NewMethod(`Get`, `Get() error`, "Get gets an item.\n\nDeprecated: use Find.", false, 76)
+	assert.Equal(t, "// Get gets an item.\n\t//\n\t// Deprecated: use Find.\n\t", m.Doc())
+	m = NewMethod(`Get`, `Get() error`, "Get gets an item.", true, 76)
+	assert.Equal(t, ``, m.Doc())
+}
@@ -1,33 +1,38 @@
-// tdata Template data
+// tdata Template data. TData is the data passed to the output template,
+// see the README for a description of the fields available to user supplied
+// templates.
 package tdata
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
-	"regexp"
 	"strings"
-
-	"github.com/mitchellh/go-wordwrap"
 )
 
 var (
 	ErrorDuplicateInterface = errors.New(`can not add duplicate interface`)
 	ErrorDuplicateMethod    = errors.New(`can not add duplicate method`)
-	reFirstWord             = regexp.MustCompile(`^[^\s]+`)
 )
 
 type TData struct {
+	Build   string // Build build constraint expression, empty if the file has no constraint
 	Comment string // Comment comment at the top of the file
+	Header  string // Header comment lines added after the top comment, E.G. a license
 	NoFDoc  bool   // NoFDoc omit copying function documentation
 	NoTDoc  bool   // NoTDoc omit copying type documentation
 	Pkg     string // Pkg package name
 	Post    string // Post postfix to interface name
 	Pre     string // Pre prefix to interface name
 	Ifaces  []*Interface
+	Imports []Import // Imports imports required by the interfaces
 	unique  map[string]*Interface
 }
 
+// Import package import
+type Import struct {
+	Name string // Name import name, empty unless the import is renamed
+	Path string // Path import path
+}
+
 // Add add an interface
 func (t *TData) Add(iface *Interface) error {
 	if t.unique == nil {
@@ -49,9 +54,24 @@ func (t TData) Get(iface string) *Interface {
 }
 
 type Interface struct {
-	Type    *Type     // TypeDecl type declaration
-	Methods []*Method // Methods list of methods
-	unique  map[string]*Method
+	Type       *Type     // TypeDecl type declaration
+	TypeParams string    // TypeParams type parameter list of a generic interface, e.g. "[T any]"
+	Embeds     []string  // Embeds embedded interfaces
+	Methods    []*Method // Methods list of methods
+	Source     string    // Source name of the type the interface is generated from
+	File       string    // File source file of the type
+	Line       int       // Line line number of the type in the source file
+	unique     map[string]*Method
+}
+
+// Embed add an embedded interface
+func (i *Interface) Embed(name string) {
+	for _, e := range i.Embeds {
+		if e == name {
+			return
+		}
+	}
+	i.Embeds = append(i.Embeds, name)
 }
 
 // Add add method to the interface
@@ -67,11 +87,19 @@ func (i *Interface) Add(method *Method) error {
 	return nil
 }
 
-func NewType(name, doc string, noTypeDoc bool) *Type {
+// Method returns the method named name or nil if there is none
+func (i *Interface) Method(name string) *Method {
+	return i.unique[name]
+}
+
+// NewType creates a type declaration. The document is wrapped at width, see
+// FormatDoc.
+func NewType(name, doc string, noTypeDoc bool, width int) *Type {
 	return &Type{
 		name:      name,
 		doc:       doc,
 		noTypeDoc: noTypeDoc,
+		width:     width,
 	}
 }
 
@@ -79,67 +107,87 @@ type Type struct {
 	noTypeDoc bool
 	name      string
 	doc       string
+	width     int
 }
 
 func (t Type) Doc() string {
 	if t.noTypeDoc {
 		return ``
 	}
-	doc := reFirstWord.ReplaceAllString(t.doc, t.Name())
-	return wrapDoc(doc, false)
+	return FormatDoc(t.doc, t.width)
+}
+
+// DocText returns the document text without comment markers, empty if the
+// document is omitted
+func (t Type) DocText() string {
+	if t.noTypeDoc {
+		return ``
+	}
+	return t.doc
 }
 
 func (t Type) Name() string {
 	return t.name
 }
 
-func NewMethod(name, signature, doc string, nofdoc bool) *Method {
+// NewMethod creates an interface method. The document is wrapped at width,
+// see FormatDoc.
+func NewMethod(name, signature, doc string, nofdoc bool, width int) *Method {
 	return &Method{
 		name:      name,
 		doc:       doc,
 		signature: signature,
 		noFuncDoc: nofdoc,
+		width:     width,
 	}
 }
 
 type Method struct {
+	Params    []Param // Params function parameters
+	Results   []Param // Results function results
+	Source    string  // Source name of the type the method belongs to
+	Recv      string  // Recv receiver kind, "pointer", "value" or empty for interface methods
+	File      string  // File source file of the method
+	Line      int     // Line line number of the method in the source file
 	noFuncDoc bool
 	name      string
 	doc       string
 	signature string
+	width     int
+}
+
+// Param function parameter or result
+type Param struct {
+	Name     string // Name parameter name, empty if the parameter is unnamed
+	Type     string // Type type expression, the element type if variadic
+	Variadic bool   // Variadic true if the parameter is variadic
+	Pkg      string // Pkg package of a named type, empty for builtin and composite types
+}
+
+func (r Method) Name() string {
+	return r.name
 }
 
 func (r Method) Doc() string {
 	if r.noFuncDoc {
 		return ``
 	}
-	doc := wrapDoc(r.doc, true)
+	doc := FormatDoc(r.doc, r.width)
 	if len(doc) > 0 {
-		return doc + "\t"
+		return strings.ReplaceAll(doc, "\n", "\n\t")
 	}
 	return ``
 }
 
-func (r Method) Signature() string {
-	return r.signature
+// DocText returns the document text without comment markers, empty if the
+// document is omitted
+func (r Method) DocText() string {
+	if r.noFuncDoc {
+		return ``
+	}
+	return r.doc
 }
 
-func wrapDoc(doc string, indent bool) string {
-	doc = strings.Replace(doc, "\n", " ", -1)
-	doc = wordwrap.WrapString(doc, 76)
-	scanner := bufio.NewScanner(strings.NewReader(doc))
-	first := true
-	buf := bytes.Buffer{}
-	for scanner.Scan() {
-		if first {
-			buf.WriteString("// " + scanner.Text() + "\n")
-			first = false
-		} else {
-			if indent {
-				buf.WriteString("\t")
-			}
-			buf.WriteString("// " + scanner.Text() + "\n")
-		}
-	}
-	return buf.String()
+func (r Method) Signature() string {
+	return r.signature
 }
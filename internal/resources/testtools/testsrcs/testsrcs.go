@@ -0,0 +1,26 @@
+package testsrcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Write writes the sources, keyed by slash separated path, to a temporary
+// directory and returns the directory. The test fails if a file can not be
+// written.
+func Write(t *testing.T, srcs map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for file, src := range srcs {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(src), 0600)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
@@ -0,0 +1,43 @@
+// tmplfuncs functions available to ifaces templates
+package tmplfuncs
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+)
+
+// FuncMap returns the functions available to output and file name templates.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// Case conversion
+		`camel`:  stringx.CamelCase,
+		`lower`:  strings.ToLower,
+		`pascal`: stringx.PascalCase,
+		`snake`:  stringx.SnakeCase,
+		`upper`:  strings.ToUpper,
+
+		// String helpers
+		`contains`:   strings.Contains,
+		`hasPrefix`:  strings.HasPrefix,
+		`hasSuffix`:  strings.HasSuffix,
+		`join`:       strings.Join,
+		`replace`:    strings.ReplaceAll,
+		`split`:      strings.Split,
+		`trimPrefix`: strings.TrimPrefix,
+		`trimSpace`:  strings.TrimSpace,
+		`trimSuffix`: strings.TrimSuffix,
+
+		// Identifier and package helpers
+		`exIdent`:      stringx.ExIdent,
+		`exPkg`:        stringx.ExPkg,
+		`exPkgPath`:    stringx.ExPkgPath,
+		`isIdent`:      stringx.IsIdent,
+		`isPkg`:        stringx.IsPkg,
+		`stripVersion`: stringx.StripVersion,
+
+		// Go types
+		`zero`: Zero,
+	}
+}
@@ -0,0 +1,33 @@
+package tmplfuncs
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuncMap(t *testing.T) {
+	tmpl, err := template.New(`test`).Funcs(FuncMap()).Parse(`{{ .Type | snake }}_iface.go`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	out := &bytes.Buffer{}
+	err = tmpl.Execute(out, struct{ Type string }{Type: `MyStruct`})
+	assert.NoError(t, err)
+	assert.Equal(t, `my_struct_iface.go`, out.String())
+}
+
+func TestZero(t *testing.T) {
+	assert.Equal(t, `false`, Zero(`bool`))
+	assert.Equal(t, `""`, Zero(`string`))
+	assert.Equal(t, `0`, Zero(`int64`))
+	assert.Equal(t, `nil`, Zero(`error`))
+	assert.Equal(t, `nil`, Zero(`*pkg.Item`))
+	assert.Equal(t, `nil`, Zero(`map[string]int`))
+	assert.Equal(t, `nil`, Zero(`<-chan int`))
+	assert.Equal(t, `nil`, Zero(`func() error`))
+	assert.Equal(t, `[4]byte{}`, Zero(`[4]byte`))
+	assert.Equal(t, `*new(time.Time)`, Zero(`time.Time`))
+}
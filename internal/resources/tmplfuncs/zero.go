@@ -0,0 +1,33 @@
+package tmplfuncs
+
+import "strings"
+
+// Zero returns a Go expression for the zero value of the type expression typ.
+// Named types which can not be determined from the expression alone use the
+// form *new(T).
+func Zero(typ string) string {
+	typ = strings.TrimSpace(typ)
+	switch typ {
+	case ``:
+		return ``
+	case `bool`:
+		return `false`
+	case `string`:
+		return `""`
+	case `any`, `error`:
+		return `nil`
+	case `int`, `int8`, `int16`, `int32`, `int64`,
+		`uint`, `uint8`, `uint16`, `uint32`, `uint64`, `uintptr`,
+		`float32`, `float64`, `complex64`, `complex128`, `byte`, `rune`:
+		return `0`
+	}
+	for _, prefix := range []string{`*`, `[]`, `map[`, `chan `, `chan<-`, `<-chan`, `func(`, `interface{`, `interface {`} {
+		if strings.HasPrefix(typ, prefix) {
+			return `nil`
+		}
+	}
+	if strings.HasPrefix(typ, `[`) || strings.HasPrefix(typ, `struct{`) || strings.HasPrefix(typ, `struct {`) {
+		return typ + `{}`
+	}
+	return `*new(` + typ + `)`
+}
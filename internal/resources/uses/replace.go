@@ -0,0 +1,220 @@
+package uses
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// TypeName named type of a package
+type TypeName struct {
+	Dir  string // Dir package directory
+	Path string // Path import path of the package
+	Pkg  string // Pkg package name
+	Name string // Name type name
+}
+
+// String returns the qualified type name, E.G. "log.Logger"
+func (t TypeName) String() string {
+	return t.Pkg + `.` + t.Name
+}
+
+// ReplaceType replaces the references to the type from with the type to and
+// returns the number of references replaced. A type is referenced by its name
+// in the files of its package and qualified by its import in other files. The
+// import of to is added to the files which reference it.
+func (p *Package) ReplaceType(from, to TypeName) (n int) {
+	for file, f := range p.files {
+		own := declares(file, f, from)
+		sels := importNames(f, from.Path)
+		if !own && len(sels) == 0 {
+			continue
+		}
+		qual, imported := ``, true
+		if !declares(file, f, to) {
+			qual, imported = to.Pkg, false
+			for name := range importNames(f, to.Path) {
+				qual, imported = name, true
+			}
+		}
+		var replaced int
+		astutil.Apply(f, func(c *astutil.Cursor) bool {
+			switch v := c.Node().(type) {
+			case *ast.SelectorExpr:
+				if x, ok := v.X.(*ast.Ident); ok && x.Obj == nil && sels[x.Name] && v.Sel.Name == from.Name {
+					c.Replace(typeExpr(qual, to.Name, v.Pos()))
+					replaced++
+					return false
+				}
+			case *ast.Ident:
+				if own && v.Name == from.Name && isTypeRef(f, v, c) {
+					c.Replace(typeExpr(qual, to.Name, v.Pos()))
+					replaced++
+				}
+			}
+			return true
+		}, nil)
+		if replaced == 0 {
+			continue
+		}
+		if !imported {
+			name := to.Pkg
+			if path.Base(to.Path) == to.Pkg {
+				name = ``
+			}
+			astutil.AddNamedImport(p.fset, f, name, to.Path)
+		}
+		p.changed[file] = true
+		n += replaced
+	}
+	return n
+}
+
+// RemoveType removes the declaration of the type name with its comments and
+// returns the file of the declaration. Returns false if the type is not
+// declared in the package.
+func (p *Package) RemoveType(name string) (string, bool) {
+	for file, f := range p.files {
+		if f.Name.Name != p.Name {
+			continue
+		}
+		for i, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for j, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name {
+					continue
+				}
+				start, end := ts.Pos(), ts.End()
+				if ts.Doc != nil {
+					start = ts.Doc.Pos()
+				}
+				if ts.Comment != nil {
+					end = ts.Comment.End()
+				}
+				if len(gd.Specs) == 1 {
+					start, end = gd.Pos(), gd.End()
+					if gd.Doc != nil {
+						start = gd.Doc.Pos()
+					}
+					f.Decls = append(f.Decls[:i], f.Decls[i+1:]...)
+				} else {
+					gd.Specs = append(gd.Specs[:j], gd.Specs[j+1:]...)
+					// join the lines of the spec to the previous line so no
+					// blank line is left in the group
+					tf := p.fset.File(start)
+					first, last := tf.Line(start), tf.Line(end)
+					for n := first; n <= last; n++ {
+						tf.MergeLine(first - 1)
+					}
+				}
+				var comments []*ast.CommentGroup
+				for _, cg := range f.Comments {
+					if cg.Pos() < start || cg.End() > end {
+						comments = append(comments, cg)
+					}
+				}
+				f.Comments = comments
+				p.changed[file] = true
+				return file, true
+			}
+		}
+	}
+	return ``, false
+}
+
+// Imports returns true if a file of the package imports path
+func (p Package) Imports(path string) bool {
+	for _, f := range p.files {
+		if len(importNames(f, path)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Generated returns true if the file has a "Code generated ... DO NOT EDIT."
+// comment before the package clause
+func (p Package) Generated(file string) bool {
+	f, ok := p.files[file]
+	if !ok {
+		return false
+	}
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, `// Code generated `) && strings.HasSuffix(c.Text, `DO NOT EDIT.`) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// empty returns true if the file has no declarations other than imports
+func empty(f *ast.File) bool {
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); !ok || gd.Tok != token.IMPORT {
+			return false
+		}
+	}
+	return true
+}
+
+// declares returns true if the file belongs to the package declaring t. The
+// files of an external test package, E.G. "store_test", do not.
+func declares(file string, f *ast.File, t TypeName) bool {
+	return filepath.Clean(filepath.Dir(file)) == filepath.Clean(t.Dir) && f.Name.Name == t.Pkg
+}
+
+// importNames returns the names the file imports path with
+func importNames(f *ast.File, path string) map[string]bool {
+	names := map[string]bool{}
+	for name, imp := range fileImports(f) {
+		if imp == path && name != `_` && name != `.` {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// isTypeRef returns true if id refers to the package level declaration of its
+// name. Identifiers declaring a name, selected names and composite literal
+// keys are left out.
+func isTypeRef(f *ast.File, id *ast.Ident, c *astutil.Cursor) bool {
+	if id.Obj != nil && (id.Obj != f.Scope.Lookup(id.Name) || id.Obj.Kind != ast.Typ) {
+		return false
+	}
+	switch c.Parent().(type) {
+	case *ast.SelectorExpr:
+		return c.Name() != `Sel`
+	case *ast.KeyValueExpr:
+		return c.Name() != `Key`
+	case *ast.Field:
+		return c.Name() != `Names`
+	case *ast.ValueSpec:
+		return c.Name() != `Names`
+	case *ast.TypeSpec, *ast.FuncDecl, *ast.File, *ast.ImportSpec, *ast.LabeledStmt, *ast.BranchStmt:
+		return c.Name() != `Name` && c.Name() != `Label`
+	}
+	return true
+}
+
+// typeExpr returns the type name qualified by qual, or unqualified if qual is
+// empty, placed at pos
+func typeExpr(qual, name string, pos token.Pos) ast.Expr {
+	id := &ast.Ident{Name: name, NamePos: pos}
+	if qual == `` {
+		return id
+	}
+	return &ast.SelectorExpr{X: &ast.Ident{Name: qual, NamePos: pos}, Sel: id}
+}
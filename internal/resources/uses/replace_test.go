@@ -0,0 +1,128 @@
+package uses
+
+import (
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiSrc = `package api
+
+type (
+	// Logger logs
+	Logger interface {
+		Infof(format string, a ...any)
+	}
+	// Server serves
+	Server struct {
+		Logger Logger
+		log    Logger
+	}
+)
+
+func New(l Logger) *Server {
+	var x Logger = l
+	s := &Server{Logger: x}
+	s.Logger.Infof("new")
+	func() {
+		type Logger int
+		var n Logger
+		_ = n
+	}()
+	return s
+}
+`
+
+var ifaceSrc = `// Code generated by ifaces DO NOT EDIT.
+
+package api
+
+import "context"
+
+// Other is removed
+type Other interface {
+	Get(ctx context.Context)
+}
+`
+
+var apiTestSrc = `package api_test
+
+import (
+	"testing"
+
+	"example.com/app/api"
+)
+
+func TestNew(t *testing.T) {
+	var l api.Logger
+	api.New(l)
+}
+`
+
+var ifaceReplaced = `package api
+
+import "example.com/app/log"
+
+type (
+	// Server serves
+	Server struct {
+		Logger log.Logger
+		log    log.Logger
+	}
+)
+
+func New(l log.Logger) *Server {
+	var x log.Logger = l
+	s := &Server{Logger: x}
+	s.Logger.Infof("new")
+	func() {
+		type Logger int
+		var n Logger
+		_ = n
+	}()
+	return s
+}
+`
+
+func TestPackage_ReplaceType(t *testing.T) {
+	p, err := Parse([]srcio.Source{
+		{File: `api/api.go`, Src: apiSrc},
+		{File: `api/other_iface.go`, Src: ifaceSrc},
+		{File: `api/api_test.go`, Src: apiTestSrc},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	from := TypeName{Dir: `api`, Path: `example.com/app/api`, Pkg: `api`, Name: `Logger`}
+	to := TypeName{Dir: `log`, Path: `example.com/app/log`, Pkg: `log`, Name: `Logger`}
+	assert.Equal(t, `api.Logger`, from.String())
+	assert.Equal(t, 5, p.ReplaceType(from, to))
+	assert.True(t, p.Imports(`example.com/app/api`))
+	assert.False(t, p.Imports(`example.com/app/store`))
+
+	file, ok := p.RemoveType(`Logger`)
+	assert.True(t, ok)
+	assert.Equal(t, `api/api.go`, file)
+	assert.False(t, p.Generated(file))
+	file, ok = p.RemoveType(`Other`)
+	assert.True(t, ok)
+	assert.True(t, p.Generated(file))
+	_, ok = p.RemoveType(`Missing`)
+	assert.False(t, ok)
+
+	files, err := p.Changed()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, files, 3)
+	assert.Equal(t, ifaceReplaced, files[`api/api.go`].String())
+	assert.Nil(t, files[`api/other_iface.go`])
+	assert.Contains(t, files[`api/api_test.go`].String(), `	"example.com/app/api"
+	"example.com/app/log"
+)
+
+func TestNew(t *testing.T) {
+	var l log.Logger
+`)
+}
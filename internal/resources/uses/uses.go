@@ -0,0 +1,570 @@
+// Package uses finds how values are used in function bodies. The analysis is
+// syntactic, identifiers are resolved with the scopes of go/parser and types
+// are not checked.
+package uses
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	gotypes "go/types"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/srcformat"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+)
+
+// Use kinds
+const (
+	Call    = iota // Call method call, E.G. s.Get(id)
+	Select         // Select selector which is not called, a method value or a field
+	Arg            // Arg passed as an argument to a function
+	Assign         // Assign assigned, returned, sent or stored in a composite literal
+	Assert         // Assert type assertion or type switch
+	Compare        // Compare compared with == or !=
+	Other          // Other any other use, E.G. &s or *s
+	Set            // Set the left hand side of an assignment
+)
+
+// Use a use of a value in a function body
+type Use struct {
+	Kind  int           // Kind kind of use
+	Name  string        // Name selected name of a Call or Select, the called function of an Arg, the field of an Assign to a field
+	Index int           // Index argument index of an Arg
+	Pos   diag.Position // Pos position of the use
+}
+
+// Package parsed package
+type Package struct {
+	Name    string    // Name package name
+	Funcs   []*Func   // Funcs function and method declarations with a body
+	Structs []*Struct // Structs struct type declarations
+	fset    *token.FileSet
+	files   map[string]*ast.File
+	changed map[string]bool
+}
+
+// Struct struct type declaration
+type Struct struct {
+	Name    string            // Name type name
+	Fields  []Param           // Fields named fields
+	Imports map[string]string // Imports import paths of the file keyed by the package name
+	Pos     diag.Position     // Pos position of the declaration
+}
+
+// Func function or method declaration
+type Func struct {
+	Name    string            // Name function name
+	Recv    string            // Recv receiver type name, empty for functions
+	Params  []Param           // Params parameters
+	Locals  []Param           // Locals local variables declared with a type, converted or asserted to a type, E.G. "var g Getter", "g := Getter(s)" or "g := v.(Getter)"
+	Results []string          // Results result type expressions
+	Imports map[string]string // Imports import paths of the file keyed by the package name
+	Pos     diag.Position     // Pos position of the declaration
+	decl    *ast.FuncDecl
+	fset    *token.FileSet
+}
+
+// Param function parameter or struct field
+type Param struct {
+	Name  string        // Name parameter name
+	Type  string        // Type type expression, E.G. "*store.Store"
+	Names int           // Names number of names declared with the type, E.G. 2 for "a, b *Store"
+	Pos   diag.Position // Pos position of the name
+	file  string
+	field *ast.Field
+	obj   *ast.Object
+}
+
+// Parse parses the function declarations of the sources
+func Parse(srcs []srcio.Source) (*Package, error) {
+	fset := token.NewFileSet()
+	p := &Package{fset: fset, files: map[string]*ast.File{}, changed: map[string]bool{}}
+	for _, src := range srcs {
+		f, err := parser.ParseFile(fset, src.File, src.Src, parser.ParseComments)
+		if el, ok := err.(scanner.ErrorList); ok {
+			return nil, diag.FromScanner(el)
+		} else if err != nil {
+			return nil, diag.Errorf(diag.CodeError, diag.Position{File: src.File}, `%w`, err)
+		}
+		if p.Name == `` {
+			p.Name = f.Name.Name
+		}
+		p.files[src.File] = f
+		imports := fileImports(f)
+		for _, d := range f.Decls {
+			switch decl := d.(type) {
+			case *ast.FuncDecl:
+				if decl.Body != nil {
+					p.Funcs = append(p.Funcs, newFunc(fset, src.File, decl, imports))
+				}
+			case *ast.GenDecl:
+				p.Structs = append(p.Structs, newStructs(fset, src.File, decl, imports)...)
+			}
+		}
+	}
+	return p, nil
+}
+
+// FieldUses returns the uses of the fields named name in the function bodies,
+// E.G. s.store.Get(). Types are not checked, fields of the same name in
+// other structs are included.
+func (p Package) FieldUses(name string) (uses []Use) {
+	for _, f := range p.Funcs {
+		var stack []ast.Node
+		ast.Inspect(f.decl.Body, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == name && len(stack) > 0 && !f.isImport(sel.X) {
+				u := f.use(sel, stack)
+				u.Pos = f.position(sel.Sel.Pos())
+				uses = append(uses, u)
+			}
+			stack = append(stack, n)
+			return true
+		})
+	}
+	return uses
+}
+
+// Retype replaces the type of the parameter or field prm with the type
+// expression expr. Every name declared with prm is retyped. The expression is
+// placed at the position of the replaced type so comments stay in place.
+func (p *Package) Retype(prm Param, expr string) error {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return err
+	}
+	pos := prm.field.Type.Pos()
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.Ident:
+			v.NamePos = pos
+		case *ast.StarExpr:
+			v.Star = pos
+		case *ast.IndexExpr:
+			v.Lbrack, v.Rbrack = pos, pos
+		case *ast.IndexListExpr:
+			v.Lbrack, v.Rbrack = pos, pos
+		}
+		return true
+	})
+	prm.field.Type = e
+	p.changed[prm.file] = true
+	return nil
+}
+
+// Changed returns the formatted source of the files changed by Retype,
+// ReplaceType or RemoveType keyed by the file name. Files left without
+// declarations have a nil source, they are to be removed.
+func (p Package) Changed() (map[string]*bytes.Buffer, error) {
+	files := map[string]*bytes.Buffer{}
+	for file := range p.changed {
+		if empty(p.files[file]) {
+			files[file] = nil
+			continue
+		}
+		buf := &bytes.Buffer{}
+		err := format.Node(buf, p.fset, p.files[file])
+		if err != nil {
+			return nil, err
+		}
+		out := &bytes.Buffer{}
+		err = srcformat.Format(file, buf.Bytes(), out)
+		if err != nil {
+			return nil, err
+		}
+		files[file] = out
+	}
+	return files, nil
+}
+
+// Func returns the function or method named name. A method is named by the
+// receiver type and method name, E.G. "Server.Handle" or "(*Server).Handle".
+// Returns nil if the function is not found.
+func (p Package) Func(name string) *Func {
+	recv, fn, ok := strings.Cut(strings.NewReplacer(`(`, ``, `)`, ``, `*`, ``).Replace(name), `.`)
+	if !ok {
+		recv, fn = ``, name
+	}
+	for _, f := range p.Funcs {
+		if f.Name == fn && f.Recv == recv {
+			return f
+		}
+	}
+	return nil
+}
+
+// String returns the name of the function, E.G. "Handle" or "Server.Handle"
+func (f Func) String() string {
+	if f.Recv != `` {
+		return f.Recv + `.` + f.Name
+	}
+	return f.Name
+}
+
+// Param returns the parameter named name or nil
+func (f Func) Param(name string) *Param {
+	for i := range f.Params {
+		if f.Params[i].Name == name {
+			return &f.Params[i]
+		}
+	}
+	return nil
+}
+
+// Shares returns true if p and o are declared with the same type expression,
+// E.G. a and b in "a, b *Store"
+func (p Param) Shares(o Param) bool {
+	return p.field == o.field
+}
+
+// Uses returns the uses of the parameter named name in the function body
+func (f Func) Uses(name string) (uses []Use) {
+	prm := f.Param(name)
+	if prm == nil || prm.obj == nil {
+		return nil
+	}
+	return f.objUses(prm.obj)
+}
+
+// LocalUses returns the uses of the local variable l in the function body,
+// starting with the declaration
+func (f Func) LocalUses(l Param) []Use {
+	if l.obj == nil {
+		return nil
+	}
+	return f.objUses(l.obj)
+}
+
+// objUses returns the uses of the identifiers of obj in the function body
+func (f Func) objUses(obj *ast.Object) (uses []Use) {
+	var stack []ast.Node
+	ast.Inspect(f.decl.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if id, ok := n.(*ast.Ident); ok && id.Obj == obj && len(stack) > 0 && !isKey(id, stack[len(stack)-1]) {
+			uses = append(uses, f.use(id, stack))
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return uses
+}
+
+// ResultUses returns the uses of the result at index of the calls to the
+// functions or methods named name, E.G. the Get of New().Get(), or the uses of
+// s in s, err := New() starting with the Set use of the assignment. Types are
+// not checked, calls to methods of the same name of other types are included.
+func (p Package) ResultUses(name string, index int) (uses []Use) {
+	for _, f := range p.Funcs {
+		var stack []ast.Node
+		ast.Inspect(f.decl.Body, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			if call, ok := n.(*ast.CallExpr); ok && callName(call) == name && len(stack) > 0 {
+				switch parent := stack[len(stack)-1].(type) {
+				case *ast.SelectorExpr:
+					if index == 0 {
+						uses = append(uses, f.use(call, stack))
+					}
+				case *ast.AssignStmt:
+					if len(parent.Rhs) == 1 && index < len(parent.Lhs) {
+						if id, ok := parent.Lhs[index].(*ast.Ident); ok && id.Obj != nil {
+							uses = append(uses, f.objUses(id.Obj)...)
+						}
+					}
+				}
+			}
+			stack = append(stack, n)
+			return true
+		})
+	}
+	return uses
+}
+
+// callName returns the name of the called function or method
+func callName(call *ast.CallExpr) string {
+	switch v := call.Fun.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return v.Sel.Name
+	}
+	return ``
+}
+
+// isKey returns true if id is the key of a composite literal element, which
+// go/parser resolves like a value, E.G. st in Server{st: st}
+func isKey(id *ast.Ident, parent ast.Node) bool {
+	kv, ok := parent.(*ast.KeyValueExpr)
+	return ok && kv.Key == id
+}
+
+// use classifies the use of id. stack holds the parents of id.
+func (f Func) use(id ast.Expr, stack []ast.Node) Use {
+	u := Use{Kind: Other, Pos: f.position(id.Pos())}
+	parent := stack[len(stack)-1]
+	switch v := parent.(type) {
+	case *ast.SelectorExpr:
+		if v.X != id {
+			break
+		}
+		u.Kind = Select
+		u.Name = v.Sel.Name
+		u.Pos = f.position(v.Sel.Pos())
+		if len(stack) > 1 {
+			if call, ok := stack[len(stack)-2].(*ast.CallExpr); ok && call.Fun == v {
+				u.Kind = Call
+			}
+		}
+	case *ast.CallExpr:
+		for i, a := range v.Args {
+			if a == id {
+				u.Kind = Arg
+				u.Name = gotypes.ExprString(v.Fun)
+				u.Index = i
+			}
+		}
+	case *ast.AssignStmt:
+		u.Kind = Assign
+		for _, l := range v.Lhs {
+			if l == id {
+				u.Kind = Set
+			}
+		}
+		if sel, ok := v.Lhs[0].(*ast.SelectorExpr); ok && u.Kind == Assign && len(v.Lhs) == 1 {
+			u.Name = sel.Sel.Name
+		}
+	case *ast.KeyValueExpr:
+		u.Kind = Assign
+		if key, ok := v.Key.(*ast.Ident); ok && v.Value == id {
+			u.Name = key.Name
+		}
+	case *ast.ValueSpec, *ast.ReturnStmt, *ast.CompositeLit, *ast.SendStmt:
+		u.Kind = Assign
+	case *ast.TypeAssertExpr:
+		u.Kind = Assert
+	case *ast.BinaryExpr:
+		if v.Op == token.EQL || v.Op == token.NEQ {
+			u.Kind = Compare
+		}
+	}
+	return u
+}
+
+func (f Func) position(pos token.Pos) diag.Position {
+	p := f.fset.Position(pos)
+	return diag.Position{File: p.Filename, Line: p.Line, Col: p.Column}
+}
+
+// isImport returns true if x names an import of the file
+func (f Func) isImport(x ast.Expr) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && id.Obj == nil && f.Imports[id.Name] != ``
+}
+
+func newFunc(fset *token.FileSet, file string, decl *ast.FuncDecl, imports map[string]string) *Func {
+	f := &Func{
+		Name:    decl.Name.Name,
+		Imports: imports,
+		decl:    decl,
+		fset:    fset,
+	}
+	f.Pos = f.position(decl.Pos())
+	if decl.Recv != nil && len(decl.Recv.List) == 1 {
+		f.Recv = recvName(decl.Recv.List[0].Type)
+	}
+	f.Params = newParams(fset, file, decl.Type.Params.List)
+	f.Locals = newLocals(fset, file, decl.Body)
+	if decl.Type.Results != nil {
+		for _, field := range decl.Type.Results.List {
+			for i := 0; i < len(field.Names) || i == 0; i++ {
+				f.Results = append(f.Results, gotypes.ExprString(field.Type))
+			}
+		}
+	}
+	return f
+}
+
+// newStructs returns the struct types of a type declaration
+func newStructs(fset *token.FileSet, file string, decl *ast.GenDecl, imports map[string]string) (structs []*Struct) {
+	for _, spec := range decl.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		p := fset.Position(ts.Pos())
+		structs = append(structs, &Struct{
+			Name:    ts.Name.Name,
+			Fields:  newParams(fset, file, st.Fields.List),
+			Imports: imports,
+			Pos:     diag.Position{File: p.Filename, Line: p.Line, Col: p.Column},
+		})
+	}
+	return
+}
+
+// newParams returns the named parameters or fields of a field list
+func newParams(fset *token.FileSet, file string, fields []*ast.Field) (params []Param) {
+	for _, field := range fields {
+		for _, n := range field.Names {
+			p := fset.Position(n.Pos())
+			params = append(params, Param{
+				Name:  n.Name,
+				Type:  gotypes.ExprString(field.Type),
+				Names: len(field.Names),
+				Pos:   diag.Position{File: p.Filename, Line: p.Line, Col: p.Column},
+				file:  file,
+				field: field,
+				obj:   n.Obj,
+			})
+		}
+	}
+	return
+}
+
+// newLocals returns the local variables of body declared with a type, E.G.
+// "var g Getter", or declared with a conversion or a type assertion, E.G.
+// "g := Getter(s)" or "g, ok := v.(Getter)". The type of a conversion is not
+// checked, the name of any function called with one argument is the type.
+func newLocals(fset *token.FileSet, file string, body *ast.BlockStmt) (locals []Param) {
+	add := func(id *ast.Ident, typ ast.Expr, names int) {
+		if id.Name == `_` || id.Obj == nil || typ == nil {
+			return
+		}
+		p := fset.Position(id.Pos())
+		locals = append(locals, Param{
+			Name:  id.Name,
+			Type:  gotypes.ExprString(typ),
+			Names: names,
+			Pos:   diag.Position{File: p.Filename, Line: p.Line, Col: p.Column},
+			file:  file,
+			obj:   id.Obj,
+		})
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.ValueSpec:
+			for _, id := range v.Names {
+				add(id, v.Type, len(v.Names))
+			}
+		case *ast.AssignStmt:
+			if v.Tok != token.DEFINE {
+				break
+			}
+			for i, l := range v.Lhs {
+				id, ok := l.(*ast.Ident)
+				if !ok || id.Obj == nil || id.Obj.Decl != v {
+					continue
+				} else if len(v.Rhs) == len(v.Lhs) {
+					add(id, localType(v.Rhs[i]), 1)
+				} else if _, ok := v.Rhs[0].(*ast.TypeAssertExpr); ok && len(v.Rhs) == 1 && i == 0 {
+					add(id, localType(v.Rhs[0]), 1)
+				}
+			}
+		}
+		return true
+	})
+	return
+}
+
+// localType returns the type of a conversion or type assertion, or nil for
+// other expressions
+func localType(x ast.Expr) ast.Expr {
+	switch v := x.(type) {
+	case *ast.ParenExpr:
+		return localType(v.X)
+	case *ast.TypeAssertExpr:
+		return v.Type
+	case *ast.CallExpr:
+		if len(v.Args) != 1 || v.Ellipsis.IsValid() {
+			return nil
+		}
+		switch fun := v.Fun.(type) {
+		case *ast.Ident:
+			return fun
+		case *ast.SelectorExpr:
+			if _, ok := fun.X.(*ast.Ident); ok {
+				return fun
+			}
+		}
+	}
+	return nil
+}
+
+// SplitType returns the package name and type name of a named type or a
+// pointer to a named type, E.G. "*store.Store" returns "store" and "Store".
+// ok is false for other types.
+func SplitType(expr string) (sel, typ string, ok bool) {
+	typ = strings.TrimPrefix(expr, `*`)
+	if s, t, found := strings.Cut(typ, `.`); found {
+		sel, typ = s, t
+	}
+	if !token.IsIdentifier(typ) || sel != `` && !token.IsIdentifier(sel) {
+		return ``, ``, false
+	}
+	return sel, typ, true
+}
+
+// TypeMatch returns true if the parameter type expr is pattern or is pattern
+// qualified by a package, E.G. "*store.Store" matches "*Store"
+func TypeMatch(expr, pattern string) bool {
+	if pattern == `` {
+		return false
+	} else if expr == pattern {
+		return true
+	}
+	star := strings.HasPrefix(expr, `*`)
+	if _, t, ok := strings.Cut(strings.TrimPrefix(expr, `*`), `.`); ok {
+		if star {
+			t = `*` + t
+		}
+		return t == pattern
+	}
+	return false
+}
+
+// recvName returns the type name of a receiver
+func recvName(expr ast.Expr) string {
+	switch v := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(v.X)
+	case *ast.IndexExpr:
+		return recvName(v.X)
+	case *ast.IndexListExpr:
+		return recvName(v.X)
+	case *ast.Ident:
+		return v.Name
+	}
+	return ``
+}
+
+// fileImports returns the import paths of a file keyed by package name. The
+// package name of an unnamed import is the last element of the path.
+func fileImports(f *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, i := range f.Imports {
+		path := strings.Trim(i.Path.Value, `"`)
+		name := path[strings.LastIndex(path, `/`)+1:]
+		if i.Name != nil {
+			name = i.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
@@ -0,0 +1,233 @@
+package uses
+
+import (
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var src = `package handler
+
+import (
+	"fmt"
+
+	st "example.com/app/store"
+)
+
+type Server struct{}
+
+func (s *Server) Handle(store *st.Store, id string) error {
+	if store == nil {
+		return nil
+	}
+	u, err := store.Get(id)
+	list := store.List
+	fmt.Println(store.Name, u, list)
+	audit(store)
+	other := store
+	_ = other
+	_, _ = store.(fmt.Stringer)
+	func() {
+		store := 1
+		_ = store
+	}()
+	return err
+}
+
+func audit(s *st.Store) {}
+
+func noBody()
+`
+
+func TestFunc_Uses(t *testing.T) {
+	p, err := Parse([]srcio.Source{{File: `handler.go`, Src: src}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `handler`, p.Name)
+	assert.Len(t, p.Funcs, 2)
+	f := p.Func(`(*Server).Handle`)
+	if !assert.NotNil(t, f) {
+		t.FailNow()
+	}
+	assert.Equal(t, `Server.Handle`, f.String())
+	assert.Equal(t, `*st.Store`, f.Param(`store`).Type)
+	assert.Equal(t, `example.com/app/store`, f.Imports[`st`])
+	assert.Equal(t, `fmt`, f.Imports[`fmt`])
+
+	var kinds []int
+	var names []string
+	for _, u := range f.Uses(`store`) {
+		kinds = append(kinds, u.Kind)
+		names = append(names, u.Name)
+	}
+	assert.Equal(t, []int{Compare, Call, Select, Select, Arg, Assign, Assert}, kinds)
+	assert.Equal(t, []string{``, `Get`, `List`, `Name`, `audit`, ``, ``}, names)
+	get := f.Uses(`store`)[1]
+	assert.Equal(t, `handler.go:15:18`, get.Pos.String())
+
+	assert.Nil(t, f.Uses(`unknown`))
+	assert.NotNil(t, p.Func(`audit`))
+	assert.Nil(t, p.Func(`Handle`))
+}
+
+var fieldSrc = `package handler
+
+import "example.com/app/store"
+
+// Server serves users
+type Server struct {
+	st    *store.Store // st user store
+	a, b  int
+}
+
+func New(st *store.Store) *Server {
+	s := &Server{st: st}
+	s.st = st
+	return s
+}
+
+func (s *Server) Handle(id string) {
+	s.st.Get(id)
+	_ = s.st.Name
+	store.st()
+}
+`
+
+func TestPackage_FieldUses(t *testing.T) {
+	p, err := Parse([]srcio.Source{{File: `handler.go`, Src: fieldSrc}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, p.Structs, 1) {
+		t.FailNow()
+	}
+	s := p.Structs[0]
+	assert.Equal(t, `Server`, s.Name)
+	assert.Len(t, s.Fields, 3)
+	assert.Equal(t, `*store.Store`, s.Fields[0].Type)
+	assert.True(t, s.Fields[1].Shares(s.Fields[2]))
+	assert.False(t, s.Fields[0].Shares(s.Fields[1]))
+
+	var kinds []int
+	var names []string
+	for _, u := range p.FieldUses(`st`) {
+		kinds = append(kinds, u.Kind)
+		names = append(names, u.Name)
+	}
+	assert.Equal(t, []int{Set, Call, Select}, kinds)
+	assert.Equal(t, []string{``, `Get`, `Name`}, names)
+
+	kinds, names = nil, nil
+	for _, u := range p.Func(`New`).Uses(`st`) {
+		kinds = append(kinds, u.Kind)
+		names = append(names, u.Name)
+	}
+	assert.Equal(t, []int{Assign, Assign}, kinds)
+	assert.Equal(t, []string{`st`, `st`}, names)
+}
+
+func TestPackage_Retype(t *testing.T) {
+	p, err := Parse([]srcio.Source{{File: `handler.go`, Src: fieldSrc}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = p.Retype(p.Structs[0].Fields[0], `store.StoreIface`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = p.Retype(p.Func(`New`).Params[0], `store.StoreIface`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	files, err := p.Changed()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, files[`handler.go`].String(), `type Server struct {
+	st   store.StoreIface // st user store
+	a, b int
+}
+
+func New(st store.StoreIface) *Server {`)
+}
+
+func TestTypeMatch(t *testing.T) {
+	assert.True(t, TypeMatch(`*store.Store`, `*Store`))
+	assert.True(t, TypeMatch(`*store.Store`, `*store.Store`))
+	assert.False(t, TypeMatch(`store.Store`, `*Store`))
+	assert.False(t, TypeMatch(`*Store`, ``))
+	sel, typ, ok := SplitType(`*store.Store`)
+	assert.Equal(t, []any{`store`, `Store`, true}, []any{sel, typ, ok})
+	_, _, ok = SplitType(`[]Store`)
+	assert.False(t, ok)
+}
+
+var resultSrc = `package handler
+
+func New() (*Store, error) { return nil, nil }
+
+func run() {
+	New().Get()
+	s, err := New()
+	s.List()
+	_ = err
+}
+
+func noBody()
+`
+
+func TestPackage_ResultUses(t *testing.T) {
+	p, err := Parse([]srcio.Source{{File: `handler.go`, Src: resultSrc}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{`*Store`, `error`}, p.Func(`New`).Results)
+
+	var kinds []int
+	var names []string
+	for _, u := range p.ResultUses(`New`, 0) {
+		kinds = append(kinds, u.Kind)
+		names = append(names, u.Name)
+	}
+	assert.Equal(t, []int{Call, Set, Call}, kinds)
+	assert.Equal(t, []string{`Get`, ``, `List`}, names)
+
+	kinds = nil
+	for _, u := range p.ResultUses(`New`, 1) {
+		kinds = append(kinds, u.Kind)
+	}
+	assert.Equal(t, []int{Set, Assign}, kinds)
+}
+
+func TestFunc_Locals(t *testing.T) {
+	src := `package handler
+
+func Handle(v any) {
+	var g, h Getter
+	s := store.Getter(Impl{})
+	a, ok := v.(Getter)
+	n, err := New()
+	g.Get()
+	s.Put()
+	a.Delete()
+	_, _, _, _ = h, ok, n, err
+}
+`
+	p, err := Parse([]srcio.Source{{File: `handler.go`, Src: src}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	fn := p.Func(`Handle`)
+	var locals []string
+	for _, l := range fn.Locals {
+		locals = append(locals, l.Name+` `+l.Type)
+	}
+	assert.Equal(t, []string{`g Getter`, `h Getter`, `s store.Getter`, `a Getter`}, locals)
+	var kinds []int
+	for _, u := range fn.LocalUses(fn.Locals[2]) {
+		kinds = append(kinds, u.Kind)
+	}
+	assert.Equal(t, []int{Set, Call}, kinds)
+}
@@ -0,0 +1,163 @@
+// Package annotations generates interfaces from "//ifaces:interface"
+// annotations in type documents.
+package annotations
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+	"github.com/dexterp/ifaces/internal/services/generate"
+)
+
+//go:generate ifaces type -o annotations_iface.go -i AnnotationsIface
+
+// Directive name of the annotation. The annotation is followed by the interface
+// name and any options of the type sub command, E.G.
+//
+//	//ifaces:interface StoreIface -o store_iface.go --exclude String
+const Directive = `interface`
+
+var (
+	ErrNoIfaceName = errors.New(`annotation has no interface name`)
+)
+
+// Annotations generate interfaces from annotations
+type Annotations struct {
+	Current func(file string) (*bytes.Buffer, error) // Current returns a previously generated file when appending
+	NewGen  func(args *cli.Args) *generate.Generate  // NewGen creates a generator from the annotation options
+}
+
+// annotation an interface annotation on a type
+type annotation struct {
+	dir    string    // dir package directory
+	file   string    // file source file
+	typ    string    // typ annotated type
+	args   *cli.Args // args options from the annotation
+	line   int       // line line number of the annotation
+	srcs   []srcio.Source
+	parsed *parser.Parser
+}
+
+// Generate generates the interfaces annotated in the packages matching
+// patterns. Packages are expanded with paths.PackageDirs. The generated source
+// is returned in a map keyed by the file name. Annotations writing to the same
+// file are added to the file in the order they are found.
+func (a Annotations) Generate(patterns []string) (map[string]*bytes.Buffer, error) {
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var anns []annotation
+	for _, dir := range dirs {
+		found, err := a.find(dir)
+		if err != nil {
+			return nil, err
+		}
+		anns = append(anns, found...)
+	}
+	files := generate.Files{}
+	for _, ann := range anns {
+		err = a.generate(ann, files)
+		if err != nil {
+			return nil, diag.At(diag.Position{File: ann.file, Line: ann.line}, err)
+		}
+	}
+	return files, nil
+}
+
+// find returns the annotations in the package within dir
+func (a Annotations) find(dir string) (anns []annotation, err error) {
+	srcs, err := srcio.ReadDir(dir)
+	if err != nil || len(srcs) == 0 {
+		return nil, err
+	}
+	p, err := parser.ParseFiles(srcs)
+	if err != nil {
+		return nil, err
+	}
+	for _, typ := range p.Types {
+		for _, d := range typ.Directives.Get(Directive) {
+			file := filepath.Join(dir, typ.File)
+			args, err := a.parseArgs(file, typ.Name, d.Text)
+			if err != nil {
+				return nil, diag.At(diag.Position{File: file, Line: d.Line}, err)
+			}
+			// Default to the package name of the source when writing to the
+			// package directory.
+			if args.Pkg == `` && args.OutTmpl == `` && !filepath.IsAbs(args.Out) && filepath.Dir(args.Out) == `.` {
+				args.Pkg = p.Package
+			}
+			anns = append(anns, annotation{
+				dir:    dir,
+				file:   file,
+				typ:    typ.Name,
+				args:   args,
+				line:   d.Line,
+				srcs:   srcs,
+				parsed: p,
+			})
+		}
+	}
+	sort.SliceStable(anns, func(i, j int) bool {
+		if anns[i].file != anns[j].file {
+			return anns[i].file < anns[j].file
+		}
+		return anns[i].line < anns[j].line
+	})
+	return anns, nil
+}
+
+// parseArgs parses the annotation text using the options of the type sub
+// command. The output file defaults to the source file name with an _iface.go
+// suffix.
+func (a Annotations) parseArgs(file, typ, text string) (*cli.Args, error) {
+	fields, err := stringx.SplitArgs(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || strings.HasPrefix(fields[0], `-`) {
+		return nil, ErrNoIfaceName
+	}
+	argv := append([]string{`type`, `-i`, fields[0], `-t`, typ, `-f`, file}, fields[1:]...)
+	args, err := cli.ParseArgs(argv, ``, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid annotation options %q: %w`, text, err)
+	}
+	if args.Out == `` && args.OutTmpl == `` {
+		args.Out = strings.TrimSuffix(filepath.Base(file), `.go`) + `_iface.go`
+	}
+	return args, nil
+}
+
+// generate generates the interface for a single annotation and adds the output
+// to files.
+func (a Annotations) generate(ann annotation, files generate.Files) error {
+	gen := a.NewGen(ann.args)
+	// Select the annotated declaration by name, -t only matches exported types.
+	gen.TypeName = ann.typ
+	gen.Parse = func([]srcio.Source) (*parser.Parser, error) {
+		return ann.parsed, nil
+	}
+	if gen.OutTmpl != `` {
+		gen.OutTmpl = filepath.Join(ann.dir, gen.OutTmpl)
+	}
+	if gen.HeaderFile != `` && !filepath.IsAbs(gen.HeaderFile) {
+		gen.HeaderFile = filepath.Join(ann.dir, gen.HeaderFile)
+	}
+	file := ann.args.Out
+	if file != `` && !filepath.IsAbs(file) {
+		file = filepath.Join(ann.dir, file)
+	}
+	return files.Add(gen, ann.srcs, file, ann.args.Append, a.Current)
+}
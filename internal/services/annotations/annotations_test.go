@@ -0,0 +1,148 @@
+package annotations
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/stretchr/testify/assert"
+)
+
+var src = `package store
+
+// Store stores items
+//
+//ifaces:interface StoreIface -o ifaces.go --exclude String
+type Store struct{}
+
+// Get gets an item
+func (s *Store) Get(id string) error { return nil }
+
+// String name
+func (s *Store) String() string { return "" }
+
+// Cache caches items
+//
+//ifaces:interface CacheIface -o ifaces.go -a
+//ifaces:interface Getter --include Get --tdoc "Getter gets items"
+type Cache struct{}
+
+// Get gets an item
+func (c Cache) Get(id string) string { return "" }
+
+// Put puts an item
+func (c Cache) Put(id string) {}
+`
+
+func writeSrc(t *testing.T, src string) string {
+	dir := filepath.Join(t.TempDir(), `store`)
+	err := os.Mkdir(dir, 0755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, `store.go`), []byte(src), 0600)
+	}
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return dir
+}
+
+func TestAnnotations_Generate(t *testing.T) {
+	dir := writeSrc(t, src)
+	a := &Annotations{
+		Current: func(file string) (*bytes.Buffer, error) {
+			t.Errorf(`unexpected read of %s`, file)
+			return nil, nil
+		},
+		NewGen: generate.FromArgs,
+	}
+	files, err := a.Generate([]string{filepath.Dir(dir) + `/...`})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, files, 2) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+// StoreIface stores items
+type StoreIface interface {
+	// Get gets an item
+	Get(id string) error
+}
+
+// CacheIface caches items
+type CacheIface interface {
+	// Get gets an item
+	Get(id string) string
+	// Put puts an item
+	Put(id string)
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(dir, `ifaces.go`)].String())
+	expected = `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+// Getter gets items
+type Getter interface {
+	// Get gets an item
+	Get(id string) string
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(dir, `store_iface.go`)].String())
+}
+
+func TestAnnotations_Generate_Error(t *testing.T) {
+	dir := writeSrc(t, "package store\n\n//ifaces:interface -o ifaces.go\ntype Store struct{}\n")
+	a := &Annotations{NewGen: generate.FromArgs}
+	_, err := a.Generate([]string{dir})
+	assert.ErrorIs(t, err, ErrNoIfaceName)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `store.go:3:`)
+	}
+
+	dir = writeSrc(t, "package store\n\n//ifaces:interface Iface --unknown\ntype Store struct{}\n")
+	_, err = a.Generate([]string{dir})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid annotation options`)
+	}
+}
+
+func TestAnnotations_Generate_Unexported(t *testing.T) {
+	dir := writeSrc(t, `package store
+
+// store stores items
+//
+//ifaces:interface Store -o ifaces.go
+type store struct{}
+
+// Get gets an item
+func (s *store) Get(id string) error { return nil }
+
+// storeCache caches items
+type storeCache struct{}
+
+// Put puts an item
+func (c storeCache) Put(id string) {}
+`)
+	a := &Annotations{NewGen: generate.FromArgs}
+	files, err := a.Generate([]string{dir})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+// Store stores items
+type Store interface {
+	// Get gets an item
+	Get(id string) error
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(dir, `ifaces.go`)].String())
+}
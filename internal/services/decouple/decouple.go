@@ -0,0 +1,340 @@
+// Package decouple rewrites the parameters and struct fields of a concrete
+// type to an interface. A parameter or field is only rewritten if every use
+// is covered by the methods of the interface.
+package decouple
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/modinfo"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/types"
+	"github.com/dexterp/ifaces/internal/resources/uses"
+)
+
+//go:generate ifaces type -o decouple_iface.go -i DecoupleIface
+
+var (
+	ErrIfaceNotFound = errors.New(`could not match interface`)
+	ErrNoSites       = errors.New(`no parameters or fields of type`)
+)
+
+// Decouple rewrites concrete dependencies to interfaces
+type Decouple struct {
+	Args     *cli.Args        // Args options of the decouple sub command
+	Print    print.PrintIface // Print handler
+	Reporter *diag.Reporter   // Reporter collects warnings instead of printing them with Print
+}
+
+// pkg parsed package
+type pkg struct {
+	dir    string
+	parsed *uses.Package
+}
+
+// site parameter or struct field of the type
+type site struct {
+	pkg     *pkg
+	fn      *uses.Func   // fn function of a parameter, nil for fields
+	strct   *uses.Struct // strct struct of a field, nil for parameters
+	prm     uses.Param
+	iface   string          // iface type expression of the interface
+	methods map[string]bool // methods method names of the interface
+	uses    []use
+	blocked []reason
+}
+
+// use use of a site in a package
+type use struct {
+	pkg *pkg
+	uses.Use
+}
+
+// reason use which is not covered by the interface
+type reason struct {
+	pos diag.Position
+	msg string
+}
+
+// String returns a description of the site, E.G. "parameter st of
+// Server.Handle"
+func (s site) String() string {
+	if s.fn != nil {
+		return fmt.Sprintf(`parameter %s of %s`, s.prm.Name, s.fn)
+	}
+	return fmt.Sprintf(`field %s of %s`, s.prm.Name, s.strct.Name)
+}
+
+// Rewrite rewrites the parameters and struct fields of the type -t in the
+// packages matching patterns to the interface -i. The rewritten files are
+// returned keyed by the file name. Parameters and fields with uses which are
+// not covered by the interface are kept and reported.
+func (d Decouple) Rewrite(patterns []string) (map[string]*bytes.Buffer, error) {
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []*pkg
+	for _, dir := range dirs {
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := uses.Parse(srcs)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, &pkg{dir: dir, parsed: parsed})
+	}
+	sites := d.sites(pkgs)
+	if len(sites) == 0 {
+		return nil, diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w %s`, ErrNoSites, d.Args.MatchType).
+			WithFix(`check that -t is written as in the declarations, E.G. '*Store' or '*store.Store'`)
+	}
+	ifaces := &ifaces{d: d, methods: map[string]map[string]bool{}}
+	for _, s := range sites {
+		err = ifaces.resolve(s)
+		if err != nil {
+			return nil, err
+		}
+		s.uses = d.uses(pkgs, s)
+	}
+	d.cover(sites)
+	files := map[string]*bytes.Buffer{}
+	for _, s := range sites {
+		if len(s.blocked) > 0 {
+			for _, r := range s.blocked {
+				diag.Warn(d.Reporter, d.Print, diag.New(diag.Warning, diag.CodeUntouchable, r.pos,
+					fmt.Sprintf(`%s is kept as %s, %s`, s, s.prm.Type, r.msg),
+				).WithFix(`add the methods to `+d.Args.Iface+` or rewrite the use by hand`))
+			}
+			continue
+		}
+		diag.Explainf(d.Print, `%s at %s rewritten to %s`, s, s.prm.Pos, s.iface)
+		err = s.pkg.parsed.Retype(s.prm, s.iface)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range pkgs {
+		changed, err := p.parsed.Changed()
+		if err != nil {
+			return nil, err
+		}
+		for file, buf := range changed {
+			files[file] = buf
+		}
+	}
+	return files, nil
+}
+
+// sites returns the parameters and fields of the type. Parameters of the
+// methods of the type are left out, they would change the methods of the
+// interface.
+func (d Decouple) sites(pkgs []*pkg) (sites []*site) {
+	_, typ, _ := uses.SplitType(d.Args.MatchType)
+	for _, p := range pkgs {
+		for _, fn := range p.parsed.Funcs {
+			for _, prm := range fn.Params {
+				if !uses.TypeMatch(prm.Type, d.Args.MatchType) {
+					continue
+				} else if fn.Recv == typ {
+					diag.Explainf(d.Print, `%s: parameter %s of a method of %s skipped`, fn, prm.Name, typ)
+					continue
+				}
+				sites = append(sites, &site{pkg: p, fn: fn, prm: prm})
+			}
+		}
+		for _, st := range p.parsed.Structs {
+			for _, prm := range st.Fields {
+				if uses.TypeMatch(prm.Type, d.Args.MatchType) {
+					sites = append(sites, &site{pkg: p, strct: st, prm: prm})
+				}
+			}
+		}
+	}
+	return
+}
+
+// uses returns the uses of a site. Exported fields are used in all the
+// packages, unexported fields in the package of the struct.
+func (d Decouple) uses(pkgs []*pkg, s *site) (out []use) {
+	if s.fn != nil {
+		for _, u := range s.fn.Uses(s.prm.Name) {
+			out = append(out, use{pkg: s.pkg, Use: u})
+		}
+		return
+	}
+	for _, p := range pkgs {
+		if p != s.pkg && !match.Capitalized(s.prm.Name) {
+			continue
+		}
+		for _, u := range p.parsed.FieldUses(s.prm.Name) {
+			out = append(out, use{pkg: p, Use: u})
+		}
+	}
+	return
+}
+
+// cover sets the uses of the sites which are not covered by the interface.
+// Sites passed to or assigned to other sites are covered while the other
+// sites are covered, so the sites are checked until none are left out.
+func (d Decouple) cover(sites []*site) {
+	for changed := true; changed; {
+		changed = false
+		for _, s := range sites {
+			if len(s.blocked) > 0 {
+				continue
+			}
+			for _, u := range s.uses {
+				if msg, ok := d.covered(sites, s, u); !ok {
+					s.blocked = append(s.blocked, reason{pos: u.Pos, msg: msg})
+				}
+			}
+			if len(s.blocked) == 0 {
+				continue
+			}
+			changed = true
+			for _, o := range sites {
+				if o != s && o.pkg == s.pkg && len(o.blocked) == 0 && o.prm.Shares(s.prm) {
+					o.blocked = append(o.blocked, reason{pos: o.prm.Pos, msg: fmt.Sprintf(`it is declared with %s`, s.prm.Name)})
+				}
+			}
+		}
+	}
+}
+
+// covered returns true if a use of a site is covered by the interface,
+// otherwise it returns the reason
+func (d Decouple) covered(sites []*site, s *site, u use) (string, bool) {
+	switch u.Kind {
+	case uses.Call, uses.Select:
+		if s.methods[u.Name] {
+			return ``, true
+		}
+		return fmt.Sprintf(`%s is not a method of %s`, u.Name, d.Args.Iface), false
+	case uses.Compare, uses.Set:
+		return ``, true
+	case uses.Arg:
+		if callee := u.pkg.parsed.Func(u.Name); callee != nil && u.Index < len(callee.Params) {
+			if o := find(sites, func(o *site) bool { return o.fn == callee && o.prm.Name == callee.Params[u.Index].Name }); o != nil && len(o.blocked) == 0 {
+				return ``, true
+			}
+		}
+		return fmt.Sprintf(`it is passed to %s which takes a %s`, u.Name, s.prm.Type), false
+	case uses.Assign:
+		if u.Name != `` {
+			if o := find(sites, func(o *site) bool { return o.strct != nil && o.pkg == u.pkg && o.prm.Name == u.Name }); o != nil && len(o.blocked) == 0 {
+				return ``, true
+			}
+		}
+		return `it is assigned, returned or stored as a ` + s.prm.Type, false
+	case uses.Assert:
+		return `it is type asserted`, false
+	}
+	return `it is used as a value`, false
+}
+
+// find returns the first site matching fn or nil
+func find(sites []*site, fn func(o *site) bool) *site {
+	for _, s := range sites {
+		if fn(s) {
+			return s
+		}
+	}
+	return nil
+}
+
+// ifaces resolves the interface of the sites
+type ifaces struct {
+	d       Decouple
+	methods map[string]map[string]bool // methods method names keyed by the package directory, nil if the interface is not declared
+}
+
+// resolve sets the interface of a site. The interface is declared in the
+// package declaring the type, or in the package of the site.
+func (i *ifaces) resolve(s *site) error {
+	var imports map[string]string
+	if s.fn != nil {
+		imports = s.fn.Imports
+	} else {
+		imports = s.strct.Imports
+	}
+	sel, typ, _ := uses.SplitType(s.prm.Type)
+	if sel != `` {
+		if imp, ok := imports[sel]; ok {
+			dir, err := modinfo.ImportDir(s.pkg.dir, imp)
+			if err != nil {
+				return fmt.Errorf(`can not find package directory %s: %w`, imp, err)
+			}
+			methods, err := i.get(dir)
+			if err != nil {
+				return err
+			}
+			if methods != nil {
+				s.iface, s.methods = sel+`.`+i.d.Args.Iface, methods
+				return nil
+			}
+		}
+	}
+	methods, err := i.get(s.pkg.dir)
+	if err != nil {
+		return err
+	}
+	if methods == nil {
+		return diag.Errorf(diag.CodeTypeNotFound, s.prm.Pos, `%w %s for the %s`, ErrIfaceNotFound, i.d.Args.Iface, s).
+			WithFix(fmt.Sprintf(`generate the interface first, E.G. ifaces type -t %s -i %s`, typ, i.d.Args.Iface))
+	}
+	s.iface, s.methods = i.d.Args.Iface, methods
+	return nil
+}
+
+// get returns the method names of the interface declared in dir
+func (i *ifaces) get(dir string) (map[string]bool, error) {
+	if methods, ok := i.methods[dir]; ok {
+		return methods, nil
+	}
+	srcs, err := srcio.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parser.ParseFiles(srcs)
+	if err != nil {
+		return nil, err
+	}
+	q := parser.NewQuery(p)
+	var methods map[string]bool
+	if typ := q.GetTypeByName(i.d.Args.Iface); typ != nil && typ.Type == types.INTERFACE {
+		methods = map[string]bool{}
+		i.add(q, *typ, methods, map[string]bool{})
+		diag.Explainf(i.d.Print, `interface %s declared in %s`, i.d.Args.Iface, dir)
+	}
+	i.methods[dir] = methods
+	return methods, nil
+}
+
+// add adds the methods of an interface and the interfaces it embeds
+func (i *ifaces) add(q *parser.Query, typ parser.Type, methods, seen map[string]bool) {
+	if seen[typ.Name] {
+		return
+	}
+	seen[typ.Name] = true
+	for _, name := range typ.Embeds {
+		if name == `error` {
+			methods[`Error`] = true
+		} else if embedded := q.GetTypeByName(name); embedded != nil && embedded.Type == types.INTERFACE {
+			i.add(q, *embedded, methods, seen)
+		}
+	}
+	for _, m := range q.GetIfaceMethods(typ.Name) {
+		methods[m.Name] = true
+	}
+}
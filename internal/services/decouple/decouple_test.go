@@ -0,0 +1,194 @@
+package decouple
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/stretchr/testify/assert"
+)
+
+var store = `package store
+
+// Store stores users
+type Store struct {
+	Name string
+}
+
+func (s *Store) Get(id string) (string, error) { return "", nil }
+
+func (s *Store) List() ([]string, error) { return nil, nil }
+
+func (s *Store) Merge(o *Store) {}
+`
+
+var storeIface = `package store
+
+type Getter interface {
+	Get(id string) (string, error)
+}
+
+type StoreIface interface {
+	Getter
+	List() ([]string, error)
+}
+`
+
+var handler = `package handler
+
+import "example.com/app/store"
+
+// Server serves users
+type Server struct {
+	st    *store.Store // st user store
+	Named *store.Store
+}
+
+func New(st *store.Store) *Server {
+	return &Server{st: st}
+}
+
+func (s *Server) Handle(id string) error {
+	_, err := s.st.Get(id)
+	list(s.st)
+	return err
+}
+
+func list(st *store.Store) {
+	if st != nil {
+		st.List()
+	}
+}
+
+func Name(st *store.Store) string {
+	return st.Name
+}
+
+func named(s *Server) string {
+	return s.Named.Name
+}
+`
+
+func TestDecouple_Rewrite(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`:               "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`:       store,
+		`store/store_iface.go`: storeIface,
+		`handler/handler.go`:   handler,
+	})
+	reporter := &diag.Reporter{}
+	d := Decouple{
+		Args:     &cli.Args{MatchType: `*Store`, Iface: `StoreIface`},
+		Reporter: reporter,
+	}
+	files, err := d.Rewrite([]string{filepath.Join(root, `...`)})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	file := filepath.Join(root, `handler`, `handler.go`)
+	assert.Len(t, files, 1)
+	if !assert.Contains(t, files, file) {
+		t.FailNow()
+	}
+	expected := `package handler
+
+import "example.com/app/store"
+
+// Server serves users
+type Server struct {
+	st    store.StoreIface // st user store
+	Named *store.Store
+}
+
+func New(st store.StoreIface) *Server {
+	return &Server{st: st}
+}
+
+func (s *Server) Handle(id string) error {
+	_, err := s.st.Get(id)
+	list(s.st)
+	return err
+}
+
+func list(st store.StoreIface) {
+	if st != nil {
+		st.List()
+	}
+}
+
+func Name(st *store.Store) string {
+	return st.Name
+}
+
+func named(s *Server) string {
+	return s.Named.Name
+}
+`
+	assert.Equal(t, expected, files[file].String())
+	var msgs []string
+	for _, d := range reporter.List() {
+		assert.Equal(t, diag.CodeUntouchable, d.Code)
+		msgs = append(msgs, d.Pos.String()[len(root)+1:]+`: `+d.Message)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(`handler`, `handler.go`) + `:28:12: parameter st of Name is kept as *store.Store, Name is not a method of StoreIface`,
+		filepath.Join(`handler`, `handler.go`) + `:32:11: field Named of Server is kept as *store.Store, Name is not a method of StoreIface`,
+	}, msgs)
+}
+
+func TestDecouple_Rewrite_Blocked(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`:               "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`:       store,
+		`store/store_iface.go`: storeIface,
+		`handler/handler.go`: `package handler
+
+import "example.com/app/store"
+
+func Handle(st *store.Store) {
+	st.Get("id")
+	keep(st)
+}
+
+func keep(st *store.Store) *store.Store {
+	st.List()
+	return st
+}
+`,
+	})
+	reporter := &diag.Reporter{}
+	d := Decouple{
+		Args:     &cli.Args{MatchType: `*store.Store`, Iface: `StoreIface`},
+		Reporter: reporter,
+	}
+	files, err := d.Rewrite([]string{filepath.Join(root, `handler`)})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, files)
+	var msgs []string
+	for _, d := range reporter.List() {
+		msgs = append(msgs, d.Message)
+	}
+	assert.Equal(t, []string{
+		`parameter st of Handle is kept as *store.Store, it is passed to keep which takes a *store.Store`,
+		`parameter st of keep is kept as *store.Store, it is assigned, returned or stored as a *store.Store`,
+	}, msgs)
+}
+
+func TestDecouple_Rewrite_NotFound(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`:             "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`:     store,
+		`handler/handler.go`: handler,
+	})
+	d := Decouple{Args: &cli.Args{MatchType: `*Store`, Iface: `StoreIface`}}
+	_, err := d.Rewrite([]string{filepath.Join(root, `handler`)})
+	assert.ErrorIs(t, err, ErrIfaceNotFound)
+
+	d = Decouple{Args: &cli.Args{MatchType: `*Other`, Iface: `StoreIface`}}
+	_, err = d.Rewrite([]string{filepath.Join(root, `handler`)})
+	assert.ErrorIs(t, err, ErrNoSites)
+}
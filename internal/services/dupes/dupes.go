@@ -0,0 +1,339 @@
+// Package dupes finds the interfaces of different packages with the same or
+// nearly the same method set and consolidates duplicates into one canonical
+// interface. Method sets are compared on the normalised signatures, the names
+// of the interfaces and parameters, the documents and the method order do not
+// count.
+package dupes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/methodset"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/uses"
+)
+
+//go:generate ifaces type -o dupes_iface.go -i DupesIface
+
+// Output formats of List
+const (
+	FormatText = `text`
+	FormatJSON = `json`
+)
+
+// Formats output formats of List
+var Formats = []string{FormatText, FormatJSON}
+
+var (
+	ErrFormat        = errors.New(`invalid dupes format`)
+	ErrIfaceNotFound = errors.New(`could not match interface`)
+	ErrNoDupes       = errors.New(`no duplicates of`)
+	ErrUnexported    = errors.New(`can not consolidate into unexported interface`)
+)
+
+// Dupes finds duplicate interfaces
+type Dupes struct {
+	Args     *cli.Args        // Args options of the dupes sub command
+	Print    print.PrintIface // Print handler
+	Reporter *diag.Reporter   // Reporter collects warnings instead of printing them with Print
+}
+
+// Report interface of the packages with duplicates or near duplicates in other
+// packages
+type Report struct {
+	Interface  string      `json:"interface"`  // Interface qualified interface name, E.G. "log.Logger"
+	Position   string      `json:"position"`   // Position position of the interface declaration
+	Duplicates []Duplicate `json:"duplicates"` // Duplicates interfaces with the same method set
+	Near       []Duplicate `json:"near"`       // Near interfaces which differ by one method
+}
+
+// Duplicate interface duplicating the interface of a report
+type Duplicate struct {
+	Interface string `json:"interface"`      // Interface qualified interface name, E.G. "cache.Logger"
+	Position  string `json:"position"`       // Position position of the interface declaration
+	Diff      string `json:"diff,omitempty"` // Diff method a near duplicate has, misses or has with another signature
+}
+
+// group interfaces with the same method set
+type group struct {
+	canonical *methodset.Iface             // canonical first exported interface, or the first interface if none is exported
+	ifaces    []*methodset.Iface           // ifaces interfaces in package order
+	methods   map[string]*methodset.Method // methods methods of the canonical interface keyed by name
+}
+
+// List writes the interfaces of the packages matching patterns which duplicate
+// an interface of another package, followed by the interfaces which nearly
+// duplicate it with one method more, one method less or one method with a
+// different signature, in the --format format. Interfaces of one method are
+// not near duplicates.
+func (d Dupes) List(patterns []string, output io.Writer) error {
+	format := cond.First(d.Args.Format, FormatText).(string)
+	if !cond.EqualAnyString(format, Formats...) {
+		return diag.Errorf(diag.CodeUsage, diag.Position{}, `%w "%s", expected one of %s`, ErrFormat, format, strings.Join(Formats, `, `))
+	}
+	_, ifaces, err := d.load(patterns)
+	if err != nil {
+		return err
+	}
+	var reports []Report
+	groups := d.groups(ifaces)
+	for i, g := range groups {
+		r := Report{
+			Interface:  g.canonical.String(),
+			Position:   g.canonical.Pos().String(),
+			Duplicates: []Duplicate{},
+			Near:       []Duplicate{},
+		}
+		for _, ifc := range g.ifaces {
+			if ifc == g.canonical {
+				continue
+			} else if ifc.Pkg.Dir == g.canonical.Pkg.Dir {
+				diag.Explainf(d.Print, `%s skipped, it is declared in the package of %s`, ifc, g.canonical)
+				continue
+			}
+			r.Duplicates = append(r.Duplicates, Duplicate{Interface: ifc.String(), Position: ifc.Pos().String()})
+		}
+		for _, o := range groups[i+1:] {
+			diff := near(g, o)
+			if diff == `` {
+				continue
+			}
+			for _, ifc := range o.ifaces {
+				if ifc.Pkg.Dir != g.canonical.Pkg.Dir {
+					r.Near = append(r.Near, Duplicate{Interface: ifc.String(), Position: ifc.Pos().String(), Diff: diff})
+				}
+			}
+		}
+		if len(r.Duplicates) > 0 || len(r.Near) > 0 {
+			reports = append(reports, r)
+		}
+	}
+	return write(output, format, reports)
+}
+
+// write writes the reports in format
+func write(output io.Writer, format string, reports []Report) error {
+	if format == FormatJSON {
+		if reports == nil {
+			reports = []Report{}
+		}
+		enc := json.NewEncoder(output)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(reports)
+	}
+	for _, r := range reports {
+		if len(r.Duplicates) > 0 {
+			var hint string
+			if match.Capitalized(r.Interface[strings.LastIndex(r.Interface, `.`)+1:]) {
+				hint = `, consolidate with ifaces dupes --into ` + r.Interface
+			}
+			fmt.Fprintf(output, "%s: %s has duplicates%s\n", r.Position, r.Interface, hint)
+		}
+		for _, dup := range r.Duplicates {
+			fmt.Fprintf(output, "%s: %s duplicates %s\n", dup.Position, dup.Interface, r.Interface)
+		}
+		for _, dup := range r.Near {
+			fmt.Fprintf(output, "%s: %s nearly duplicates %s, %s\n", dup.Position, dup.Interface, r.Interface, dup.Diff)
+		}
+	}
+	return nil
+}
+
+// Consolidate removes the interfaces of the packages matching patterns which
+// duplicate the interface --into, and replaces the references to them with
+// references to --into. The changed files are returned keyed by the file name,
+// files left without declarations have a nil source. A duplicate in a package
+// imported by the package of --into is kept and reported, its removal would
+// create an import cycle.
+func (d Dupes) Consolidate(patterns []string) (map[string]*bytes.Buffer, error) {
+	dirs, ifaces, err := d.load(patterns)
+	if err != nil {
+		return nil, err
+	}
+	var canonical *methodset.Iface
+	var g *group
+	for _, gr := range d.groups(ifaces) {
+		for _, ifc := range gr.ifaces {
+			if canonical == nil && methodset.Match(ifc.Pkg, ifc.Type.Name, d.Args.Into) {
+				canonical, g = ifc, gr
+			}
+		}
+	}
+	if canonical == nil {
+		return nil, diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w %s`, ErrIfaceNotFound, d.Args.Into).
+			WithFix(`name an interface with exported methods declared in the packages, E.G. Logger or log.Logger`)
+	} else if !match.Capitalized(canonical.Type.Name) {
+		return nil, diag.Errorf(diag.CodeUsage, canonical.Pos(), `%w %s`, ErrUnexported, canonical)
+	}
+	diag.Explainf(d.Print, `interface %s selected at %s`, canonical, canonical.Pos())
+	to, err := typeName(canonical)
+	if err != nil {
+		return nil, err
+	}
+	parsed := map[string]*uses.Package{}
+	for _, dir := range dirs {
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		tests, err := srcio.ReadTestDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		parsed[dir], err = uses.Parse(append(srcs, tests...))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var found bool
+	for _, ifc := range g.ifaces {
+		if ifc.Pkg.Dir == canonical.Pkg.Dir {
+			continue
+		}
+		found = true
+		from, err := typeName(ifc)
+		if err != nil {
+			return nil, err
+		}
+		if parsed[canonical.Pkg.Dir].Imports(from.Path) {
+			diag.Warn(d.Reporter, d.Print, diag.New(diag.Warning, diag.CodeUntouchable, ifc.Pos(),
+				fmt.Sprintf(`%s is kept, %s imports %s so the references can not be replaced`, ifc, to.Pkg, from.Path),
+			).WithFix(`consolidate into an interface of a package which does not import `+from.Path))
+			continue
+		}
+		for _, dir := range dirs {
+			if n := parsed[dir].ReplaceType(from, to); n > 0 {
+				diag.Explainf(d.Print, `%s: %d references to %s replaced with %s`, dir, n, from, to)
+			}
+		}
+		file, _ := parsed[ifc.Pkg.Dir].RemoveType(ifc.Type.Name)
+		diag.Explainf(d.Print, `%s removed from %s`, ifc, file)
+		if parsed[ifc.Pkg.Dir].Generated(file) {
+			diag.Warn(d.Reporter, d.Print, diag.New(diag.Warning, diag.CodeGenerated, ifc.Pos(),
+				fmt.Sprintf(`%s is removed from a generated file, it is generated again unless the directive generating it is removed`, ifc),
+			).WithFix(`remove the ifaces go:generate directive or annotation generating `+ifc.Type.Name))
+		}
+	}
+	if !found {
+		return nil, diag.Errorf(diag.CodeTypeNotFound, canonical.Pos(), `%w %s`, ErrNoDupes, canonical).
+			WithFix(`list the duplicates with ifaces dupes`)
+	}
+	files := map[string]*bytes.Buffer{}
+	for _, dir := range dirs {
+		changed, err := parsed[dir].Changed()
+		if err != nil {
+			return nil, err
+		}
+		for file, buf := range changed {
+			files[file] = buf
+		}
+	}
+	return files, nil
+}
+
+// load returns the package directories matching patterns and the interfaces
+// declared in the packages
+func (d Dupes) load(patterns []string) ([]string, []*methodset.Iface, error) {
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgs, err := methodset.Load(dirs)
+	if err != nil {
+		return nil, nil, err
+	}
+	ifaces, _ := methodset.Declarations(pkgs, func(dg *diag.Diagnostic) { diag.Warn(d.Reporter, d.Print, dg) })
+	return dirs, ifaces, nil
+}
+
+// groups groups the interfaces by method set. Interfaces with unexported
+// methods are left out, the methods can only be implemented in their package.
+func (d Dupes) groups(ifaces []*methodset.Iface) (groups []*group) {
+	byKey := map[string]*group{}
+	for _, ifc := range ifaces {
+		if m := unexported(ifc); m != `` {
+			diag.Explainf(d.Print, `%s skipped, %s is not exported`, ifc, m)
+			continue
+		}
+		var sigs []string
+		for _, m := range ifc.Methods {
+			sigs = append(sigs, m.Sig)
+		}
+		sort.Strings(sigs)
+		key := strings.Join(sigs, "\n")
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{methods: map[string]*methodset.Method{}}
+			for _, m := range ifc.Methods {
+				g.methods[m.Name] = m
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.ifaces = append(g.ifaces, ifc)
+		if g.canonical == nil || !match.Capitalized(g.canonical.Type.Name) && match.Capitalized(ifc.Type.Name) {
+			g.canonical = ifc
+		}
+	}
+	return
+}
+
+// near returns the difference of the method set of o with the method set of g
+// if they differ by one method and both have more than one method, otherwise
+// an empty string
+func near(g, o *group) string {
+	if len(g.methods) < 2 || len(o.methods) < 2 {
+		return ``
+	}
+	var diffs []string
+	for name, m := range o.methods {
+		if gm, ok := g.methods[name]; !ok {
+			diffs = append(diffs, `has `+m.Display)
+		} else if gm.Sig != m.Sig {
+			diffs = append(diffs, fmt.Sprintf(`has %s want %s`, m.Display, gm.Display))
+		}
+	}
+	for name, m := range g.methods {
+		if _, ok := o.methods[name]; !ok {
+			diffs = append(diffs, `missing `+m.Display)
+		}
+	}
+	if len(diffs) != 1 {
+		return ``
+	}
+	return diffs[0]
+}
+
+// unexported returns the name of the first unexported method of the interface
+// or an empty string
+func unexported(ifc *methodset.Iface) string {
+	for _, m := range ifc.Methods {
+		if !match.Capitalized(m.Name) {
+			return m.Name
+		}
+	}
+	return ``
+}
+
+// typeName returns the type name of the interface with the import path of its
+// package
+func typeName(ifc *methodset.Iface) (uses.TypeName, error) {
+	imp, err := paths.PathToImport(filepath.Join(ifc.Pkg.Dir, ifc.Type.File))
+	if err != nil {
+		return uses.TypeName{}, fmt.Errorf(`can not find the import path of %s: %w`, ifc.Pkg.Dir, err)
+	}
+	return uses.TypeName{Dir: ifc.Pkg.Dir, Path: imp, Pkg: ifc.Pkg.Name, Name: ifc.Type.Name}, nil
+}
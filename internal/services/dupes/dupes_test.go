@@ -0,0 +1,180 @@
+package dupes
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcs = map[string]string{
+	`go.mod`: `module example.com/app
+
+go 1.19
+`,
+	`log/log.go`: `package log
+
+import "example.com/app/cache"
+
+// Logger logs messages
+type Logger interface {
+	Infof(format string, a ...any)
+	Errorf(format string, a ...any)
+}
+
+var _ = cache.New
+`,
+	`api/api.go`: `package api
+
+// Logger logs
+type Logger interface {
+	Errorf(msg string, args ...any)
+	Infof(msg string, args ...any)
+}
+
+type Server struct {
+	Log Logger
+}
+
+func New(l Logger) *Server {
+	return &Server{Log: l}
+}
+`,
+	`cache/cache.go`: `package cache
+
+type Logger interface {
+	Infof(format string, a ...any)
+	Errorf(format string, a ...any)
+}
+
+func New(l Logger) {}
+`,
+	`db/db.go`: `package db
+
+type Logger interface {
+	Infof(format string, a ...any)
+	Errorf(format string, a ...any)
+	Debugf(format string, a ...any)
+}
+
+type logger interface {
+	Infof(format string, a ...any)
+	Errorf(format string, a ...any)
+	flush()
+}
+
+type printer interface {
+	Printf(format string, a ...any)
+	Println(a ...any)
+}
+`,
+	`queue/queue.go`: `package queue
+
+// Logger is written with interface{}
+type Logger interface {
+	Infof(format string, a ...interface{})
+	Errorf(format string, a ...interface{})
+}
+`,
+}
+
+func TestDupes_List(t *testing.T) {
+	root := testsrcs.Write(t, srcs)
+	d := Dupes{Args: &cli.Args{}}
+	out := &bytes.Buffer{}
+	err := d.List([]string{filepath.Join(root, `...`)}, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `api/api.go:4: api.Logger has duplicates, consolidate with ifaces dupes --into api.Logger
+cache/cache.go:3: cache.Logger duplicates api.Logger
+log/log.go:6: log.Logger duplicates api.Logger
+queue/queue.go:4: queue.Logger duplicates api.Logger
+db/db.go:3: db.Logger nearly duplicates api.Logger, has Debugf(format string, a ...any)
+`
+	assert.Equal(t, filepath.FromSlash(expected), strings.ReplaceAll(out.String(), root+string(filepath.Separator), ``))
+}
+
+func TestDupes_List_JSON(t *testing.T) {
+	root := testsrcs.Write(t, srcs)
+	d := Dupes{Args: &cli.Args{Format: FormatJSON}}
+	out := &bytes.Buffer{}
+	err := d.List([]string{filepath.Join(root, `...`)}, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var reports []Report
+	err = json.Unmarshal([]byte(strings.ReplaceAll(out.String(), root+string(filepath.Separator), ``)), &reports)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := []Report{{
+		Interface: `api.Logger`,
+		Position:  filepath.Join(`api`, `api.go`) + `:4`,
+		Duplicates: []Duplicate{
+			{Interface: `cache.Logger`, Position: filepath.Join(`cache`, `cache.go`) + `:3`},
+			{Interface: `log.Logger`, Position: filepath.Join(`log`, `log.go`) + `:6`},
+			{Interface: `queue.Logger`, Position: filepath.Join(`queue`, `queue.go`) + `:4`},
+		},
+		Near: []Duplicate{
+			{Interface: `db.Logger`, Position: filepath.Join(`db`, `db.go`) + `:3`, Diff: `has Debugf(format string, a ...any)`},
+		},
+	}}
+	assert.Equal(t, expected, reports)
+
+	d.Args.Format = `yaml`
+	err = d.List([]string{filepath.Join(root, `...`)}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFormat)
+}
+
+func TestDupes_Consolidate(t *testing.T) {
+	root := testsrcs.Write(t, srcs)
+	reporter := &diag.Reporter{}
+	d := Dupes{Args: &cli.Args{Into: `log.Logger`}, Reporter: reporter}
+	files, err := d.Consolidate([]string{filepath.Join(root, `...`)})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, files, 2) {
+		t.FailNow()
+	}
+	assert.Nil(t, files[filepath.Join(root, `queue`, `queue.go`)])
+	expected := `package api
+
+import "example.com/app/log"
+
+type Server struct {
+	Log log.Logger
+}
+
+func New(l log.Logger) *Server {
+	return &Server{Log: l}
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(root, `api`, `api.go`)].String())
+	if assert.Len(t, reporter.List(), 1) {
+		assert.Equal(t, diag.CodeUntouchable, reporter.List()[0].Code)
+		assert.Contains(t, reporter.List()[0].Message, `cache.Logger is kept`)
+	}
+}
+
+func TestDupes_Consolidate_NotFound(t *testing.T) {
+	root := testsrcs.Write(t, srcs)
+	patterns := []string{filepath.Join(root, `...`)}
+	for into, expected := range map[string]error{
+		`Missing`:    ErrIfaceNotFound,
+		`db.Logger`:  ErrNoDupes,
+		`db.logger`:  ErrIfaceNotFound,
+		`db.printer`: ErrUnexported,
+	} {
+		d := Dupes{Args: &cli.Args{Into: into}}
+		_, err := d.Consolidate(patterns)
+		assert.ErrorIs(t, err, expected, into)
+	}
+}
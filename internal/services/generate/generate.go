@@ -6,11 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/dexterp/ifaces/internal/resources/addimports"
 	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
 	"github.com/dexterp/ifaces/internal/resources/parser"
 	"github.com/dexterp/ifaces/internal/resources/paths"
 	"github.com/dexterp/ifaces/internal/resources/print"
@@ -18,6 +23,7 @@ import (
 	"github.com/dexterp/ifaces/internal/resources/srcio"
 	"github.com/dexterp/ifaces/internal/resources/stringx"
 	"github.com/dexterp/ifaces/internal/resources/tdata"
+	"github.com/dexterp/ifaces/internal/resources/tmplfuncs"
 	"github.com/dexterp/ifaces/internal/resources/types"
 )
 
@@ -25,130 +31,309 @@ import (
 
 // Generate interface generator
 type Generate struct {
-	Type      bool             // Type type subcommand
-	Method    bool             // Method method sub command
-	Comment   string           // Comment comment at the top of the file
-	Iface     string           // Iface explicitly set interface name
-	Module    string           // Module name of module to scan instead of scanning the file system
-	NoFDoc    bool             // NoFDoc omit copying function documentation
-	NoTDoc    bool             // NoTDoc omit copying type documentation
-	Pkg       string           // Pkg package name
-	Post      string           // Post postfix to interface name
-	Pre       string           // Pre prefix to interface name
-	Print     print.PrintIface // Print handler
-	Struct    bool             // Struct generate an interface for all structs
-	TDoc      string           // TDoc type document
-	MatchType string           // MatchType match types
-	MatchFunc string           // MatchFunc match receivers
-	targets   map[string]*target
+	Type        bool             // Type type subcommand
+	Method      bool             // Method method sub command
+	Build       string           // Build build constraint of the output files. Defaults to the constraints of the source files.
+	Comment     string           // Comment comment at the top of the file
+	Config      string           // Config path to a YAML or JSON config file, see Config
+	DocWidth    int              // DocWidth wrap width of documents, tdata.DefaultWidth if zero. A negative width keeps the line breaks.
+	Common      bool             // Common generate a single interface with the methods common to all matched types
+	Exclude     []string         // Exclude omit methods matching any of the patterns
+	ExtraCmt    string           // ExtraCmt comment added after the header
+	Format      string           // Format output format, FormatGo if empty or FormatJSON
+	FDoc        string           // FDoc function document template, see DocData
+	FromFiles   []string         // FromFiles only use methods from files matching any of the patterns
+	HeaderFile  string           // HeaderFile file with a header, E.G. a license, added after the top comment
+	Generalize  bool             // Generalize infer a type parameter for common methods whose signatures differ by one set of types
+	Iface       string           // Iface explicitly set interface name
+	Map         []string         // Map type substitutions of the form "from=to", E.G. "*os.File=io.ReadWriteCloser"
+	Include     []string         // Include only use methods matching any of the patterns
+	Module      string           // Module name of module to scan instead of scanning the file system
+	NoFDoc      bool             // NoFDoc omit copying function documentation
+	NoTDoc      bool             // NoTDoc omit copying type documentation
+	Parse       ParseFunc        // Parse parses the sources. Defaults to parser.ParseFiles.
+	Pkg         string           // Pkg package name
+	Plugin      string           // Plugin executable the parsed interfaces are sent to instead of generating Go source, see the plugin package
+	PluginParam string           // PluginParam parameter passed to the plugin
+	Post        string           // Post postfix to interface name
+	Pre         string           // Pre prefix to interface name
+	RoleSplit   bool             // RoleSplit split the methods of a type into role interfaces
+	Roles       []string         // Roles role rules, E.G. "Reader=Get*,List*". Defaults to DefaultRoles.
+	Print       print.PrintIface // Print handler
+	Reporter    *diag.Reporter   // Reporter collects warnings instead of printing them with Print
+	Struct      bool             // Struct generate an interface for all structs
+	TDoc        string           // TDoc type document template, see DocData
+	MatchType   string           // MatchType match types
+	TypeName    string           // TypeName selects the type declared with the name, exported or not, instead of MatchType
+	MatchFunc   string           // MatchFunc match receivers
+	OutTmpl     string           // OutTmpl file name template used to write one file per type
+	Template    string           // Template path to a user supplied output template
+	Transitive  bool             // Transitive generate interfaces for the concrete types returned by methods and use them in the signatures
+	targets     map[string]*target
+	outfile     string
+	outTmpl     *template.Template
+	roles       []role
+	tmpl        *template.Template
+	current     func(file string) (*bytes.Buffer, error)
+	builds      map[string]string
+	header      string
+	fdocTmpl    *template.Template
+	tdocTmpl    *template.Template
+	transitive  map[string]bool
+	typeMap     *parser.Substitution
+	mapImports  []mapImport
 }
 
 //go:embed generate.gotmpl
 var gentmpl string
 
-var ErrorNoSourceFile = errors.New(`no source files processed`)
+var (
+	ErrorNoSourceFile = errors.New(`no source files processed`)
+	ErrorNoOutTmpl    = errors.New(`no output file template`)
+	ErrFormat         = errors.New(`unknown output format`)
+	ErrFormatAppend   = errors.New(`can not append to json output`)
+	ErrRecvNotFound   = errors.New(`could not match receiver method`)
+	ErrTypeNotFound   = errors.New(`could not match type`)
+)
 
 // Generate generate interfaces source code for the gen sub command.
 func (g *Generate) Generate(srcs []srcio.Source, current *bytes.Buffer, outfile string, output io.Writer) error {
-	g.init()
-	pkg, err := g.setOutputPackage(g.Pkg, outfile)
+	err := g.init(outfile, func(string) (*bytes.Buffer, error) { return current, nil })
 	if err != nil {
 		return err
 	}
-	err = g.parse(srcs, current, outfile, pkg)
+	// The output file is always created even if no interfaces are found.
+	_, err = g.getOrMakeTarget(outfile, ``)
 	if err != nil {
 		return err
 	}
-
-	// TODO - move this exported import to parser
-	var importValue *parser.Import
-	if srcs != nil {
-		i, err := paths.PathToImport(srcs[0].File)
-		if err == nil {
-			importValue = &parser.Import{
-				Path: i,
-			}
-		}
+	err = g.parseSrc(srcs)
+	if err != nil {
+		return err
 	}
-	for _, t := range g.targets {
-		templateOut := &bytes.Buffer{}
-		err = applyTemplate(templateOut, t.tdata)
+	for _, t := range g.sortedTargets() {
+		err = g.render(t, srcs, output)
 		if err != nil {
 			return err
 		}
-		importsOut := &bytes.Buffer{}
-		importsList := []addimports.Import{}
-		if t.exported && importValue != nil {
-			importsList = append(importsList, addimports.NewImport(importValue.Name, importValue.Path))
-		}
-		for i := range t.imports {
-			importsList = append(importsList, addimports.NewImport(i.Name, i.Path))
+	}
+	return nil
+}
+
+// GenerateFiles generate interfaces source code with one output file per type.
+// File names are created by executing the OutTmpl template. current returns
+// the contents of a previously generated file and may be nil. The generated
+// source is returned in a map keyed by the file name.
+func (g *Generate) GenerateFiles(srcs []srcio.Source, current func(file string) (*bytes.Buffer, error)) (map[string]*bytes.Buffer, error) {
+	if g.OutTmpl == `` {
+		return nil, ErrorNoOutTmpl
+	}
+	err := g.init(``, current)
+	if err != nil {
+		return nil, err
+	}
+	err = g.parseSrc(srcs)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*bytes.Buffer{}
+	for _, t := range g.sortedTargets() {
+		out := &bytes.Buffer{}
+		err = g.render(t, srcs, out)
+		if err != nil {
+			return nil, err
 		}
-		err = addimports.AddImports(outfile, templateOut, importsList, importsOut)
+		files[t.file] = out
+	}
+	return files, nil
+}
+
+func (g *Generate) init(outfile string, current func(file string) (*bytes.Buffer, error)) (err error) {
+	g.targets = map[string]*target{}
+	g.outfile = outfile
+	g.current = current
+	g.outTmpl = nil
+	if g.OutTmpl != `` {
+		g.outTmpl, err = template.New(`out`).Funcs(tmplfuncs.FuncMap()).Parse(g.OutTmpl)
 		if err != nil {
-			return err
+			return fmt.Errorf(`invalid output file template: %w`, err)
 		}
-		finalSrc := &bytes.Buffer{}
-		err = srcformat.Format(outfile, importsOut, finalSrc)
+	}
+	switch g.Format {
+	case ``, FormatGo, FormatJSON:
+	default:
+		return fmt.Errorf(`%w "%s"`, ErrFormat, g.Format)
+	}
+	g.builds = nil
+	g.header, err = loadHeader(g.HeaderFile, g.ExtraCmt)
+	if err != nil {
+		return err
+	}
+	g.fdocTmpl, err = parseDocTmpl(`fdoc`, g.FDoc)
+	if err != nil {
+		return err
+	}
+	g.tdocTmpl, err = parseDocTmpl(`tdoc`, g.TDoc)
+	if err != nil {
+		return err
+	}
+	g.typeMap, g.mapImports, err = g.loadTypeMap()
+	if err != nil {
+		return err
+	}
+	g.roles = nil
+	g.transitive = nil
+	if g.RoleSplit {
+		rules := g.Roles
+		if len(rules) == 0 {
+			rules = DefaultRoles
+		}
+		g.roles, err = parseRoles(rules)
 		if err != nil {
 			return err
 		}
-		_, err = io.Copy(output, finalSrc)
 	}
+	g.tmpl, err = loadTemplate(g.Template)
 	return err
 }
 
-func (g *Generate) init() {
-	g.targets = map[string]*target{}
-}
-
-func (g *Generate) parse(srcs []srcio.Source, current *bytes.Buffer, outfile string, pkg string) (err error) {
-	err = g.parseTargetSrc(outfile, current)
+// render writes the source for a single target to output.
+func (g *Generate) render(t *target, srcs []srcio.Source, output io.Writer) error {
+	importsList, err := g.prepare(t, srcs)
 	if err != nil {
 		return err
 	}
-	err = g.parseSrc(srcs, pkg)
+	if g.Format == FormatJSON {
+		return renderJSON(t, output)
+	}
+	templateOut := &bytes.Buffer{}
+	err = g.tmpl.Execute(templateOut, t.tdata)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (g *Generate) parseSrc(srcs []srcio.Source, pkg string) (err error) {
-	p, err := parser.ParseFiles(srcs)
+	// User supplied templates can generate files other than Go source.
+	if ext := filepath.Ext(t.file); ext != `` && ext != `.go` {
+		_, err = io.Copy(output, templateOut)
+		return err
+	}
+	importsOut := &bytes.Buffer{}
+	err = addimports.AddImports(t.file, templateOut, importsList, importsOut)
 	if err != nil {
 		return err
 	}
-	for _, t := range g.targets {
-		t.tdata.Pkg = cond.First(pkg, p.Package).(string)
-		goGenerateSrc := firstWithLine(srcs...)
-		err = g.populateTypeInterfaces(t, goGenerateSrc, p)
-		if err != nil {
-			return err
+	finalSrc := &bytes.Buffer{}
+	err = srcformat.Format(t.file, importsOut, finalSrc)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(output, finalSrc)
+	return err
+}
+
+// prepare completes the template data of a target with the imports, header
+// and build constraint. The imports to add to the generated source are
+// returned.
+func (g *Generate) prepare(t *target, srcs []srcio.Source) ([]addimports.Import, error) {
+	// TODO - move this exported import to parser
+	var importValue *parser.Import
+	if srcs != nil {
+		i, err := paths.PathToImport(srcs[0].File)
+		if err == nil {
+			importValue = &parser.Import{
+				Path: i,
+			}
 		}
-		err = g.populateRecvInterfaces(t, goGenerateSrc, p)
-		if err != nil {
-			return err
+	}
+	// the same import is parsed once per source file, keep one of each
+	seen := map[tdata.Import]bool{}
+	t.tdata.Imports = nil
+	add := func(i *parser.Import) {
+		imp := tdata.Import{Name: i.Name, Path: i.Path}
+		if !seen[imp] {
+			seen[imp] = true
+			t.tdata.Imports = append(t.tdata.Imports, imp)
 		}
 	}
+	if t.exported && importValue != nil {
+		add(importValue)
+	}
+	for i := range t.imports {
+		add(i)
+	}
+	sort.Slice(t.tdata.Imports, func(i, j int) bool {
+		a, b := t.tdata.Imports[i], t.tdata.Imports[j]
+		return a.Path < b.Path || a.Path == b.Path && a.Name < b.Name
+	})
+	importsList := []addimports.Import{}
+	for _, i := range t.tdata.Imports {
+		importsList = append(importsList, addimports.NewImport(i.Name, i.Path))
+		g.debugf(`%s: import %s`, t.file, strings.TrimSpace(i.Name+` "`+i.Path+`"`))
+	}
+	t.tdata.Header = g.header
+	var err error
+	t.tdata.Build, err = g.buildFor(t)
+	if err != nil {
+		return nil, err
+	}
+	return importsList, nil
+}
+
+func (g *Generate) sortedTargets() (targets []*target) {
+	for _, t := range g.targets {
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].file < targets[j].file
+	})
+	return
+}
+
+func (g *Generate) parseSrc(srcs []srcio.Source) (err error) {
+	parse := g.Parse
+	if parse == nil {
+		parse = parser.ParseFiles
+	}
+	p, err := parse(srcs)
+	if err != nil {
+		return err
+	}
+	g.builds = p.Builds
+	goGenerateSrc := firstWithLine(srcs...)
+	g.explainParsed(srcs, p, goGenerateSrc)
+	err = g.populateTypeInterfaces(goGenerateSrc, p)
+	if err != nil {
+		return err
+	}
+	err = g.populateRecvInterfaces(goGenerateSrc, p)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
 // parseTargetSrc scans any previously generated source before any additions.
-func (g *Generate) parseTargetSrc(path string, src *bytes.Buffer) (err error) {
+func (g *Generate) parseTargetSrc(t *target) (err error) {
 	// No need to parse source if file is empty or dose not exists
-	if src.Len() == 0 {
-		g.getOrMakeTarget(path, nil)
+	if t.src == nil || t.src.Len() == 0 {
 		return nil
+	} else if g.Format == FormatJSON {
+		return fmt.Errorf(`%s: %w`, t.file, ErrFormatAppend)
 	}
-	p, err := parser.Parse(path, src, 0)
+	p, err := parser.Parse(t.file, t.src, 0)
 	if err != nil {
 		return fmt.Errorf(`error parsing target source: %w`, err)
 	}
 	q := parser.NewQuery(p)
-	t := g.getOrMakeTarget(path, p.Imports)
+	t.build = p.Builds[filepath.Base(t.file)]
+	for _, i := range p.Imports {
+		t.imports[i] = struct{}{}
+	}
 	for _, typ := range q.GetTypesByType(types.INTERFACE) {
-		iface, finish := makeInterface(t.tdata, typ.Name, typ.Doc, false)
+		iface, finish := makeInterface(t.tdata, typ.Name, typ.Doc, false, g.docWidth())
+		iface.TypeParams = typ.TypeParams
+		for _, e := range typ.Embeds {
+			iface.Embed(e)
+		}
 		methods := q.GetIfaceMethods(typ.Name)
 		err = g.addIfaceMethods(iface, methods)
 		if err != nil {
@@ -162,7 +347,7 @@ func (g *Generate) parseTargetSrc(path string, src *bytes.Buffer) (err error) {
 	return
 }
 
-func (g *Generate) populateTypeInterfaces(t *target, src *srcio.Source, p *parser.Parser) (err error) {
+func (g *Generate) populateTypeInterfaces(src *srcio.Source, p *parser.Parser) (err error) {
 	if !g.Type && !g.Struct {
 		return
 	}
@@ -172,28 +357,78 @@ func (g *Generate) populateTypeInterfaces(t *target, src *srcio.Source, p *parse
 		name = g.Pre + g.Iface + g.Post
 	}
 	types := g.getTypeList(p, src)
-	q := parser.NewQuery(p)
+	if len(types) == 0 && !g.Struct {
+		return g.typeNotFound(src)
+	}
+	if (g.Common || g.Generalize) && ifaceDefined && len(types) > 0 {
+		return g.populateCommonInterface(types, name, p)
+	}
+	names := map[string]string{}
+	if g.Transitive {
+		types, names, err = g.transitiveTypes(p, types, func(typ string) string {
+			return cond.First(name, g.Pre+typ+g.Post).(string)
+		})
+		if err != nil {
+			return err
+		}
+	}
 	for _, typ := range types {
-		if !ifaceDefined {
+		if n, ok := names[typ.Name]; ok {
+			name = n
+		} else if !ifaceDefined {
 			name = g.Pre + typ.Name + g.Post
 		}
-		doc := g.TDoc
-		if doc == `` {
-			doc = typ.Doc
+		if typ.Directives.Ignore(name) {
+			g.debugf(`%s: type %s skipped, ifaces:ignore directive`, name, typ.Name)
+			continue
+		}
+		methods, err := g.typeMethods(p, typ, name)
+		if err != nil {
+			return err
 		}
-		iface, finish := makeInterface(t.tdata, name, doc, g.NoTDoc)
 		recvs := &[]*parser.Method{}
-		*recvs = q.GetRecvsByType(typ.Name)
+		*recvs = g.selectMethods(methods, name)
+		if g.Transitive {
+			*recvs, err = substituteTypes(*recvs, names)
+			if err != nil {
+				return err
+			}
+		}
+		if len(*recvs) == 0 {
+			g.debugf(`%s: interface skipped, type %s has no methods to add`, name, typ.Name)
+			continue
+		}
+		t, err := g.targetFor(typ.Name, name, p.Package)
+		if err != nil {
+			return err
+		}
+		if g.RoleSplit {
+			err = g.populateRoleInterfaces(t, typ, name, *recvs, p.Package)
+			if err != nil {
+				return err
+			}
+			err = g.addPrefixImports(t, p.Imports, *recvs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		iface, finish := makeInterface(t.tdata, name, g.typeDoc(name, typ.Name, typ.Doc), g.NoTDoc, g.docWidth())
+		setSource(iface, typ.Name, typ.File, typ.Line)
 		addPackage(recvs, p.Package, t.tdata.Pkg)
-		err = addRecvMethods(iface, recvs, p.Package, t.tdata.Pkg, g.NoFDoc)
+		err = g.addRecvMethods(iface, recvs, p.Package, t.tdata.Pkg)
+		if err != nil {
+			return err
+		}
+		err = g.addPrefixImports(t, p.Imports, *recvs)
 		if err != nil {
 			return err
 		}
-		g.addPrefixImports(p.Imports, *recvs)
 		if isExported(*recvs...) {
 			t.exported = true
 		}
 		if iface.Methods == nil {
+			g.debugf(`%s: interface skipped, no methods added`, name)
 			continue
 		}
 		err = finish()
@@ -204,8 +439,7 @@ func (g *Generate) populateTypeInterfaces(t *target, src *srcio.Source, p *parse
 	return nil
 }
 
-func (g *Generate) populateRecvInterfaces(t *target, src *srcio.Source, p *parser.Parser) (err error) {
-	data := t.tdata
+func (g *Generate) populateRecvInterfaces(src *srcio.Source, p *parser.Parser) (err error) {
 	if !g.Method {
 		return
 	}
@@ -215,64 +449,132 @@ func (g *Generate) populateRecvInterfaces(t *target, src *srcio.Source, p *parse
 		name = g.Pre + g.Iface + g.Post
 	}
 	recvs := g.getRecvList(src, p)
-	q := parser.NewQuery(p)
+	if len(recvs) == 0 {
+		return g.recvNotFound(src)
+	}
 	for _, recv := range recvs {
-		typ := q.GetTypeByName(recv.TypeName)
 		if !ifaceDefined {
-			name = g.Pre + typ.Name + g.Post
+			name = g.Pre + recv.TypeName + g.Post
+		}
+		recv = g.mapMethod(recv, name)
+		t, err := g.targetFor(recv.TypeName, name, p.Package)
+		if err != nil {
+			return err
+		}
+		doc := ``
+		if g.tdocTmpl != nil {
+			doc = g.typeDoc(name, recv.TypeName, ``)
 		}
-		doc := g.TDoc
-		if doc == `` {
-			doc = g.TDoc
+		iface, finish := makeInterface(t.tdata, name, doc, g.NoTDoc, g.docWidth())
+		if typ := parser.NewQuery(p).GetTypeByName(recv.TypeName); typ != nil {
+			setSource(iface, typ.Name, typ.File, typ.Line)
 		}
-		iface, finish := makeInterface(data, name, doc, g.NoTDoc)
-		m := tdata.NewMethod(recv.Name, recv.Signature(), recv.Doc, g.NoFDoc)
-		err := iface.Add(m)
-		if err != nil && err != tdata.ErrorDuplicateMethod {
+		err = g.addMethod(iface, newMethod(recv, g.methodDoc(recv), g.NoFDoc, g.docWidth()))
+		if err != nil {
 			return err
 		}
 		err = finish()
 		if err != nil {
 			return err
 		}
-	}
-	g.addPrefixImports(p.Imports, recvs)
-	if isExported(recvs...) {
-		t.exported = true
+		err = g.addPrefixImports(t, p.Imports, []*parser.Method{recv})
+		if err != nil {
+			return err
+		}
+		if isExported(recv) {
+			t.exported = true
+		}
 	}
 	return nil
 }
 
-func (g *Generate) getOrMakeTarget(file string, imports []*parser.Import) *target {
+// getOrMakeTarget returns the target for file. New targets are loaded with
+// any previously generated source.
+func (g *Generate) getOrMakeTarget(file, parsedPkg string) (*target, error) {
 	if t, ok := g.targets[file]; ok {
-		return t
+		t.tdata.Pkg = cond.First(t.pkg, parsedPkg).(string)
+		return t, nil
+	}
+	pkg, err := g.setOutputPackage(g.Pkg, file)
+	if err != nil {
+		return nil, err
 	}
 
 	// Output target
 	t := &target{
 		file:    file,
+		pkg:     pkg,
 		imports: map[*parser.Import]any{},
 		tdata: &tdata.TData{
 			Comment: g.Comment,
 			NoFDoc:  g.NoFDoc,
 			NoTDoc:  g.NoTDoc,
+			Pkg:     cond.First(pkg, parsedPkg).(string),
 			Post:    g.Post,
 			Pre:     g.Pre,
 		},
 	}
-	for _, i := range imports {
-		t.imports[i] = true
+	if g.current != nil {
+		t.src, err = g.current(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = g.parseTargetSrc(t)
+	if err != nil {
+		return nil, err
 	}
 	g.targets[file] = t
-	return t
+	return t, nil
+}
+
+// targetFor returns the target for an interface generated from typ. Unless an
+// output file template is set this is the output file.
+func (g *Generate) targetFor(typ, iface, parsedPkg string) (*target, error) {
+	if g.outTmpl == nil {
+		return g.getOrMakeTarget(g.outfile, parsedPkg)
+	}
+	buf := &bytes.Buffer{}
+	err := g.outTmpl.Execute(buf, outTmplData{
+		Type:  typ,
+		Iface: iface,
+		Pkg:   parsedPkg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(`can not create file name from output template: %w`, err)
+	}
+	if buf.Len() == 0 {
+		return nil, fmt.Errorf(`output template created an empty file name for type %s`, typ)
+	}
+	return g.getOrMakeTarget(buf.String(), parsedPkg)
 }
 
-func (g Generate) getRecvList(src *srcio.Source, p *parser.Parser) (r []*parser.Method) {
+// selectMethods returns copies of the methods to add to iface. Methods are
+// removed by ignore directives and the include, exclude and from files
+// patterns. Copies are returned as parsed sources can be shared between
+// generators.
+func (g *Generate) selectMethods(methods []*parser.Method, iface string) (out []*parser.Method) {
+	for _, m := range methods {
+		if reason := g.excludeReason(m, iface); reason != `` {
+			g.debugf(`%s: %s.%s excluded, %s`, iface, m.TypeName, m.Name, reason)
+			continue
+		}
+		g.debugf(`%s: %s.%s included`, iface, m.TypeName, m.Name)
+		c := *g.mapMethod(m, iface)
+		out = append(out, &c)
+	}
+	return
+}
+
+func (g *Generate) getRecvList(src *srcio.Source, p *parser.Parser) (r []*parser.Method) {
 	q := parser.NewQuery(p)
 	if g.MatchFunc != `` {
 		recv := q.GetRecvByTypeMethod(g.MatchType, g.MatchFunc)
 		if recv != nil {
+			g.debugf(`method %s.%s selected at %s:%d`, recv.TypeName, recv.Name, recv.File, recv.Line)
 			r = append(r, recv)
+		} else {
+			g.debugf(`no method %s.%s found`, g.MatchType, g.MatchFunc)
 		}
 	}
 	if len(r) == 0 && src != nil {
@@ -281,26 +583,41 @@ func (g Generate) getRecvList(src *srcio.Source, p *parser.Parser) (r []*parser.
 		if line > 0 {
 			recv := q.GetRecvByLine(file, line)
 			if recv != nil {
+				g.debugf(`method %s.%s selected at %s:%d, the first method after the directive`, recv.TypeName, recv.Name, recv.File, recv.Line)
 				r = append(r, recv)
+			} else {
+				g.debugf(`no method found after the directive at %s:%d`, file, line)
 			}
 		}
 	}
 	return
 }
 
-func (g Generate) getTypeList(p *parser.Parser, src *srcio.Source) (t []parser.Type) {
+func (g *Generate) getTypeList(p *parser.Parser, src *srcio.Source) (t []parser.Type) {
 	q := parser.NewQuery(p)
 	if g.Struct {
-		t = append(t, q.GetTypesByType(types.STRUCT)...)
-	}
-	if g.MatchType != `` {
-		t = append(t, q.GetTypeByPattern(g.MatchType)...)
+		structs := q.GetTypesByType(types.STRUCT)
+		g.explainTypes(`structs`, structs)
+		t = append(t, structs...)
+	}
+	if g.TypeName != `` {
+		named := []parser.Type{}
+		if typ := q.GetTypeByName(g.TypeName); typ != nil {
+			named = append(named, *typ)
+		}
+		g.explainTypes(`types named `+g.TypeName, named)
+		t = append(t, named...)
+	} else if g.MatchType != `` {
+		matched := q.GetTypeByPattern(g.MatchType)
+		g.explainTypes(`exported types matching `+g.MatchType, matched)
+		t = append(t, matched...)
 	}
 	if src != nil {
 		file := src.File
 		line := src.Line
 		if len(t) == 0 && line > 0 {
 			typ := q.GetTypeByLine(file, line)
+			g.explainTypeByLine(q, file, line, typ)
 			if typ != nil {
 				t = append(t, *typ)
 			}
@@ -324,7 +641,7 @@ func (g Generate) setOutputPackage(pkgCli, path string) (string, error) {
 	pkgCli = filepath.Base(d)
 	m := stringx.ExPkg(pkgCli)
 	if m == `` {
-		g.Print.HasFatalf(`invalid package name "%s" determined from path %s`, path, pkgCli)
+		return ``, fmt.Errorf(`invalid package name "%s" determined from path %s`, pkgCli, path)
 	}
 	pkgCli = m
 	return pkgCli, nil
@@ -332,14 +649,9 @@ func (g Generate) setOutputPackage(pkgCli, path string) (string, error) {
 
 func (g *Generate) addIfaceMethods(iface *tdata.Interface, methods []*parser.Method) error {
 	for _, method := range methods {
-		m := tdata.NewMethod(method.Name, method.Signature(), method.Doc, g.NoFDoc)
-		err := iface.Add(m)
+		err := g.addMethod(iface, newMethod(method, method.Doc, g.NoFDoc, g.docWidth()))
 		if err != nil {
-			if err == tdata.ErrorDuplicateMethod {
-				continue
-			} else {
-				return fmt.Errorf(`can not add method: %w`, err)
-			}
+			return err
 		}
 	}
 	return nil
@@ -353,60 +665,69 @@ func addPackage(recvs *[]*parser.Method, parsedPkg, targetPkg string) {
 	}
 }
 
-func addRecvMethods(iface *tdata.Interface, recvs *[]*parser.Method, parsedPkg, targetPkg string, noFuncDoc bool) error {
+func (g *Generate) addRecvMethods(iface *tdata.Interface, recvs *[]*parser.Method, parsedPkg, targetPkg string) error {
 	for _, recv := range *recvs {
 		if targetPkg != parsedPkg {
 			recv.Pkg = parsedPkg
 		}
-		m := tdata.NewMethod(recv.Name, recv.Signature(), recv.Doc, noFuncDoc)
-		err := iface.Add(m)
+		err := g.addMethod(iface, newMethod(recv, g.methodDoc(recv), g.NoFDoc, g.docWidth()))
 		if err != nil {
-			if err == tdata.ErrorDuplicateMethod {
-				continue
-			} else {
-				return fmt.Errorf(`can not add method: %w`, err)
-			}
+			return err
 		}
 	}
 	return nil
 }
 
-func applyTemplate(current *bytes.Buffer, data *tdata.TData) error {
-	t, err := template.New("generate.gotmpl").Parse(gentmpl)
-	if err != nil {
-		return err
-	}
-	err = t.Execute(current, data)
-	if err != nil {
-		return err
+// loadTemplate parses the output template in file. The embedded template is
+// used if file is empty.
+func loadTemplate(file string) (*template.Template, error) {
+	name := `generate.gotmpl`
+	text := gentmpl
+	if file != `` {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf(`can not read template: %w`, err)
+		}
+		name = filepath.Base(file)
+		text = string(b)
 	}
-	return nil
+	return template.New(name).Funcs(tmplfuncs.FuncMap()).Parse(text)
 }
 
-func (g *Generate) addPrefixImports(parsed []*parser.Import, recvs []*parser.Method) {
-	if parsed == nil || recvs == nil {
-		return
+func (g *Generate) addPrefixImports(t *target, parsed []*parser.Import, recvs []*parser.Method) error {
+	err := g.addMapImports(t, parsed, recvs)
+	if err != nil || parsed == nil || recvs == nil {
+		return err
 	}
-	for _, t := range g.targets {
-		for _, r := range recvs {
-			if r.Prefixes == nil {
+	for _, r := range recvs {
+		if r.Prefixes == nil {
+			continue
+		}
+		for _, pi := range parsed {
+			if cond.EqualAnyString(pi.Name, `_`, `.`) {
+				continue
+			} else if pi.Name != `` && cond.EqualAnyString(pi.Name, r.Prefixes...) {
+				t.imports[pi] = struct{}{}
 				continue
 			}
-			for _, pi := range parsed {
-				if cond.EqualAnyString(pi.Name, `_`, `.`) {
-					continue
-				} else if pi.Name != `` && cond.EqualAnyString(pi.Name, r.Prefixes...) {
-					t.imports[pi] = struct{}{}
-					continue
-				}
-				m := stringx.ExPkgPath(pi.Path)
-				if m != `` && cond.EqualAnyString(m, r.Prefixes...) {
-					t.imports[pi] = struct{}{}
-					continue
-				}
+			m := stringx.ExPkgPath(pi.Path)
+			if m != `` && cond.EqualAnyString(m, r.Prefixes...) {
+				t.imports[pi] = struct{}{}
+				continue
 			}
 		}
 	}
+	return nil
+}
+
+// matchAny returns true if str matches any of the wildcard patterns
+func matchAny(str string, patterns []string) bool {
+	for _, p := range patterns {
+		if match.Match(str, p) {
+			return true
+		}
+	}
+	return false
 }
 
 func firstWithLine(srcs ...srcio.Source) *srcio.Source {
@@ -418,12 +739,12 @@ func firstWithLine(srcs ...srcio.Source) *srcio.Source {
 	return nil
 }
 
-func makeInterface(data *tdata.TData, name, doc string, noTDoc bool) (iface *tdata.Interface, finish func() error) {
+func makeInterface(data *tdata.TData, name, doc string, noTDoc bool, width int) (iface *tdata.Interface, finish func() error) {
 	finish = func() error { return nil }
 	iface = data.Get(name)
 	if iface == nil {
 		iface = &tdata.Interface{
-			Type: tdata.NewType(name, doc, noTDoc),
+			Type: tdata.NewType(name, doc, noTDoc, width),
 		}
 		finish = func() error {
 			return data.Add(iface)
@@ -432,6 +753,44 @@ func makeInterface(data *tdata.TData, name, doc string, noTDoc bool) (iface *tda
 	return
 }
 
+// newMethod creates the template data for a parsed method.
+func newMethod(method *parser.Method, doc string, noFDoc bool, width int) *tdata.Method {
+	m := tdata.NewMethod(method.Name, method.Signature(), doc, noFDoc, width)
+	m.Params = newParams(method.Params())
+	m.Results = newParams(method.Results())
+	m.Source = method.TypeName
+	m.File = method.File
+	m.Line = method.Line
+	if method.Pointer {
+		m.Recv = `pointer`
+	} else if method.Receiver {
+		m.Recv = `value`
+	}
+	return m
+}
+
+func newParams(params []parser.Param) (out []tdata.Param) {
+	for _, p := range params {
+		out = append(out, tdata.Param{
+			Name:     p.Name,
+			Type:     p.Type,
+			Variadic: p.Variadic,
+			Pkg:      p.Pkg,
+		})
+	}
+	return
+}
+
+// setSource records the type an interface is generated from.
+func setSource(iface *tdata.Interface, typ, file string, line int) {
+	if iface.Source != `` {
+		return
+	}
+	iface.Source = typ
+	iface.File = file
+	iface.Line = line
+}
+
 func isExported(recvs ...*parser.Method) bool {
 	for _, r := range recvs {
 		if r.NeedsImport() {
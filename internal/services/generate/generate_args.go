@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+)
+
+// FromArgs creates a generator from the command line options. Print and
+// Reporter are left for the caller to set.
+func FromArgs(args *cli.Args) *Generate {
+	return &Generate{
+		Type:        args.CmdType,
+		Method:      args.CmdFunc,
+		Build:       args.Build,
+		Comment:     args.Cmt,
+		Common:      args.Common,
+		Config:      args.Config,
+		DocWidth:    args.DocWidth,
+		Exclude:     stringx.SplitList(args.Exclude),
+		ExtraCmt:    args.ExtraCmt,
+		FDoc:        args.FDoc,
+		Format:      args.Format,
+		FromFiles:   stringx.SplitList(args.FromFiles),
+		Generalize:  args.Generalize,
+		HeaderFile:  args.HeaderFile,
+		Iface:       args.Iface,
+		Include:     stringx.SplitList(args.Include),
+		Map:         args.Map,
+		MatchFunc:   args.MatchFunc,
+		MatchType:   args.MatchType,
+		Module:      args.Module,
+		NoFDoc:      args.NoFDoc,
+		NoTDoc:      args.NoTDoc,
+		OutTmpl:     args.OutTmpl,
+		Pkg:         args.Pkg,
+		Plugin:      args.Plugin,
+		PluginParam: args.PluginParam,
+		Post:        args.Post,
+		Pre:         args.Pre,
+		RoleSplit:   args.RoleSplit,
+		Roles:       splitRoles(args.RoleRules),
+		Struct:      args.CmdStruct,
+		TDoc:        args.TDoc,
+		Template:    args.Template,
+		Transitive:  args.Transitive,
+	}
+}
+
+// splitRoles splits semicolon separated role rules
+func splitRoles(rules string) (out []string) {
+	for _, r := range strings.Split(rules, `;`) {
+		if r = strings.TrimSpace(r); r != `` {
+			out = append(out, r)
+		}
+	}
+	return
+}
@@ -0,0 +1,179 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+)
+
+// Exclusion a method left out of a common interface
+type Exclusion struct {
+	Method string        // Method method name
+	Reason string        // Reason why the method was left out
+	Pos    diag.Position // Pos position of the method on the first type declaring it
+}
+
+func (e Exclusion) String() string {
+	return e.Method + `: ` + e.Reason
+}
+
+// populateCommonInterface generates a single interface from the methods common
+// to all types. A method is common if every type has a method with the same
+// name and the same parameter and result types. With Generalize, methods whose
+// signatures only differ by one set of types are made common by inferring the
+// type parameter T.
+// Methods that are left out are recorded on the target and reported as
+// warnings at the position of the method.
+func (g *Generate) populateCommonInterface(typs []parser.Type, name string, p *parser.Parser) error {
+	var matched []parser.Type
+	for _, typ := range typs {
+		if !typ.Directives.Ignore(name) {
+			matched = append(matched, typ)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	typs = matched
+	q := parser.NewQuery(p)
+	sets := make([][]*parser.Method, len(typs))
+	for i, typ := range typs {
+		sets[i] = g.selectMethods(q.GetRecvsByType(typ.Name), name)
+	}
+	t, err := g.targetFor(typs[0].Name, name, p.Package)
+	if err != nil {
+		return err
+	}
+	var params *parser.TypeParams
+	if g.Generalize {
+		params = &parser.TypeParams{}
+	}
+	common, excluded := intersect(typs, sets, qualifyPkg(p.Package, t.tdata.Pkg), params)
+	t.excluded = append(t.excluded, excluded...)
+	for _, e := range excluded {
+		g.report(diag.New(diag.Warning, diag.CodeMethodExcluded, e.Pos, name+`: `+e.String()))
+	}
+	iface, finish := makeInterface(t.tdata, name, g.typeDoc(name, typs[0].Name, typs[0].Doc), g.NoTDoc, g.docWidth())
+	setSource(iface, typs[0].Name, typs[0].File, typs[0].Line)
+	if params != nil && len(params.Names) > 0 {
+		iface.TypeParams = `[` + strings.Join(params.Names, `, `) + ` any]`
+	}
+	err = g.addRecvMethods(iface, &common, p.Package, t.tdata.Pkg)
+	if err != nil {
+		return err
+	}
+	err = g.addPrefixImports(t, p.Imports, common)
+	if err != nil || iface.Methods == nil {
+		return err
+	}
+	return finish()
+}
+
+// intersect returns the methods of the first type which are common to all the
+// method sets, and the methods which are not with the reason. If params is not
+// nil methods with differing signatures are generalized, the inferred type
+// parameters are added to params.
+func intersect(typs []parser.Type, sets [][]*parser.Method, pkg string, params *parser.TypeParams) (common []*parser.Method, excluded []Exclusion) {
+	var names []string
+	byName := make([]map[string]*parser.Method, len(sets))
+	for i, set := range sets {
+		byName[i] = map[string]*parser.Method{}
+		for _, m := range set {
+			if _, ok := byName[i][m.Name]; ok {
+				continue
+			}
+			byName[i][m.Name] = m
+			if !containsString(names, m.Name) {
+				names = append(names, m.Name)
+			}
+		}
+	}
+	for _, n := range names {
+		var missing []string
+		sigs := map[string][]string{}
+		var order []string
+		var pos diag.Position
+		for i, typ := range typs {
+			m, ok := byName[i][n]
+			if !ok {
+				missing = append(missing, typ.Name)
+				continue
+			} else if pos.File == `` {
+				pos = diag.Position{File: m.File, Line: m.Line}
+			}
+			sig := typeSignature(m)
+			if _, ok := sigs[sig]; !ok {
+				order = append(order, sig)
+			}
+			sigs[sig] = append(sigs[sig], fmt.Sprintf(`%s.%s at %s:%d`, typ.Name, m.Signature(), m.File, m.Line))
+		}
+		switch {
+		case len(missing) > 0:
+			excluded = append(excluded, Exclusion{
+				Method: n,
+				Reason: `missing on ` + strings.Join(missing, `, `),
+				Pos:    pos,
+			})
+		case len(sigs) > 1:
+			if params != nil {
+				if m, err := parser.Generalize(methodsNamed(byName, n), pkg, params); err == nil {
+					common = append(common, m)
+					continue
+				}
+			}
+			var diffs []string
+			for _, sig := range order {
+				diffs = append(diffs, sigs[sig]...)
+			}
+			excluded = append(excluded, Exclusion{
+				Method: n,
+				Reason: `signature mismatch ` + strings.Join(diffs, `, `),
+				Pos:    pos,
+			})
+		default:
+			common = append(common, byName[0][n])
+		}
+	}
+	sort.SliceStable(excluded, func(i, j int) bool {
+		return excluded[i].Method < excluded[j].Method
+	})
+	return
+}
+
+// methodsNamed returns the method n of each method set
+func methodsNamed(byName []map[string]*parser.Method, n string) []*parser.Method {
+	methods := make([]*parser.Method, len(byName))
+	for i := range byName {
+		methods[i] = byName[i][n]
+	}
+	return methods
+}
+
+// qualifyPkg returns the package used to qualify types of the parsed package
+// in the target package. It is empty if they are the same package.
+func qualifyPkg(parsedPkg, targetPkg string) string {
+	if parsedPkg == targetPkg {
+		return ``
+	}
+	return parsedPkg
+}
+
+// typeSignature returns the parameter and result types of a method. Parameter
+// names are not part of the signature.
+func typeSignature(m *parser.Method) string {
+	types := func(params []parser.Param) string {
+		var l []string
+		for _, p := range params {
+			t := p.Type
+			if p.Variadic {
+				t = `...` + t
+			}
+			l = append(l, t)
+		}
+		return `(` + strings.Join(l, `, `) + `)`
+	}
+	return types(m.Params()) + types(m.Results())
+}
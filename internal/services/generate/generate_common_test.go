@@ -0,0 +1,85 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcCommon = `package originpkg
+
+// PostgresUserStore stores users
+type PostgresUserStore struct{}
+
+// Get gets a user
+func (s *PostgresUserStore) Get(id string) (any, error) { return nil, nil }
+
+// Ping pings the database
+func (s *PostgresUserStore) Ping(ctx context.Context) error { return nil }
+
+// Delete deletes a user
+func (s *PostgresUserStore) Delete(id string) error { return nil }
+
+// Close closes the store
+func (s *PostgresUserStore) Close() error { return nil }
+
+// PostgresOrderStore stores orders
+type PostgresOrderStore struct{}
+
+// Get gets an order
+func (s *PostgresOrderStore) Get(key string) (any, error) { return nil, nil }
+
+// Ping pings the database
+func (s PostgresOrderStore) Ping(c context.Context) error { return nil }
+
+// Delete deletes an order
+func (s *PostgresOrderStore) Delete(id int) error { return nil }
+`
+
+func TestGenerator_Type_Common(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Common:    true,
+		Iface:     `Store`,
+		MatchType: `Postgres*Store`,
+		Pkg:       `originpkg`,
+		Print:     print.New(print.Options{Stderr: stderr}),
+	}
+	srcs := []srcio.Source{
+		{
+			File: `store.go`,
+			Src:  srcCommon,
+		},
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package originpkg
+
+import "context"
+
+// Store stores users
+type Store interface {
+	// Get gets a user
+	Get(id string) (any, error)
+	// Ping pings the database
+	Ping(ctx context.Context) error
+}
+`
+	assert.Equal(t, expected, out.String())
+	excluded := gen.targets[`store_iface.go`].excluded
+	if assert.Len(t, excluded, 2) {
+		assert.Equal(t, `Close: missing on PostgresOrderStore`, excluded[0].String())
+		assert.Equal(t, `Delete: signature mismatch PostgresUserStore.Delete(id string) error at store.go:13, PostgresOrderStore.Delete(id int) error at store.go:28`, excluded[1].String())
+	}
+	assert.Equal(t, "store.go:16: Store: "+excluded[0].String()+"\nstore.go:13: Store: "+excluded[1].String()+"\n", stderr.String())
+}
@@ -0,0 +1,69 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/tdata"
+)
+
+// report reports a warning to the Reporter or prints it if there is no
+// Reporter and a print handler is set
+func (g *Generate) report(d *diag.Diagnostic) {
+	diag.Warn(g.Reporter, g.Print, d)
+}
+
+// addMethod adds m to iface. The first method of a name is kept and a
+// duplicate-method warning is reported if a later method has a different
+// signature.
+func (g *Generate) addMethod(iface *tdata.Interface, m *tdata.Method) error {
+	err := iface.Add(m)
+	if err == tdata.ErrorDuplicateMethod {
+		prev := iface.Method(m.Name())
+		if prev != nil && prev.Signature() != m.Signature() {
+			g.report(diag.New(diag.Warning, diag.CodeDuplicateMethod, diag.Position{File: m.File, Line: m.Line},
+				fmt.Sprintf(`%s: %s is left out, %s already has %s`, iface.Type.Name(), m.Signature(), iface.Type.Name(), prev.Signature()),
+			).WithFix(fmt.Sprintf(`remove %s from %s or generate the interface without -a`, m.Name(), cond.First(prev.File, `the output file`))))
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf(`can not add method: %w`, err)
+	}
+	return nil
+}
+
+// typeNotFound returns the error for no types matching the options or the
+// go:generate directive in src
+func (g *Generate) typeNotFound(src *srcio.Source) error {
+	if g.TypeName != `` {
+		return diag.Errorf(diag.CodeTypeNotFound, srcPosition(src), `%w "%s"`, ErrTypeNotFound, g.TypeName).
+			WithFix(`check that the type is declared in the source files`)
+	}
+	if g.MatchType != `` {
+		return diag.Errorf(diag.CodeTypeNotFound, srcPosition(src), `%w "%s"`, ErrTypeNotFound, g.MatchType).
+			WithFix(`check that -t matches an exported type declared in the source files`)
+	}
+	return diag.Errorf(diag.CodeTypeNotFound, srcPosition(src), `%w after the directive`, ErrTypeNotFound).
+		WithFix(`move the directive above the type declaration or select the type with -t`)
+}
+
+// recvNotFound returns the error for no methods matching the options or the
+// go:generate directive in src
+func (g *Generate) recvNotFound(src *srcio.Source) error {
+	if g.MatchFunc != `` {
+		return diag.Errorf(diag.CodeMethodNotFound, srcPosition(src), `%w %s.%s`, ErrRecvNotFound, g.MatchType, g.MatchFunc).
+			WithFix(`check that -t and -m match an exported method declared in the source files`)
+	}
+	return diag.Errorf(diag.CodeMethodNotFound, srcPosition(src), `%w after the directive`, ErrRecvNotFound).
+		WithFix(`move the directive above the method declaration or select the method with -t and -m`)
+}
+
+// srcPosition returns the position of the go:generate directive in src
+func srcPosition(src *srcio.Source) diag.Position {
+	if src == nil {
+		return diag.Position{}
+	}
+	return diag.Position{File: src.File, Line: src.Line}
+}
@@ -0,0 +1,101 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_Diag_TypeNotFound(t *testing.T) {
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Iface:   `AIface`,
+		Pkg:     `store`,
+	}
+	src := "package store\n\n//go:generate ifaces type -i AIface\n\nfunc helper() {}\n"
+	err := gen.Generate([]srcio.Source{{File: `store.go`, Src: src, Line: 3}}, &bytes.Buffer{}, `a_iface.go`, &bytes.Buffer{})
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+	l := diag.FromError(err)
+	if assert.Len(t, l, 1) {
+		assert.Equal(t, diag.CodeTypeNotFound, l[0].Code)
+		assert.Equal(t, diag.Position{File: `store.go`, Line: 3}, l[0].Pos)
+		assert.Equal(t, `store.go:3: could not match type after the directive`, l[0].Error())
+		assert.NotEmpty(t, l[0].Fix)
+	}
+
+	gen.MatchType = `Missing*`
+	err = gen.Generate([]srcio.Source{{File: `store.go`, Src: src}}, &bytes.Buffer{}, `a_iface.go`, &bytes.Buffer{})
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+	assert.Equal(t, `could not match type "Missing*"`, err.Error())
+}
+
+func TestGenerator_Diag_ParseError(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `store`,
+	}
+	src := "package store\n\ntype Store struct {\n"
+	err := gen.Generate([]srcio.Source{{File: `store.go`, Src: src}}, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	l := diag.FromError(err)
+	if assert.NotEmpty(t, l) {
+		assert.Equal(t, diag.CodeParse, l[0].Code)
+		assert.Equal(t, `store.go`, l[0].Pos.File)
+		assert.Equal(t, 3, l[0].Pos.Line)
+	}
+}
+
+func TestGenerator_Diag_DuplicateMethod(t *testing.T) {
+	current := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+type StoreIface interface {
+	Get(id int) error
+}
+`
+	src := `package store
+
+type Store struct{}
+
+func (s *Store) Get(id string) error { return nil }
+`
+	reporter := &diag.Reporter{}
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `store`,
+		Reporter:  reporter,
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate([]srcio.Source{{File: `store.go`, Src: src}}, bytes.NewBufferString(current), `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `Get(id int) error`)
+	l := reporter.List()
+	if assert.Len(t, l, 1) {
+		assert.Equal(t, diag.Warning, l[0].Severity)
+		assert.Equal(t, diag.CodeDuplicateMethod, l[0].Code)
+		assert.Equal(t, `store.go:5: StoreIface: Get(id string) error is left out, StoreIface already has Get(id int) error`, l[0].Error())
+		assert.Equal(t, `remove Get from store_iface.go or generate the interface without -a`, l[0].Fix)
+	}
+
+	stderr := &bytes.Buffer{}
+	gen.Reporter = nil
+	gen.Print = print.New(print.Options{Stderr: stderr})
+	err = gen.Generate([]srcio.Source{{File: `store.go`, Src: src}}, bytes.NewBufferString(current), `store_iface.go`, &bytes.Buffer{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "store.go:5: StoreIface: Get(id string) error is left out, StoreIface already has Get(id int) error\n\tfix: remove Get from store_iface.go or generate the interface without -a\n", stderr.String())
+	}
+}
@@ -0,0 +1,79 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/tdata"
+	"github.com/dexterp/ifaces/internal/resources/tmplfuncs"
+)
+
+// DocData data passed to the TDoc and FDoc document templates
+type DocData struct {
+	Name    string // Name interface or method name
+	Source  string // Source name of the type the interface or method is generated from
+	OrigDoc string // OrigDoc original document of the type or method
+}
+
+// parseDocTmpl parses a document template. The template is executed once to
+// report unknown fields before any output is generated.
+func parseDocTmpl(name, text string) (*template.Template, error) {
+	if text == `` {
+		return nil, nil
+	}
+	t, err := template.New(name).Funcs(tmplfuncs.FuncMap()).Parse(text)
+	if err == nil {
+		err = t.Execute(&bytes.Buffer{}, DocData{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`invalid %s template: %w`, name, err)
+	}
+	return t, nil
+}
+
+// typeDoc returns the document of the interface name generated from source.
+// Without a TDoc template a leading source name in the original document is
+// replaced by the interface name.
+func (g *Generate) typeDoc(name, source, orig string) string {
+	if g.tdocTmpl != nil {
+		return execDoc(g.tdocTmpl, DocData{Name: name, Source: source, OrigDoc: orig})
+	}
+	if first, rest, ok := strings.Cut(orig, ` `); ok && first == source {
+		return name + ` ` + rest
+	} else if orig == source {
+		return name
+	}
+	return orig
+}
+
+// methodDoc returns the document of an interface method generated from m
+func (g *Generate) methodDoc(m *parser.Method) string {
+	if g.fdocTmpl != nil {
+		return execDoc(g.fdocTmpl, DocData{Name: m.Name, Source: m.TypeName, OrigDoc: m.Doc})
+	}
+	return m.Doc
+}
+
+// docWidth returns the wrap width of documents
+func (g *Generate) docWidth() int {
+	if g.DocWidth == 0 {
+		return tdata.DefaultWidth
+	}
+	return g.DocWidth
+}
+
+// execDoc executes a document template. A "Deprecated:" notice in the original
+// document is kept if the template does not include it.
+func execDoc(t *template.Template, data DocData) string {
+	buf := &bytes.Buffer{}
+	// errors are reported by parseDocTmpl
+	_ = t.Execute(buf, data)
+	doc := strings.TrimSpace(buf.String())
+	if dep := tdata.Deprecated(data.OrigDoc); dep != `` && !strings.Contains(doc, dep) {
+		doc += "\n\n" + dep
+	}
+	return doc
+}
@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcDoc = `package mypkg
+
+// Store stores items.
+//
+// Items are cached:
+//   - in memory
+//   - on disk
+type Store struct{}
+
+// Get gets an item.
+//
+// Deprecated: use Find.
+func (s *Store) Get(id string) error { return nil }
+
+// Find finds an item
+func (s *Store) Find(id string) error { return nil }
+
+// List of items
+type Items struct{}
+
+// Len length
+func (i Items) Len() int { return 0 }
+`
+
+func TestGenerator_Type_Doc(t *testing.T) {
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Iface:   `StoreIface`,
+		Pkg:     `mypkg`,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcDoc}}
+	out := &bytes.Buffer{}
+	gen.MatchType = `Store`
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package mypkg
+
+// StoreIface stores items.
+//
+// Items are cached:
+//   - in memory
+//   - on disk
+type StoreIface interface {
+	// Get gets an item.
+	//
+	// Deprecated: use Find.
+	Get(id string) error
+	// Find finds an item
+	Find(id string) error
+}
+`
+	assert.Equal(t, expected, out.String())
+
+	// the first word is only replaced if it is the type name
+	gen.MatchType = `Items`
+	gen.Iface = `Lener`
+	out.Reset()
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out.String(), "// List of items\ntype Lener interface {")
+	}
+}
+
+func TestGenerator_Type_DocTemplates(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		FDoc:      `{{.Name}} calls {{.Source}}.{{.Name}}.`,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `mypkg`,
+		TDoc:      `{{.Name}} is implemented by {{.Source}}. {{.OrigDoc}}`,
+		DocWidth:  40,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcDoc}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package mypkg
+
+// StoreIface is implemented by Store.
+// Store stores items.
+//
+// Items are cached:
+//   - in memory
+//   - on disk
+type StoreIface interface {
+	// Get calls Store.Get.
+	//
+	// Deprecated: use Find.
+	Get(id string) error
+	// Find calls Store.Find.
+	Find(id string) error
+}
+`
+	assert.Equal(t, expected, out.String())
+
+	gen.TDoc = `{{.Missing}}`
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid tdoc template`)
+	}
+}
@@ -0,0 +1,83 @@
+package generate
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+)
+
+// debugf prints a message explaining a decision if a print handler is set. The
+// messages are printed at the DEBUG level, see --explain.
+func (g *Generate) debugf(format string, a ...any) {
+	diag.Explainf(g.Print, format, a...)
+}
+
+// explainParsed explains the files parsed and the go:generate directive
+func (g *Generate) explainParsed(srcs []srcio.Source, p *parser.Parser, src *srcio.Source) {
+	for _, s := range srcs {
+		g.debugf(`parsed %s, package %s`, s.File, p.Package)
+	}
+	if src != nil {
+		g.debugf(`directive at %s:%d`, src.File, src.Line)
+	}
+}
+
+// explainTypeByLine explains the type selected after the directive at line
+func (g *Generate) explainTypeByLine(q *parser.Query, file string, line int, typ *parser.Type) {
+	if typ != nil {
+		g.debugf(`type %s selected at %s:%d, the first type after the directive`, typ.Name, typ.File, typ.Line)
+		return
+	}
+	end := q.NextComment(file, line)
+	if end > 0 {
+		g.debugf(`no type found between the directive at %s:%d and the next directive at line %d`, file, line, end)
+	} else {
+		g.debugf(`no type found after the directive at %s:%d`, file, line)
+	}
+}
+
+// explainTypes explains the types selected by the struct command or -t
+func (g *Generate) explainTypes(kind string, types []parser.Type) {
+	if len(types) == 0 {
+		g.debugf(`no %s found`, kind)
+		return
+	}
+	names := []string{}
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	g.debugf(`%s selected: %s`, kind, strings.Join(names, `, `))
+}
+
+// explainUnexported explains the unexported methods of typ which are never
+// added to an interface
+func (g *Generate) explainUnexported(p *parser.Parser, typ, iface string) {
+	for _, m := range p.ReceiverMethods {
+		if m.TypeName == typ && !match.Capitalized(m.Signature()) {
+			g.debugf(`%s: %s.%s excluded, not exported`, iface, m.TypeName, m.Name)
+		}
+	}
+}
+
+// excludeReason returns the reason a method is not added to iface or an empty
+// string if it is added
+func (g Generate) excludeReason(m *parser.Method, iface string) string {
+	switch {
+	case m.Directives.Ignore(iface):
+		return `ifaces:ignore directive`
+	case len(g.Include) > 0 && !matchAny(m.Name, g.Include):
+		return `no match for --include ` + strings.Join(g.Include, `,`)
+	case matchAny(m.Name, g.Exclude):
+		return `matches --exclude ` + strings.Join(g.Exclude, `,`)
+	case g.Type && g.MatchFunc != `` && !g.transitive[iface] && !matchAny(m.Name, stringx.SplitList(g.MatchFunc)):
+		return `no match for -m ` + g.MatchFunc
+	case len(g.FromFiles) > 0 && !matchAny(m.File, g.FromFiles):
+		return `file ` + filepath.Base(m.File) + ` does not match --from-files ` + strings.Join(g.FromFiles, `,`)
+	}
+	return ``
+}
@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcExplain = `package store
+
+import "context"
+
+//` + `go:generate ifaces type -o store_iface.go -i StoreIface --exclude Close
+
+// Store stores items
+type Store struct{}
+
+// Get gets an item
+func (s *Store) Get(ctx context.Context, id string) error { return nil }
+
+func (s *Store) Close() error { return nil }
+
+func (s *Store) reset() {}
+
+//` + `go:generate ifaces type -o cache_iface.go -i CacheIface
+
+func helper() {}
+
+type Cache struct{}
+`
+
+func TestGenerator_Explain(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Exclude: []string{`Close`},
+		Iface:   `StoreIface`,
+		Pkg:     `store`,
+		Print:   print.New(print.Options{Stderr: stderr, Level: print.DEBUG}),
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcExplain, Line: 5}}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `explain: parsed store.go, package store
+explain: directive at store.go:5
+explain: type Store selected at store.go:8, the first type after the directive
+explain: StoreIface: Store.reset excluded, not exported
+explain: StoreIface: Store.Get included
+explain: StoreIface: Store.Close excluded, matches --exclude Close
+explain: store_iface.go: import "context"
+`
+	assert.Equal(t, expected, stderr.String())
+}
+
+func TestGenerator_Explain_Skipped(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Iface:   `CacheIface`,
+		Pkg:     `store`,
+		Print:   print.New(print.Options{Stderr: stderr, Level: print.DEBUG}),
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcExplain, Line: 17}}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `cache_iface.go`, &bytes.Buffer{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, stderr.String(), "explain: type Cache selected at store.go:21, the first type after the directive\n")
+	assert.Contains(t, stderr.String(), "explain: CacheIface: interface skipped, type Cache has no methods to add\n")
+}
+
+func TestGenerator_Explain_NoType(t *testing.T) {
+	src := `package store
+
+//` + `go:generate ifaces type -o a_iface.go -i AIface
+
+func helper() {}
+
+//` + `go:generate ifaces type -o b_iface.go -i BIface
+
+//` + `go:generate ifaces type -o c_iface.go -i CIface
+type C struct{}
+`
+	stderr := &bytes.Buffer{}
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Iface:   `AIface`,
+		Pkg:     `store`,
+		Print:   print.New(print.Options{Stderr: stderr, Level: print.DEBUG}),
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: src, Line: 3}}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `a_iface.go`, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrTypeNotFound)
+	assert.Contains(t, stderr.String(), "explain: no type found between the directive at store.go:3 and the next directive at line 7\n")
+}
@@ -0,0 +1,47 @@
+package generate
+
+import (
+	"bytes"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+)
+
+// Files generated source keyed by file name. Files accumulates the output of
+// several generators so that generators writing to the same file add to the
+// source of the previous generator.
+type Files map[string]*bytes.Buffer
+
+// Add runs g and adds the generated source to f. outfile is the output file
+// unless g.OutTmpl is set. Files not yet in f are read with current when
+// appending, otherwise they start empty.
+func (f Files) Add(g *Generate, srcs []srcio.Source, outfile string, appendTo bool, current func(file string) (*bytes.Buffer, error)) error {
+	cur := func(file string) (*bytes.Buffer, error) {
+		if buf, ok := f[file]; ok {
+			return bytes.NewBuffer(buf.Bytes()), nil
+		} else if appendTo && current != nil {
+			return current(file)
+		}
+		return &bytes.Buffer{}, nil
+	}
+	if g.OutTmpl != `` {
+		out, err := g.GenerateFiles(srcs, cur)
+		if err != nil {
+			return err
+		}
+		for file, buf := range out {
+			f[file] = buf
+		}
+		return nil
+	}
+	src, err := cur(outfile)
+	if err != nil {
+		return err
+	}
+	out := &bytes.Buffer{}
+	err = g.Generate(srcs, src, outfile, out)
+	if err != nil {
+		return err
+	}
+	f[outfile] = out
+	return nil
+}
@@ -0,0 +1,97 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcGeneralize = `package originpkg
+
+import "github.com/example/models"
+
+// UserRepo user repository
+type UserRepo struct{}
+
+// Get gets a user
+func (r *UserRepo) Get(id string) (*models.User, error) { return nil, nil }
+
+// List lists users
+func (r *UserRepo) List() ([]*models.User, error) { return nil, nil }
+
+// Count counts users
+func (r *UserRepo) Count() int { return 0 }
+
+// Key returns the key of a user
+func (r *UserRepo) Key(u *models.User) int { return 0 }
+
+// OrderRepo order repository
+type OrderRepo struct{}
+
+// Get gets an order
+func (r *OrderRepo) Get(id string) (*Order, error) { return nil, nil }
+
+// List lists orders
+func (r *OrderRepo) List() ([]*Order, error) { return nil, nil }
+
+// Count counts orders
+func (r *OrderRepo) Count() int { return 0 }
+
+// Key returns the key of an order
+func (r *OrderRepo) Key(o *Order) string { return "" }
+
+type Order struct{}
+`
+
+func TestGenerator_Type_Generalize(t *testing.T) {
+	gen := &Generate{
+		Type:       true,
+		Comment:    comment,
+		Generalize: true,
+		Iface:      `Repo`,
+		MatchType:  `*Repo`,
+		Pkg:        `ports`,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `repo.go`,
+			Src:  srcGeneralize,
+		},
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `repo_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package ports
+
+// Repo user repository
+type Repo[T any] interface {
+	// Get gets a user
+	Get(id string) (T, error)
+	// List lists users
+	List() ([]T, error)
+	// Count counts users
+	Count() int
+}
+`
+	assert.Equal(t, expected, out.String())
+	excluded := gen.targets[`repo_iface.go`].excluded
+	if assert.Len(t, excluded, 1) {
+		assert.Equal(t, `Key`, excluded[0].Method)
+		assert.Equal(t, `signature mismatch UserRepo.Key(u *models.User) int at repo.go:18, OrderRepo.Key(o *Order) string at repo.go:33`, excluded[0].Reason)
+	}
+
+	// appending keeps the type parameters
+	out2 := &bytes.Buffer{}
+	gen.Iface = `Other`
+	gen.MatchType = `UserRepo`
+	err = gen.Generate(srcs, out, `repo_iface.go`, out2)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out2.String(), `type Repo[T any] interface {`)
+	}
+}
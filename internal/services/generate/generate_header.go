@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// loadHeader returns the comment lines of the header file followed by the
+// extra comment. Lines of the header file which are not comments are turned
+// into comments.
+func loadHeader(file, extra string) (string, error) {
+	var blocks []string
+	if file != `` {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return ``, fmt.Errorf(`can not read header file: %w`, err)
+		}
+		if h := commentLines(string(b)); h != `` {
+			blocks = append(blocks, h)
+		}
+	}
+	if c := commentLines(extra); c != `` {
+		blocks = append(blocks, c)
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// commentLines returns text as line comments. Text which is already a comment
+// is returned as is.
+func commentLines(text string) string {
+	text = strings.Trim(text, "\n")
+	if strings.TrimSpace(text) == `` {
+		return ``
+	}
+	lines := strings.Split(text, "\n")
+	comment := strings.HasPrefix(strings.TrimSpace(text), `/*`)
+	if !comment {
+		comment = true
+		for _, l := range lines {
+			if l = strings.TrimSpace(l); l != `` && !strings.HasPrefix(l, `//`) {
+				comment = false
+			}
+		}
+	}
+	if comment {
+		return text + "\n"
+	}
+	buf := &strings.Builder{}
+	for _, l := range lines {
+		l = strings.TrimRight(l, " \t")
+		if l == `` {
+			buf.WriteString("//\n")
+		} else {
+			buf.WriteString("// " + l + "\n")
+		}
+	}
+	return buf.String()
+}
+
+// buildFor returns the build constraint of a target. Unless Build is set the
+// constraint combines the constraint of the current source and the constraints
+// of the source files of the interfaces and methods.
+func (g *Generate) buildFor(t *target) (string, error) {
+	if g.Build != `` {
+		expr, err := constraint.Parse(`//go:build ` + g.Build)
+		if err != nil {
+			return ``, fmt.Errorf(`invalid build constraint "%s": %w`, g.Build, err)
+		}
+		return expr.String(), nil
+	}
+	uniq := map[string]struct{}{}
+	if t.build != `` {
+		uniq[t.build] = struct{}{}
+	}
+	add := func(file string) {
+		if b, ok := g.builds[filepath.Base(file)]; ok {
+			uniq[b] = struct{}{}
+		}
+	}
+	for _, i := range t.tdata.Ifaces {
+		if i.File != `` {
+			add(i.File)
+		}
+		for _, m := range i.Methods {
+			if m.File != `` {
+				add(m.File)
+			}
+		}
+	}
+	var builds []string
+	for b := range uniq {
+		builds = append(builds, b)
+	}
+	sort.Strings(builds)
+	var expr constraint.Expr
+	for _, b := range builds {
+		e, err := constraint.Parse(`//go:build ` + b)
+		if err != nil {
+			return ``, err
+		}
+		if expr == nil {
+			expr = e
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: e}
+		}
+	}
+	if expr == nil {
+		return ``, nil
+	}
+	return expr.String(), nil
+}
@@ -0,0 +1,118 @@
+package generate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcHeaderLinux = `//go:build linux
+
+package mypkg
+
+type Store struct{}
+
+func (s *Store) Sync() error { return nil }
+`
+
+var srcHeader = `package mypkg
+
+func (s *Store) Get(id string) error { return nil }
+`
+
+func TestGenerator_Type_Header(t *testing.T) {
+	hdr := filepath.Join(t.TempDir(), `LICENSE.hdr`)
+	err := os.WriteFile(hdr, []byte("Copyright 2026 The Authors\n\nLicensed under the MIT License.\n"), 0o600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen := &Generate{
+		Type:       true,
+		Comment:    `Code generated by ifaces DO NOT EDIT.`,
+		ExtraCmt:   `Interfaces of the store.`,
+		HeaderFile: hdr,
+		Iface:      `StoreIface`,
+		MatchType:  `Store`,
+		Pkg:        `mypkg`,
+	}
+	srcs := []srcio.Source{
+		{File: `/src/store_linux.go`, Src: srcHeaderLinux},
+		{File: `/src/store.go`, Src: srcHeader},
+	}
+	out := &bytes.Buffer{}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+//go:build linux
+
+// Copyright 2026 The Authors
+//
+// Licensed under the MIT License.
+
+// Interfaces of the store.
+
+package mypkg
+
+type StoreIface interface {
+	Sync() error
+	Get(id string) error
+}
+`
+	assert.Equal(t, expected, out.String())
+
+	// appending keeps the constraint of the current source
+	gen.HeaderFile = ``
+	gen.ExtraCmt = ``
+	gen.Iface = `Namer`
+	gen.MatchType = `Other`
+	other := []srcio.Source{{File: `/src/other.go`, Src: "package mypkg\n\ntype Other struct{}\n\nfunc (o Other) Name() string { return \"\" }\n"}}
+	out2 := &bytes.Buffer{}
+	err = gen.Generate(other, out, `store_iface.go`, out2)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out2.String(), "DO NOT EDIT.\n\n//go:build linux\n\npackage mypkg\n")
+	}
+
+	gen.Build = `linux && !arm`
+	gen.MatchType = `Store`
+	out2.Reset()
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out2)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out2.String(), "//go:build linux && !arm\n")
+	}
+
+	gen.Build = `linux &&`
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestGenerator_Type_BuildFileName(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `mypkg`,
+	}
+	srcs := []srcio.Source{
+		{File: `/src/store_windows_amd64.go`, Src: "package mypkg\n\ntype Store struct{}\n\nfunc (s *Store) Sync() error { return nil }\n"},
+		{File: `/src/store.go`, Src: srcHeader},
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out.String(), "//go:build windows && amd64\n\npackage mypkg\n")
+	}
+}
+
+func TestCommentLines(t *testing.T) {
+	assert.Equal(t, "// a\n//\n// b\n", commentLines("a\n\nb\n"))
+	assert.Equal(t, "// a\n\n// b\n", commentLines("// a\n\n// b"))
+	assert.Equal(t, "/*\na\n*/\n", commentLines("/*\na\n*/\n"))
+	assert.Equal(t, ``, commentLines("\n \n"))
+}
@@ -7,10 +7,24 @@ import (
 	"io"
 
 	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/pkg/model"
 )
 
 // GenerateIface interface generator
 type GenerateIface interface {
 	// Generate generate interfaces source code for the gen sub command.
 	Generate(srcs []srcio.Source, current *bytes.Buffer, outfile string, output io.Writer) error
+	// GenerateFiles generate interfaces source code with one output file per type.
+	// File names are created by executing the OutTmpl template. current returns
+	// the contents of a previously generated file and may be nil. The generated
+	// source is returned in a map keyed by the file name.
+	GenerateFiles(srcs []srcio.Source, current func(file string) (*bytes.Buffer, error)) (map[string]*bytes.Buffer, error)
+	// GeneratePlugin sends the interfaces found in srcs to the Plugin executable
+	// and returns the files it generates keyed by the file name. Interfaces are
+	// grouped by outfile, or by the OutTmpl file names if outfile is empty.
+	// current returns the contents of a previously generated file and may be nil.
+	GeneratePlugin(srcs []srcio.Source, outfile string, current func(file string) (*bytes.Buffer, error)) (map[string]*bytes.Buffer, error)
+	// GenerateSpec generates the interfaces described by spec. The package and
+	// build constraint of the spec are used unless Pkg or Build are set.
+	GenerateSpec(spec *model.File, current *bytes.Buffer, outfile string, output io.Writer) error
 }
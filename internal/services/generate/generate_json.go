@@ -0,0 +1,73 @@
+package generate
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dexterp/ifaces/internal/resources/tdata"
+	"github.com/dexterp/ifaces/pkg/model"
+)
+
+// Output formats
+const (
+	FormatGo   = `go`   // FormatGo Go source
+	FormatJSON = `json` // FormatJSON JSON model, see the model package
+)
+
+// renderJSON writes the model of a target to output
+func renderJSON(t *target, output io.Writer) error {
+	enc := json.NewEncoder(output)
+	enc.SetIndent(``, `  `)
+	return enc.Encode(newModel(t.file, t.tdata))
+}
+
+// newModel converts template data to the JSON model
+func newModel(file string, data *tdata.TData) model.File {
+	f := model.File{
+		Version:    model.Version,
+		File:       file,
+		Package:    data.Pkg,
+		Build:      data.Build,
+		Imports:    []model.Import{},
+		Interfaces: []model.Interface{},
+	}
+	for _, i := range data.Imports {
+		f.Imports = append(f.Imports, model.Import{Name: i.Name, Path: i.Path})
+	}
+	for _, i := range data.Ifaces {
+		iface := model.Interface{
+			Name:       i.Type.Name(),
+			TypeParams: i.TypeParams,
+			Doc:        i.Type.DocText(),
+			Embeds:     i.Embeds,
+			Methods:    []model.Method{},
+		}
+		if i.Source != `` {
+			iface.Source = &model.Source{Type: i.Source, File: i.File, Line: i.Line}
+		}
+		for _, m := range i.Methods {
+			method := model.Method{
+				Name:      m.Name(),
+				Signature: m.Signature(),
+				Doc:       m.DocText(),
+				Params:    newModelParams(m.Params),
+				Results:   newModelParams(m.Results),
+				Recv:      m.Recv,
+			}
+			if m.Source != `` {
+				method.Source = &model.Source{Type: m.Source, File: m.File, Line: m.Line}
+			}
+			iface.Methods = append(iface.Methods, method)
+		}
+		f.Interfaces = append(f.Interfaces, iface)
+	}
+	return f
+}
+
+func newModelParams(params []tdata.Param) []model.Param {
+	out := []model.Param{}
+	for _, p := range params {
+		out = append(out, model.Param{Name: p.Name, Type: p.Type, Variadic: p.Variadic, Pkg: p.Pkg})
+	}
+	return out
+}
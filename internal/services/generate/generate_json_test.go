@@ -0,0 +1,128 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcJSON = `package store
+
+import "context"
+
+// Store persists items
+type Store struct{}
+
+// Get gets an item
+func (s *Store) Get(ctx context.Context, ids ...string) (item *Item, err error) { return nil, nil }
+
+func (s Store) Len() int { return 0 }
+
+type Item struct{}
+`
+
+func TestGenerator_Type_JSON(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Format:    FormatJSON,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `store`,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcJSON}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.json`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `{
+  "version": 1,
+  "file": "store_iface.json",
+  "package": "store",
+  "imports": [
+    {
+      "path": "context"
+    }
+  ],
+  "interfaces": [
+    {
+      "name": "StoreIface",
+      "doc": "StoreIface persists items",
+      "source": {
+        "type": "Store",
+        "file": "store.go",
+        "line": 6
+      },
+      "methods": [
+        {
+          "name": "Get",
+          "signature": "Get(ctx context.Context, ids ...string) (item *Item, err error)",
+          "doc": "Get gets an item",
+          "params": [
+            {
+              "name": "ctx",
+              "type": "context.Context",
+              "pkg": "context"
+            },
+            {
+              "name": "ids",
+              "type": "string",
+              "variadic": true
+            }
+          ],
+          "results": [
+            {
+              "name": "item",
+              "type": "*Item"
+            },
+            {
+              "name": "err",
+              "type": "error"
+            }
+          ],
+          "recv": "pointer",
+          "source": {
+            "type": "Store",
+            "file": "store.go",
+            "line": 9
+          }
+        },
+        {
+          "name": "Len",
+          "signature": "Len() int",
+          "params": [],
+          "results": [
+            {
+              "type": "int"
+            }
+          ],
+          "recv": "value",
+          "source": {
+            "type": "Store",
+            "file": "store.go",
+            "line": 11
+          }
+        }
+      ]
+    }
+  ]
+}
+`
+	assert.Equal(t, expected, out.String())
+	var f model.File
+	if assert.NoError(t, json.Unmarshal(out.Bytes(), &f)) {
+		assert.Equal(t, model.Version, f.Version)
+	}
+
+	err = gen.Generate(srcs, bytes.NewBufferString(out.String()), `store_iface.json`, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFormatAppend)
+
+	gen.Format = `yaml`
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.json`, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFormat)
+}
@@ -0,0 +1,223 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrConfig  = errors.New(`invalid config file`)
+	ErrTypeMap = errors.New(`invalid type mapping`)
+)
+
+// reQualified matches a qualified type with an optional import path before the
+// package, E.G. "io.Reader", "cloud.google.com/go/civil.Date" or
+// "gopkg.in/yaml.v3.Node"
+var reQualified = regexp.MustCompile(`((?:[\w.~-]+/)*)([A-Za-z_][\w-]*(?:\.v\d+)?)\.([A-Za-z_]\w*)`)
+
+var (
+	reMajor       = regexp.MustCompile(`^v\d+$`)  // reMajor major version element of a module path, E.G. "v2"
+	reMajorSuffix = regexp.MustCompile(`\.v\d+$`) // reMajorSuffix major version suffix of a gopkg.in path, E.G. ".v3"
+)
+
+// Config options read from a YAML or JSON config file
+type Config struct {
+	Map map[string]string `yaml:"map"` // Map type substitutions, keyed by the type in the source, see Generate.Map
+}
+
+// loadConfig reads the config file. Unknown fields are an error.
+func loadConfig(file string) (*Config, error) {
+	cfg := &Config{}
+	if file == `` {
+		return cfg, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf(`can not read config file: %w`, err)
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	err = dec.Decode(cfg)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf(`%w %s: %s`, ErrConfig, file, err.Error())
+	}
+	return cfg, nil
+}
+
+// loadTypeMap creates the substitution of the config file mappings and the Map
+// rules, which take precedence, and the package qualifiers of the types
+// substituted in. Types in another package are written with their import path,
+// E.G. "time.Time=cloud.google.com/go/civil.Date" or
+// "*Node=gopkg.in/yaml.v3.Node". A qualifier without an import path, E.G.
+// "civil.Date", is resolved from the imports of the source files or the
+// standard library once it is used, see addMapImports.
+func (g *Generate) loadTypeMap() (*parser.Substitution, []mapImport, error) {
+	cfg, err := loadConfig(g.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules := map[string]string{}
+	for from, to := range cfg.Map {
+		rules[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	for _, rule := range g.Map {
+		from, to, ok := strings.Cut(rule, `=`)
+		if !ok || strings.TrimSpace(from) == `` || strings.TrimSpace(to) == `` {
+			return nil, nil, fmt.Errorf(`%w %q, expected <from>=<to>`, ErrTypeMap, rule)
+		}
+		rules[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	if len(rules) == 0 {
+		return nil, nil, nil
+	}
+	subs := map[string]string{}
+	quals := map[string]string{}
+	for from, to := range rules {
+		var conflict error
+		subs[from] = reQualified.ReplaceAllStringFunc(to, func(q string) string {
+			m := reQualified.FindStringSubmatch(q)
+			imp := m[1] + m[2]
+			qual := packageName(imp)
+			if m[1] == `` {
+				imp = ``
+			}
+			if cur, ok := quals[qual]; ok && cur != imp && cur != `` && imp != `` && conflict == nil {
+				conflict = diag.Errorf(diag.CodeUsage, diag.Position{}, `%w "%s=%s", %s is the qualifier of %s and %s`, ErrTypeMap, from, to, qual, cur, imp)
+			}
+			if quals[qual] == `` {
+				quals[qual] = imp
+			}
+			return qual + `.` + m[3]
+		})
+		if conflict != nil {
+			return nil, nil, conflict
+		}
+	}
+	s, err := parser.NewSubstitution(subs)
+	if err != nil {
+		return nil, nil, err
+	}
+	var imports []mapImport
+	for qual, imp := range quals {
+		mi := mapImport{qual: qual}
+		if imp != `` {
+			mi.imp = &parser.Import{Path: imp}
+			if path.Base(imp) != qual {
+				mi.imp.Name = qual
+			}
+		}
+		imports = append(imports, mi)
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].qual < imports[j].qual
+	})
+	return s, imports, nil
+}
+
+// mapImport package qualifier of the mapped types
+type mapImport struct {
+	qual string         // qual package qualifier written in the mapped types
+	imp  *parser.Import // imp import of the qualifier, nil if the rule has no import path
+}
+
+// packageName returns the package name of an import path by convention, the
+// last element without a major version, E.G. "yaml" for "gopkg.in/yaml.v3"
+// and "chi" for "github.com/go-chi/chi/v5". The import is named with it if the
+// name differs from the last element, so the name does not have to match the
+// declared package name.
+func packageName(imp string) string {
+	name := path.Base(imp)
+	if reMajor.MatchString(name) && path.Dir(imp) != `.` {
+		name = path.Base(path.Dir(imp))
+	}
+	name = reMajorSuffix.ReplaceAllString(name, ``)
+	return strings.NewReplacer(`-`, `_`, `.`, `_`, `~`, `_`).Replace(name)
+}
+
+// mapMethod returns a copy of m with the types replaced by the type mappings,
+// or m if there are no mappings
+func (g *Generate) mapMethod(m *parser.Method, iface string) *parser.Method {
+	if g.typeMap == nil {
+		return m
+	}
+	mapped := g.typeMap.Apply(m)
+	if sig := mapped.Signature(); sig != m.Signature() {
+		g.debugf(`%s: %s.%s mapped to %s`, iface, m.TypeName, m.Name, sig)
+	}
+	return mapped
+}
+
+// addMapImports adds the imports of the mapped types used by methods. A
+// qualifier without an import path is left to the imports of the source
+// files, see addPrefixImports, or imported from the standard library if no
+// source file imports it.
+func (g *Generate) addMapImports(t *target, parsed []*parser.Import, methods []*parser.Method) error {
+	for _, mi := range g.mapImports {
+		used := false
+		for _, m := range methods {
+			used = used || containsString(m.Prefixes, mi.qual)
+		}
+		if !used {
+			continue
+		} else if mi.imp != nil {
+			t.imports[mi.imp] = struct{}{}
+			continue
+		}
+		imported := false
+		for _, pi := range parsed {
+			imported = imported || pi.Name == mi.qual || pi.Name == `` && stringx.ExPkgPath(pi.Path) == mi.qual
+		}
+		if imported {
+			continue
+		}
+		std := stdPackages()[mi.qual]
+		if len(std) != 1 {
+			reason := `is not imported by the source files or a standard library package`
+			if len(std) > 1 {
+				reason = `matches the standard library packages ` + strings.Join(std, `, `)
+			}
+			return diag.Errorf(diag.CodeUsage, diag.Position{}, `%w, the qualifier %s of a mapped type %s`, ErrTypeMap, mi.qual, reason).
+				WithFix(`write the type with the import path of its package, E.G. 'time.Time=cloud.google.com/go/civil.Date'`)
+		}
+		t.imports[&parser.Import{Path: std[0]}] = struct{}{}
+	}
+	return nil
+}
+
+// stdPackages returns the import paths of the standard library packages keyed
+// by package name, internal and vendored packages are left out. The packages
+// are listed with "go list std" once.
+var stdPackages = func() func() map[string][]string {
+	var once sync.Once
+	pkgs := map[string][]string{}
+	return func() map[string][]string {
+		once.Do(func() {
+			out, err := exec.Command(`go`, `list`, `std`).Output()
+			if err != nil {
+				return
+			}
+			for _, imp := range strings.Fields(string(out)) {
+				if imp == `internal` || strings.Contains(imp, `internal/`) || strings.HasPrefix(imp, `vendor/`) {
+					continue
+				}
+				name := packageName(imp)
+				pkgs[name] = append(pkgs[name], imp)
+			}
+		})
+		return pkgs
+	}
+}()
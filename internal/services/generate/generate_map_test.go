@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testpaths"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcMap = `package originpkg
+
+import (
+	"os"
+	"time"
+)
+
+type Store struct{}
+
+func (s *Store) Open(name string, at time.Time) (*os.File, error) { return nil, nil }
+
+func (s *Store) All() map[string][]*os.File { return nil }
+`
+
+func TestGenerator_Type_Map(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       pkg,
+		Map:       []string{`*os.File=io.ReadWriteCloser`, `time.Time = cloud.google.com/go/civil.Date`},
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcMap}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package mypkg
+
+import (
+	"io"
+
+	"cloud.google.com/go/civil"
+)
+
+type StoreIface interface {
+	Open(name string, at civil.Date) (io.ReadWriteCloser, error)
+	All() map[string][]io.ReadWriteCloser
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestGenerator_Type_MapConfig(t *testing.T) {
+	dir := filepath.Join(testpaths.TempDir(), `generate_map`)
+	err := os.MkdirAll(dir, 0755)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	config := filepath.Join(dir, `ifaces.yaml`)
+	err = os.WriteFile(config, []byte("map:\n  \"*os.File\": io.Reader\n  time.Time: int64\n"), 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Config:    config,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Map:       []string{`*os.File=io.ReadCloser`},
+		Pkg:       pkg,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcMap}}
+	out := &bytes.Buffer{}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `	Open(name string, at int64) (io.ReadCloser, error)`)
+	assert.NotContains(t, out.String(), `"time"`)
+}
+
+func TestGenerator_Type_MapInvalid(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Map:       []string{`*os.File`},
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcMap}}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrTypeMap)
+}
+
+var srcMapCivil = `package originpkg
+
+import (
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+type Item struct{}
+
+type Entity interface {
+	ID() string
+}
+
+type Store struct{}
+
+func (s *Store) Open(at time.Time, d civil.Date) (*Item, error) { return nil, nil }
+`
+
+func TestGenerator_Type_MapQualifier(t *testing.T) {
+	for _, tc := range []struct {
+		rule     string
+		src      string
+		contains []string
+	}{
+		// resolved from the imports of the source file
+		{`time.Time=civil.Date`, srcMapCivil, []string{`Open(at civil.Date, d civil.Date)`, `"cloud.google.com/go/civil"`}},
+		// resolved from the standard library
+		{`*os.File=http.Handler`, srcMap, []string{`(http.Handler, error)`, `"net/http"`}},
+		// named by convention and imported with the name
+		{`*os.File=gopkg.in/yaml.v3.Node`, srcMap, []string{`(yaml.Node, error)`, `yaml "gopkg.in/yaml.v3"`}},
+		{`*os.File=github.com/go-chi/chi/v5.Router`, srcMap, []string{`(chi.Router, error)`, `chi "github.com/go-chi/chi/v5"`}},
+		// types declared in the source are qualified
+		{`*Item=Entity`, srcMapCivil, []string{`(originpkg.Entity, error)`}},
+	} {
+		gen := &Generate{
+			Type:      true,
+			Iface:     `StoreIface`,
+			MatchType: `Store`,
+			Pkg:       pkg,
+			Map:       []string{tc.rule},
+		}
+		out := &bytes.Buffer{}
+		err := gen.Generate([]srcio.Source{{File: `store.go`, Src: tc.src}}, &bytes.Buffer{}, `store_iface.go`, out)
+		if !assert.NoError(t, err, tc.rule) {
+			continue
+		}
+		for _, c := range tc.contains {
+			assert.Contains(t, out.String(), c, tc.rule)
+		}
+	}
+}
+
+func TestGenerator_Type_MapUnknownQualifier(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Map:       []string{`time.Time=civil.Date`},
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcMap}}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, &bytes.Buffer{})
+	if assert.ErrorIs(t, err, ErrTypeMap) {
+		assert.Equal(t, diag.CodeUsage, diag.FromError(err)[0].Code)
+		assert.Contains(t, err.Error(), `the qualifier civil of a mapped type is not imported by the source files`)
+	}
+}
@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/pkg/model"
+	"github.com/dexterp/ifaces/pkg/plugin"
+)
+
+var (
+	ErrPlugin         = errors.New(`plugin failed`)
+	ErrPluginFileName = errors.New(`invalid plugin file name`)
+)
+
+// GeneratePlugin sends the interfaces found in srcs to the Plugin executable
+// and returns the files it generates keyed by the file name. Interfaces are
+// grouped by outfile, or by the OutTmpl file names if outfile is empty.
+// current returns the contents of a previously generated file and may be nil.
+func (g *Generate) GeneratePlugin(srcs []srcio.Source, outfile string, current func(file string) (*bytes.Buffer, error)) (map[string]*bytes.Buffer, error) {
+	err := g.init(outfile, current)
+	if err != nil {
+		return nil, err
+	}
+	if outfile != `` || g.OutTmpl == `` {
+		_, err = g.getOrMakeTarget(outfile, ``)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = g.parseSrc(srcs)
+	if err != nil {
+		return nil, err
+	}
+	req := &plugin.Request{
+		Version: plugin.Version,
+		Options: g.pluginOptions(),
+		Files:   []model.File{},
+	}
+	for _, t := range g.sortedTargets() {
+		_, err = g.prepare(t, srcs)
+		if err != nil {
+			return nil, err
+		}
+		req.Files = append(req.Files, newModel(t.file, t.tdata))
+	}
+	resp, err := g.runPlugin(req)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*bytes.Buffer{}
+	for _, f := range resp.Files {
+		name := filepath.Clean(f.Name)
+		if f.Name == `` || filepath.IsAbs(name) || name == `..` || strings.HasPrefix(name, `..`+string(filepath.Separator)) {
+			return nil, fmt.Errorf(`%s: %w "%s"`, g.Plugin, ErrPluginFileName, f.Name)
+		}
+		files[name] = bytes.NewBufferString(f.Content)
+	}
+	return files, nil
+}
+
+// pluginOptions returns the options sent to a plugin
+func (g *Generate) pluginOptions() plugin.Options {
+	o := plugin.Options{
+		Iface:  g.Iface,
+		Type:   g.MatchType,
+		Method: g.MatchFunc,
+		Pkg:    g.Pkg,
+		Param:  g.PluginParam,
+	}
+	if g.TypeName != `` {
+		o.Type = g.TypeName
+	}
+	switch {
+	case g.Struct:
+		o.Command = `struct`
+	case g.Type:
+		o.Command = `type`
+	case g.Method:
+		o.Command = `func`
+	}
+	return o
+}
+
+// runPlugin runs the plugin executable with a request
+func (g *Generate) runPlugin(req *plugin.Request) (*plugin.Response, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(g.Plugin)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w: %s%s`, g.Plugin, ErrPlugin, err.Error(), pluginStderr(stderr))
+	}
+	resp := &plugin.Response{}
+	err = json.Unmarshal(stdout.Bytes(), resp)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w: invalid response: %s`, g.Plugin, ErrPlugin, err.Error())
+	} else if resp.Error != `` {
+		return nil, fmt.Errorf(`%s: %w: %s`, g.Plugin, ErrPlugin, resp.Error)
+	}
+	return resp, nil
+}
+
+// pluginStderr returns the standard error of a plugin to add to an error
+func pluginStderr(stderr *bytes.Buffer) string {
+	if s := strings.TrimSpace(stderr.String()); s != `` {
+		return "\n" + s
+	}
+	return ``
+}
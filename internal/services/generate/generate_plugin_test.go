@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain runs the test binary as a plugin if IFACES_TEST_PLUGIN is set
+func TestMain(m *testing.M) {
+	switch os.Getenv(`IFACES_TEST_PLUGIN`) {
+	case ``:
+		os.Exit(m.Run())
+	case `fail`:
+		fmt.Fprintln(os.Stderr, `plugin crashed`)
+		os.Exit(2)
+	default:
+		plugin.Main(testPlugin)
+		os.Exit(0)
+	}
+}
+
+// testPlugin lists the methods of each interface in a file named by the
+// plugin parameter
+func testPlugin(req *plugin.Request) ([]plugin.File, error) {
+	if req.Options.Param == `error` {
+		return nil, errors.New(`bad param`)
+	}
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "%s %s %s\n", req.Options.Command, req.Options.Iface, req.Options.Type)
+	for _, f := range req.Files {
+		for _, i := range f.Interfaces {
+			for _, m := range i.Methods {
+				fmt.Fprintf(out, "%s %s.%s %s\n", f.File, i.Name, m.Name, m.Signature)
+			}
+		}
+	}
+	return []plugin.File{{Name: req.Options.Param, Content: out.String()}}, nil
+}
+
+func TestGenerator_Plugin(t *testing.T) {
+	t.Setenv(`IFACES_TEST_PLUGIN`, `1`)
+	gen := &Generate{
+		Type:        true,
+		Comment:     comment,
+		Iface:       `StoreIface`,
+		MatchType:   `Store`,
+		Pkg:         `store`,
+		Plugin:      os.Args[0],
+		PluginParam: `store.txt`,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcJSON}}
+	files, err := gen.GeneratePlugin(srcs, `store_iface.go`, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `type StoreIface Store
+store_iface.go StoreIface.Get Get(ctx context.Context, ids ...string) (item *Item, err error)
+store_iface.go StoreIface.Len Len() int
+`
+	if assert.Contains(t, files, `store.txt`) {
+		assert.Equal(t, expected, files[`store.txt`].String())
+	}
+}
+
+func TestGenerator_Plugin_Errors(t *testing.T) {
+	srcs := []srcio.Source{{File: `store.go`, Src: srcJSON}}
+	for _, tc := range []struct {
+		env   string
+		param string
+		err   error
+		msg   string
+	}{
+		{`fail`, `store.txt`, ErrPlugin, `plugin crashed`},
+		{`1`, `error`, ErrPlugin, `bad param`},
+		{`1`, `../store.txt`, ErrPluginFileName, `../store.txt`},
+		{`1`, `/tmp/store.txt`, ErrPluginFileName, `/tmp/store.txt`},
+	} {
+		t.Run(tc.param, func(t *testing.T) {
+			t.Setenv(`IFACES_TEST_PLUGIN`, tc.env)
+			gen := &Generate{
+				Type:        true,
+				Comment:     comment,
+				Iface:       `StoreIface`,
+				MatchType:   `Store`,
+				Pkg:         `store`,
+				Plugin:      os.Args[0],
+				PluginParam: tc.param,
+			}
+			_, err := gen.GeneratePlugin(srcs, `store_iface.go`, nil)
+			if assert.Error(t, err) {
+				assert.True(t, errors.Is(err, tc.err))
+				assert.Contains(t, err.Error(), tc.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+)
+
+// DefaultRoles role rules used when roles are enabled without any rules
+var DefaultRoles = []string{
+	`Reader=Get*,List*,Find*`,
+	`Writer=Create*,Update*,Delete*`,
+}
+
+// role a role interface and the methods it includes
+type role struct {
+	name     string   // name role name added to the interface name
+	patterns []string // patterns method name wildcards
+}
+
+// parseRoles parses rules of the form "Reader=Get*,List*"
+func parseRoles(rules []string) (roles []role, err error) {
+	for _, rule := range rules {
+		name, patterns, ok := strings.Cut(rule, `=`)
+		name = strings.TrimSpace(name)
+		if !ok || !stringx.IsIdent(name) || len(stringx.SplitList(patterns)) == 0 {
+			return nil, fmt.Errorf(`invalid role rule %q, expected <role>=<pattern>[,<pattern>...]`, rule)
+		}
+		roles = append(roles, role{
+			name:     name,
+			patterns: stringx.SplitList(patterns),
+		})
+	}
+	return roles, nil
+}
+
+// populateRoleInterfaces splits the methods of a type into role interfaces.
+// Methods are assigned by "//ifaces:role <role>..." annotations or else by the
+// first rule matching the method name. Role interfaces are named the type
+// followed by the role, E.G. StoreReader, and documented with the role. A
+// combined interface embeds the role interfaces and holds the methods without
+// a role, it is named the type followed by the combined role names, E.G.
+// StoreReadWriter, or name if there are less than two roles. The combined
+// interface has the type document.
+func (g *Generate) populateRoleInterfaces(t *target, typ parser.Type, name string, recvs []*parser.Method, parsedPkg string) error {
+	var (
+		order  []string
+		groups = map[string][]*parser.Method{}
+		rest   []*parser.Method
+	)
+	for _, r := range g.roles {
+		order = append(order, r.name)
+	}
+	for _, m := range recvs {
+		var names []string
+		for _, d := range m.Directives.Get(`role`) {
+			names = append(names, d.Args...)
+		}
+		if len(names) == 0 {
+			for _, r := range g.roles {
+				if matchAny(m.Name, r.patterns) {
+					names = append(names, r.name)
+					break
+				}
+			}
+		}
+		if len(names) == 0 {
+			rest = append(rest, m)
+		}
+		for _, n := range names {
+			if _, ok := groups[n]; !ok && !containsString(order, n) {
+				order = append(order, n)
+			}
+			groups[n] = append(groups[n], m)
+		}
+	}
+	var roles, ifaces []string
+	for _, n := range order {
+		methods := groups[n]
+		if len(methods) == 0 {
+			continue
+		}
+		roleName := g.Pre + typ.Name + n + g.Post
+		doc := fmt.Sprintf(`%s has the %s methods of %s`, roleName, n, typ.Name)
+		iface, finish := makeInterface(t.tdata, roleName, g.typeDoc(roleName, typ.Name, doc), g.NoTDoc, g.docWidth())
+		setSource(iface, typ.Name, typ.File, typ.Line)
+		err := g.addRecvMethods(iface, &methods, parsedPkg, t.tdata.Pkg)
+		if err != nil {
+			return err
+		}
+		err = finish()
+		if err != nil {
+			return err
+		}
+		roles = append(roles, n)
+		ifaces = append(ifaces, roleName)
+	}
+	if len(roles) < 2 && len(rest) == 0 {
+		return nil
+	}
+	combined := name
+	if len(roles) >= 2 {
+		combined = g.Pre + typ.Name + combineRoles(roles, ``) + g.Post
+	}
+	iface, finish := makeInterface(t.tdata, combined, g.typeDoc(combined, typ.Name, typ.Doc), g.NoTDoc, g.docWidth())
+	setSource(iface, typ.Name, typ.File, typ.Line)
+	for _, r := range ifaces {
+		iface.Embed(r)
+	}
+	err := g.addRecvMethods(iface, &rest, parsedPkg, t.tdata.Pkg)
+	if err != nil {
+		return err
+	}
+	return finish()
+}
+
+// combineRoles combines role names in the style of the io package, E.G. Reader
+// and Writer become ReadWriter. prefix is removed from each role.
+func combineRoles(roles []string, prefix string) string {
+	buf := &strings.Builder{}
+	for i, r := range roles {
+		r = strings.TrimPrefix(r, prefix)
+		if i < len(roles)-1 {
+			r = roleStem(r)
+		}
+		buf.WriteString(r)
+	}
+	return buf.String()
+}
+
+// roleStem returns the verb of a role name ending in "er", E.G. Reader is Read,
+// Writer is Write and Getter is Get.
+func roleStem(role string) string {
+	stem := strings.TrimSuffix(role, `er`)
+	if stem == role || len(stem) < 2 {
+		return role
+	}
+	lower := strings.ToLower(stem)
+	last := lower[len(lower)-1]
+	switch {
+	case lower[len(lower)-2] == last && !isVowel(last):
+		return stem[:len(stem)-1]
+	case len(lower) >= 3 && !isVowel(lower[len(lower)-3]) && isVowel(lower[len(lower)-2]) && strings.IndexByte(`cstvz`, last) >= 0:
+		return stem + `e`
+	}
+	return stem
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte(`aeiou`, c) >= 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
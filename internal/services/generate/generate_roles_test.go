@@ -0,0 +1,146 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcRoles = `package originpkg
+
+// Store stores users
+type Store struct{}
+
+// GetUser gets a user
+func (s *Store) GetUser(id string) (*User, error) { return nil, nil }
+
+// ListUsers lists users
+func (s *Store) ListUsers() ([]*User, error) { return nil, nil }
+
+// CreateUser creates a user
+func (s *Store) CreateUser(u *User) error { return nil }
+
+// Refresh refreshes the cache
+//
+//ifaces:role Reader
+func (s *Store) Refresh() {}
+
+// Close closes the store
+func (s *Store) Close() error { return nil }
+
+// User user
+type User struct{}
+`
+
+func TestGenerator_Type_Roles(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `Store`,
+		MatchType: `Store`,
+		Pkg:       pkg,
+		RoleSplit: true,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `store.go`,
+			Src:  srcRoles,
+		},
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package mypkg
+
+// StoreReader has the Reader methods of Store
+type StoreReader interface {
+	// GetUser gets a user
+	GetUser(id string) (*originpkg.User, error)
+	// ListUsers lists users
+	ListUsers() ([]*originpkg.User, error)
+	// Refresh refreshes the cache
+	Refresh()
+}
+
+// StoreWriter has the Writer methods of Store
+type StoreWriter interface {
+	// CreateUser creates a user
+	CreateUser(u *originpkg.User) error
+}
+
+// StoreReadWriter stores users
+type StoreReadWriter interface {
+	StoreReader
+	StoreWriter
+	// Close closes the store
+	Close() error
+}
+`
+	assert.Equal(t, expected, out.String())
+
+	// Generated interfaces with embedded interfaces are kept when appending.
+	gen.RoleSplit = false
+	gen.MatchType = `User`
+	gen.Iface = `UserIface`
+	srcs[0].Src = srcRoles + "\n// Name name\nfunc (u User) Name() string { return \"\" }\n"
+	appended := &bytes.Buffer{}
+	err = gen.Generate(srcs, out, `store_iface.go`, appended)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, appended.String(), "type StoreReadWriter interface {\n\tStoreReader\n\tStoreWriter\n")
+	assert.Contains(t, appended.String(), "type UserIface interface {")
+}
+
+func TestGenerator_Type_Roles_Rules(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `Users`,
+		MatchType: `Store`,
+		Pkg:       `originpkg`,
+		RoleSplit: true,
+		Roles:     []string{`Getter=Get*`},
+	}
+	srcs := []srcio.Source{
+		{
+			File: `store.go`,
+			Src:  strings.Replace(srcRoles, "// Store stores users\n", "// Store stores users\n//\n// Deprecated: use Repo\n", 1),
+		},
+	}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), "// StoreGetter has the Getter methods of Store\ntype StoreGetter interface {\n\t// GetUser")
+	assert.Contains(t, out.String(), "// StoreReader has the Reader methods of Store\ntype StoreReader interface {\n\t// Refresh")
+	assert.Contains(t, out.String(), "// Deprecated: use Repo\ntype StoreGetReader interface {\n\tStoreGetter\n\tStoreReader\n\t// ListUsers")
+	assert.Equal(t, 1, strings.Count(out.String(), `Deprecated:`))
+
+	// the combined interface of less than two roles is named -i
+	srcs[0].Src = strings.Replace(srcRoles, "//\n//ifaces:role Reader\n", "", 1)
+	out.Reset()
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	if assert.NoError(t, err) {
+		assert.Contains(t, out.String(), "// Users stores users\ntype Users interface {\n\tStoreGetter\n\t// ListUsers")
+	}
+
+	gen.Roles = []string{`Getter`}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+	assert.Error(t, err)
+}
+
+func TestCombineRoles(t *testing.T) {
+	assert.Equal(t, `ReadWriter`, combineRoles([]string{`StoreReader`, `StoreWriter`}, `Store`))
+	assert.Equal(t, `ReadWriteCloser`, combineRoles([]string{`Reader`, `Writer`, `Closer`}, ``))
+	assert.Equal(t, `GetSeekDeleter`, combineRoles([]string{`Getter`, `Seeker`, `Deleter`}, ``))
+	assert.Equal(t, `AdminUpdateLister`, combineRoles([]string{`Admin`, `Updater`, `Lister`}, ``))
+}
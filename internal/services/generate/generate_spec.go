@@ -0,0 +1,165 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/tdata"
+	"github.com/dexterp/ifaces/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrSpec        = errors.New(`invalid spec`)
+	ErrSpecVersion = errors.New(`unsupported spec version`)
+)
+
+// ReadSpec reads a spec in YAML or JSON. Unknown fields are an error.
+func ReadSpec(r io.Reader) (*model.File, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	spec := &model.File{}
+	err := dec.Decode(spec)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf(`%w: %s`, ErrSpec, err.Error())
+	}
+	if spec.Version != 0 && spec.Version != model.Version {
+		return nil, fmt.Errorf(`%w %d`, ErrSpecVersion, spec.Version)
+	}
+	return spec, nil
+}
+
+// GenerateSpec generates the interfaces described by spec. The package and
+// build constraint of the spec are used unless Pkg or Build are set.
+func (g *Generate) GenerateSpec(spec *model.File, current *bytes.Buffer, outfile string, output io.Writer) error {
+	gen := *g
+	gen.Pkg = cond.First(g.Pkg, spec.Package).(string)
+	gen.Build = cond.First(g.Build, spec.Build).(string)
+	err := gen.init(outfile, func(string) (*bytes.Buffer, error) { return current, nil })
+	if err != nil {
+		return err
+	}
+	t, err := gen.getOrMakeTarget(outfile, ``)
+	if err != nil {
+		return err
+	}
+	err = gen.populateSpec(t, spec)
+	if err != nil {
+		return err
+	}
+	return gen.render(t, nil, output)
+}
+
+// populateSpec adds the interfaces and imports of spec to a target
+func (g *Generate) populateSpec(t *target, spec *model.File) error {
+	for _, i := range spec.Imports {
+		if i.Path == `` {
+			return fmt.Errorf(`%w: import without a path`, ErrSpec)
+		}
+		t.imports[&parser.Import{Name: i.Name, Path: i.Path}] = struct{}{}
+	}
+	for n, i := range spec.Interfaces {
+		if i.Name == `` {
+			return fmt.Errorf(`%w: interfaces[%d]: missing name`, ErrSpec, n)
+		}
+		iface, finish := makeInterface(t.tdata, i.Name, i.Doc, g.NoTDoc, g.docWidth())
+		iface.TypeParams = i.TypeParams
+		for _, e := range i.Embeds {
+			iface.Embed(e)
+		}
+		for k, m := range i.Methods {
+			method, err := g.specMethod(m)
+			if err != nil {
+				return fmt.Errorf(`%w: %s.methods[%d]: %s`, ErrSpec, i.Name, k, err.Error())
+			}
+			err = iface.Add(method)
+			if err != nil {
+				return fmt.Errorf(`%w: %s.%s: %s`, ErrSpec, i.Name, m.Name, err.Error())
+			}
+		}
+		err := finish()
+		if err != nil {
+			return fmt.Errorf(`%w: %s: %s`, ErrSpec, i.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// specMethod creates the template data for a method of a spec
+func (g *Generate) specMethod(m model.Method) (*tdata.Method, error) {
+	if m.Name == `` {
+		return nil, errors.New(`missing name`)
+	}
+	sig := m.Signature
+	if sig == `` {
+		params, err := specParams(m.Params, false)
+		if err != nil {
+			return nil, err
+		}
+		results, err := specParams(m.Results, true)
+		if err != nil {
+			return nil, err
+		}
+		sig = m.Name + params + results
+	} else if !strings.HasPrefix(sig, m.Name+`(`) {
+		return nil, fmt.Errorf(`signature "%s" does not match the name %s`, sig, m.Name)
+	}
+	method := tdata.NewMethod(m.Name, sig, m.Doc, g.NoFDoc, g.docWidth())
+	method.Params = newSpecParams(m.Params)
+	method.Results = newSpecParams(m.Results)
+	method.Recv = m.Recv
+	if m.Source != nil {
+		method.Source = m.Source.Type
+		method.File = m.Source.File
+		method.Line = m.Source.Line
+	}
+	return method, nil
+}
+
+// specParams returns a parameter or result list. Parameters are either all
+// named or all unnamed and only the last parameter can be variadic.
+func specParams(params []model.Param, results bool) (string, error) {
+	var l []string
+	named := 0
+	for i, p := range params {
+		if p.Type == `` {
+			return ``, fmt.Errorf(`parameter %d: missing type`, i)
+		}
+		typ := p.Type
+		if p.Variadic {
+			if results || i != len(params)-1 {
+				return ``, fmt.Errorf(`parameter %d: only the last parameter can be variadic`, i)
+			}
+			typ = `...` + typ
+		}
+		if p.Name != `` {
+			named++
+			typ = p.Name + ` ` + typ
+		}
+		l = append(l, typ)
+	}
+	if named != 0 && named != len(params) {
+		return ``, errors.New(`parameters must be all named or all unnamed`)
+	}
+	switch {
+	case !results:
+		return `(` + strings.Join(l, `, `) + `)`, nil
+	case len(l) == 0:
+		return ``, nil
+	case len(l) == 1 && named == 0:
+		return ` ` + l[0], nil
+	}
+	return ` (` + strings.Join(l, `, `) + `)`, nil
+}
+
+func newSpecParams(params []model.Param) (out []tdata.Param) {
+	for _, p := range params {
+		out = append(out, tdata.Param{Name: p.Name, Type: p.Type, Variadic: p.Variadic, Pkg: p.Pkg})
+	}
+	return
+}
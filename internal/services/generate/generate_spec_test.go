@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var specYAML = `package: ports
+imports:
+  - path: context
+  - name: m
+    path: github.com/example/models
+interfaces:
+  - name: UserPort
+    doc: UserPort reads and writes users.
+    embeds: [io.Closer]
+    methods:
+      - name: Get
+        doc: Get gets a user.
+        params:
+          - {name: ctx, type: context.Context}
+          - {name: id, type: string}
+        results:
+          - {type: "*m.User"}
+          - {type: error}
+      - name: Tag
+        params:
+          - {name: tags, type: string, variadic: true}
+      - name: Count
+        signature: Count() int
+`
+
+func TestGenerator_Spec(t *testing.T) {
+	spec, err := ReadSpec(strings.NewReader(specYAML))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen := &Generate{
+		Comment: comment,
+	}
+	out := &bytes.Buffer{}
+	err = gen.GenerateSpec(spec, &bytes.Buffer{}, ``, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package ports
+
+import (
+	"context"
+	"io"
+
+	m "github.com/example/models"
+)
+
+// UserPort reads and writes users.
+type UserPort interface {
+	io.Closer
+	// Get gets a user.
+	Get(ctx context.Context, id string) (*m.User, error)
+	Tag(tags ...string)
+	Count() int
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestGenerator_Spec_RoundTrip(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `store`,
+	}
+	srcs := []srcio.Source{{File: `store.go`, Src: srcJSON}}
+	goOut := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, goOut)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen.Format = FormatJSON
+	jsonOut := &bytes.Buffer{}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `store_iface.json`, jsonOut)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	spec, err := ReadSpec(jsonOut)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	specOut := &bytes.Buffer{}
+	err = (&Generate{Comment: comment}).GenerateSpec(spec, &bytes.Buffer{}, ``, specOut)
+	if assert.NoError(t, err) {
+		assert.Equal(t, goOut.String(), specOut.String())
+	}
+}
+
+func TestGenerator_Spec_Errors(t *testing.T) {
+	_, err := ReadSpec(strings.NewReader("version: 2\n"))
+	assert.ErrorIs(t, err, ErrSpecVersion)
+	_, err = ReadSpec(strings.NewReader("interfacez: []\n"))
+	assert.ErrorIs(t, err, ErrSpec)
+
+	for _, tc := range []struct {
+		spec string
+		err  string
+	}{
+		{"interfaces:\n  - methods: []\n", `interfaces[0]: missing name`},
+		{"interfaces:\n  - name: I\n    methods:\n      - params: [{type: int}]\n", `I.methods[0]: missing name`},
+		{"interfaces:\n  - name: I\n    methods:\n      - name: M\n        params: [{name: a, type: int}, {type: int}]\n", `must be all named or all unnamed`},
+		{"interfaces:\n  - name: I\n    methods:\n      - name: M\n        params: [{type: int, variadic: true}, {type: int}]\n", `only the last parameter can be variadic`},
+		{"interfaces:\n  - name: I\n    methods:\n      - name: M\n        signature: N()\n", `does not match the name`},
+	} {
+		spec, err := ReadSpec(strings.NewReader("package: p\n" + tc.spec))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		err = (&Generate{}).GenerateSpec(spec, &bytes.Buffer{}, ``, &bytes.Buffer{})
+		if assert.ErrorIs(t, err, ErrSpec, tc.spec) {
+			assert.Contains(t, err.Error(), tc.err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/types"
+)
+
+// builtinSrc declares the builtin interfaces which can be embedded
+const builtinSrc = `package builtin
+
+type error interface {
+	Error() string
+}
+`
+
+// typeMethods returns the exported methods of typ. The methods of an
+// interface declaration are its methods and the methods of the interfaces it
+// embeds, otherwise they are the receiver methods of typ.
+func (g *Generate) typeMethods(p *parser.Parser, typ parser.Type, iface string) ([]*parser.Method, error) {
+	if typ.Type != types.INTERFACE {
+		g.explainUnexported(p, typ.Name, iface)
+		return parser.NewQuery(p).GetRecvsByType(typ.Name), nil
+	}
+	e := &embedder{
+		g:     g,
+		iface: iface,
+		q:     parser.NewQuery(p),
+		seen:  map[string]bool{},
+	}
+	err := e.expand(typ)
+	if err != nil {
+		return nil, err
+	}
+	return e.methods, nil
+}
+
+// embedder expands the embedded interfaces of an interface declaration
+type embedder struct {
+	g       *Generate
+	iface   string
+	q       *parser.Query
+	seen    map[string]bool
+	methods []*parser.Method
+}
+
+// expand adds the methods of the interfaces embedded in typ followed by the
+// methods of typ. Embedded interfaces are only expanded once.
+func (e *embedder) expand(typ parser.Type) error {
+	if e.seen[typ.Name] {
+		return nil
+	}
+	e.seen[typ.Name] = true
+	for _, name := range typ.Embeds {
+		if name == `error` {
+			err := e.builtin(name)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		embedded := e.q.GetTypeByName(name)
+		if embedded == nil || embedded.Type != types.INTERFACE {
+			e.g.report(diag.New(diag.Warning, diag.CodeEmbedNotFound, diag.Position{File: typ.File, Line: typ.Line},
+				fmt.Sprintf(`%s: embedded interface %s of %s is not declared in the source files, its methods are left out`, e.iface, name, typ.Name),
+			).WithFix(`add the source files of ` + name + ` with -f or generate the interface from ` + name + ` separately`))
+			continue
+		}
+		e.g.debugf(`%s: %s embeds %s`, e.iface, typ.Name, name)
+		err := e.expand(*embedded)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range e.q.GetIfaceMethods(typ.Name) {
+		if !match.Capitalized(m.Name) {
+			e.g.debugf(`%s: %s.%s excluded, not exported`, e.iface, m.TypeName, m.Name)
+			continue
+		}
+		e.methods = append(e.methods, m)
+	}
+	return nil
+}
+
+// builtin adds the methods of a builtin interface
+func (e *embedder) builtin(name string) error {
+	if e.seen[name] {
+		return nil
+	}
+	p, err := parser.Parse(`builtin.go`, builtinSrc, 0)
+	if err != nil {
+		return diag.Errorf(diag.CodeError, diag.Position{File: `builtin.go`}, `can not parse the builtin interfaces: %w`, err)
+	}
+	q := parser.NewQuery(p)
+	if typ := q.GetTypeByName(name); typ != nil {
+		e.seen[name] = true
+		e.methods = append(e.methods, q.GetIfaceMethods(name)...)
+	}
+	return nil
+}
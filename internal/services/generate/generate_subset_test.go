@@ -0,0 +1,102 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcSubset = `package vendor
+
+import (
+	"context"
+	"fmt"
+)
+
+// Client calls the vendor API
+type Client interface {
+	Base
+	fmt.Stringer
+	// Get gets an object
+	Get(ctx context.Context, in *GetInput) (*GetOutput, error)
+	Put(ctx context.Context, in *PutInput) error
+	internal()
+}
+
+type Base interface {
+	error
+	Close() error
+}
+
+type GetInput struct{}
+
+type GetOutput struct{}
+
+type PutInput struct{}
+`
+
+func TestGenerator_Type_Subset(t *testing.T) {
+	reporter := &diag.Reporter{}
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `ObjectGetter`,
+		MatchType: `Client`,
+		MatchFunc: `Get,Close,Err*`,
+		Pkg:       `domain`,
+		NoTDoc:    true,
+		Reporter:  reporter,
+	}
+	srcs := []srcio.Source{{File: `client.go`, Src: srcSubset}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `getter.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package domain
+
+import "context"
+
+type ObjectGetter interface {
+	Error() string
+	Close() error
+	// Get gets an object
+	Get(ctx context.Context, in *vendor.GetInput) (*vendor.GetOutput, error)
+}
+`
+	assert.Equal(t, expected, out.String())
+	l := reporter.List()
+	if assert.Len(t, l, 1) {
+		assert.Equal(t, diag.CodeEmbedNotFound, l[0].Code)
+		assert.Equal(t, `client.go:9: ObjectGetter: embedded interface fmt.Stringer of Client is not declared in the source files, its methods are left out`, l[0].Error())
+	}
+}
+
+func TestGenerator_Type_SubsetAll(t *testing.T) {
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `Client`,
+		MatchType: `Client`,
+		Pkg:       `vendor`,
+		NoTDoc:    true,
+		Exclude:   []string{`Error`},
+	}
+	srcs := []srcio.Source{{File: `client.go`, Src: srcSubset}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `client_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `type Client interface {
+	Close() error
+	// Get gets an object
+	Get(ctx context.Context, in *GetInput) (*GetOutput, error)
+	Put(ctx context.Context, in *PutInput) error
+}`)
+}
@@ -3,6 +3,9 @@ package generate
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dexterp/ifaces/internal/resources/print"
@@ -629,3 +632,274 @@ type ServiceIface interface {
 	assert.NoError(t, err)
 	assert.Equal(t, expected, out.String())
 }
+
+func TestGenerator_GenerateFiles(t *testing.T) {
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Print: print.New(print.Options{
+			Exit: print.PANIC,
+		}),
+		Pkg:     pkg,
+		Post:    `Iface`,
+		Struct:  true,
+		OutTmpl: `{{ .Type | snake }}_iface.go`,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `test_ifaces.go`,
+			Src:  src1,
+		},
+	}
+	files, err := gen.GenerateFiles(srcs, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, files, 3) {
+		t.FailNow()
+	}
+	assert.Contains(t, files, `some_struct_iface.go`)
+	assert.Contains(t, files, `ignore_struct_iface.go`)
+	expected := fmt.Sprintf(`// DO NOT EDIT
+
+package %s
+
+// MyStructIface type document
+type MyStructIface interface {
+	// Get func doc
+	Get() (item originpkg.Data)
+	// Set func doc
+	Set(item originpkg.Data)
+}
+`, pkg)
+	assert.Equal(t, expected, files[`my_struct_iface.go`].String())
+	assert.NotContains(t, files[`ignore_struct_iface.go`].String(), `SomeStruct`)
+	assert.Contains(t, files[`some_struct_iface.go`].String(), `import "io"`)
+	assert.NotContains(t, files[`ignore_struct_iface.go`].String(), `import "io"`)
+}
+
+func TestGenerator_GenerateFiles_Append(t *testing.T) {
+	gen := &Generate{
+		Type:    true,
+		Comment: comment,
+		Print: print.New(print.Options{
+			Exit: print.PANIC,
+		}),
+		Pkg:       pkg,
+		Post:      `Iface`,
+		MatchType: `IgnoreStruct`,
+		OutTmpl:   `{{ .Iface | snake }}.go`,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `test_ifaces.go`,
+			Src:  src1,
+		},
+	}
+	current := func(file string) (*bytes.Buffer, error) {
+		assert.Equal(t, `ignore_struct_iface.go`, file)
+		return bytes.NewBufferString(fmt.Sprintf("// DO NOT EDIT\n\npackage %s\n\ntype Iface interface {\n\tClose() error\n}\n", pkg)), nil
+	}
+	files, err := gen.GenerateFiles(srcs, current)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := fmt.Sprintf(`// DO NOT EDIT
+
+package %s
+
+type Iface interface {
+	Close() error
+}
+
+// IgnoreStructIface type document
+type IgnoreStructIface interface {
+	// Connect connect func document
+	Connect(connetstr string)
+}
+`, pkg)
+	assert.Equal(t, expected, files[`ignore_struct_iface.go`].String())
+}
+
+func TestGenerator_Template(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), `methods.gotmpl`)
+	err := os.WriteFile(tmpl, []byte(`{{ range .Ifaces }}# {{ .Type.Name }} ({{ .Source }} {{ .File }}:{{ .Line }})
+{{ range .Methods }}- {{ .Name }} {{ .Recv }} {{ .Source }}:{{ .Line }}
+{{ range .Params }}  - param {{ .Name }} {{ .Type }} {{ .Variadic }} {{ .Pkg }} {{ zero .Type }}
+{{ end }}{{ range .Results }}  - result {{ .Type | snake }} {{ zero .Type }}
+{{ end }}{{ end }}{{ end }}`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen := &Generate{
+		Type:      true,
+		Comment:   comment,
+		Iface:     `Iface`,
+		MatchType: `SomeStruct`,
+		Pkg:       `originpkg`,
+		Template:  tmpl,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `test.go`,
+			Src:  src1,
+		},
+	}
+	out := &bytes.Buffer{}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `methods.md`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `# Iface (SomeStruct test.go:26)
+- AddData pointer SomeStruct:30
+  - param d Data true  *new(Data)
+- Add pointer SomeStruct:34
+  - param d any true  nil
+- Collate pointer SomeStruct:38
+  - param in []*Data false  nil
+  - result error nil
+- Scan value SomeStruct:43
+  - param in io.Reader false io *new(io.Reader)
+  - result error nil
+`
+	assert.Equal(t, expected, out.String()[:len(expected)])
+}
+
+func TestGenerator_Template_Imports(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), `imports.gotmpl`)
+	err := os.WriteFile(tmpl, []byte(`{{ range .Imports }}{{ .Path }}
+{{ end }}`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gen := &Generate{
+		Type:      true,
+		Iface:     `StoreIface`,
+		MatchType: `Store`,
+		Pkg:       `store`,
+		Template:  tmpl,
+	}
+	srcs := []srcio.Source{
+		{
+			File: `a.go`,
+			Src: `package store
+
+import "context"
+
+type Store struct{}
+
+func (s *Store) Get(ctx context.Context, id string) error { return nil }
+`,
+		},
+		{
+			File: `b.go`,
+			Src: `package store
+
+import (
+	"context"
+	"io"
+)
+
+func (s *Store) Put(ctx context.Context, r io.Reader) error { return nil }
+`,
+		},
+	}
+	out := &bytes.Buffer{}
+	err = gen.Generate(srcs, &bytes.Buffer{}, `imports.txt`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "context\nio\n", out.String())
+}
+
+func TestGenerator_Type_Filters(t *testing.T) {
+	store := `package originpkg
+
+// Store store
+type Store struct {
+}
+
+// Get get
+func (s *Store) Get() {}
+
+// GetAll get all
+func (s *Store) GetAll() {}
+
+// List list
+//
+//ifaces:ignore StoreIface
+func (s *Store) List() {}
+
+// Delete delete
+func (s *Store) Delete() {}
+
+// String string
+//
+//ifaces:ignore
+func (s *Store) String() string { return "" }
+
+// Ignored ignored
+//
+//ifaces:ignore
+type Ignored struct {
+}
+
+// Close close
+func (i *Ignored) Close() {}
+`
+	helper := `package originpkg
+
+// Reset test helper
+func (s *Store) Reset() {}
+`
+	srcs := []srcio.Source{
+		{File: `store.go`, Src: store},
+		{File: `store_helper.go`, Src: helper},
+	}
+	tests := []struct {
+		name     string
+		gen      *Generate
+		expected []string
+	}{
+		{
+			name:     `ignore`,
+			gen:      &Generate{Post: `Iface`},
+			expected: []string{`Get()`, `GetAll()`, `Delete()`, `Reset()`},
+		},
+		{
+			name:     `ignore iface`,
+			gen:      &Generate{Post: `Reader`},
+			expected: []string{`Get()`, `GetAll()`, `List()`, `Delete()`, `Reset()`},
+		},
+		{
+			name:     `include exclude`,
+			gen:      &Generate{Post: `Iface`, Include: []string{`Get*`, `Delete`}, Exclude: []string{`GetAll`}},
+			expected: []string{`Get()`, `Delete()`},
+		},
+		{
+			name:     `from files`,
+			gen:      &Generate{Post: `Iface`, FromFiles: []string{`store.go`}},
+			expected: []string{`Get()`, `GetAll()`, `Delete()`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.gen.Struct = true
+			tt.gen.Pkg = `originpkg`
+			out := &bytes.Buffer{}
+			err := tt.gen.Generate(srcs, &bytes.Buffer{}, `store_iface.go`, out)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.NotContains(t, out.String(), `Ignored`)
+			assert.NotContains(t, out.String(), `String()`)
+			var methods []string
+			for _, line := range strings.Split(out.String(), "\n") {
+				if strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "\t//") {
+					methods = append(methods, strings.TrimSpace(line))
+				}
+			}
+			assert.Equal(t, tt.expected, methods)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package generate
+
+import (
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/types"
+)
+
+// transitiveTypes adds the concrete types returned by the methods of types,
+// recursively, if they are declared in the parsed source and have exported
+// methods. The interface names are returned keyed by the type name. name
+// returns the interface name of a type in types.
+func (g *Generate) transitiveTypes(p *parser.Parser, roots []parser.Type, name func(typ string) string) ([]parser.Type, map[string]string, error) {
+	q := parser.NewQuery(p)
+	names := map[string]string{}
+	for _, typ := range roots {
+		names[typ.Name] = name(typ.Name)
+	}
+	g.transitive = map[string]bool{}
+	out := append([]parser.Type{}, roots...)
+	for i := 0; i < len(out); i++ {
+		typ := out[i]
+		methods, err := g.typeMethods(p, typ, names[typ.Name])
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range methods {
+			for _, r := range m.ResultTypes() {
+				if _, ok := names[r]; ok {
+					continue
+				}
+				returned := q.GetTypeByName(r)
+				if returned == nil || returned.Type == types.INTERFACE || returned.TypeParams != `` || len(q.GetRecvsByType(r)) == 0 {
+					continue
+				}
+				iface := g.transitiveName(r)
+				if returned.Directives.Ignore(iface) {
+					g.debugf(`%s: type %s skipped, ifaces:ignore directive`, iface, r)
+					continue
+				}
+				g.debugf(`%s: type %s added, returned by %s.%s`, iface, r, m.TypeName, m.Name)
+				names[r] = iface
+				g.transitive[iface] = true
+				out = append(out, *returned)
+			}
+		}
+	}
+	return out, names, nil
+}
+
+// transitiveName returns the interface name of a type added by Transitive
+func (g Generate) transitiveName(typ string) string {
+	if g.Pre == `` && g.Post == `` {
+		return typ + `Iface`
+	}
+	return g.Pre + typ + g.Post
+}
+
+// substituteTypes replaces the types in the signatures of methods which have an
+// interface in names by the interface.
+func substituteTypes(methods []*parser.Method, names map[string]string) ([]*parser.Method, error) {
+	subs := map[string]string{}
+	for typ, iface := range names {
+		subs[typ] = iface
+		subs[`*`+typ] = iface
+	}
+	s, err := parser.NewSubstitution(subs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*parser.Method, len(methods))
+	for i, m := range methods {
+		out[i] = s.Apply(m)
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/stretchr/testify/assert"
+)
+
+var srcTransitive = `package originpkg
+
+import "context"
+
+// DB database
+type DB struct{}
+
+// Begin starts a transaction
+func (d *DB) Begin(ctx context.Context) (*Tx, error) { return nil, nil }
+
+// Query starts a query
+func (d *DB) Query() *Builder { return nil }
+
+// Tx transaction
+type Tx struct{}
+
+func (t *Tx) Commit() error { return nil }
+
+func (t *Tx) Rows() []*Row { return nil }
+
+type Row struct{}
+
+func (r Row) Scan(dst ...any) error { return nil }
+
+// Builder query builder
+type Builder struct{}
+
+func (b *Builder) Where(cond string) *Builder { return b }
+
+// Ignored is not added
+//
+//ifaces:ignore
+type Ignored struct{}
+
+func (i Ignored) Name() string { return "" }
+
+func (b *Builder) Ignored() Ignored { return Ignored{} }
+`
+
+func TestGenerator_Type_Transitive(t *testing.T) {
+	gen := &Generate{
+		Type:       true,
+		Comment:    comment,
+		Iface:      `DBIface`,
+		MatchType:  `DB`,
+		Pkg:        pkg,
+		NoTDoc:     true,
+		NoFDoc:     true,
+		Transitive: true,
+	}
+	srcs := []srcio.Source{{File: `db.go`, Src: srcTransitive}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `db_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package mypkg
+
+import "context"
+
+type DBIface interface {
+	Begin(ctx context.Context) (TxIface, error)
+	Query() BuilderIface
+}
+
+type TxIface interface {
+	Commit() error
+	Rows() []RowIface
+}
+
+type BuilderIface interface {
+	Where(cond string) BuilderIface
+	Ignored() originpkg.Ignored
+}
+
+type RowIface interface {
+	Scan(dst ...any) error
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestGenerator_Type_TransitiveSuffix(t *testing.T) {
+	gen := &Generate{
+		Type:       true,
+		Comment:    comment,
+		MatchType:  `Builder`,
+		Pkg:        pkg,
+		Post:       `API`,
+		NoTDoc:     true,
+		Transitive: true,
+		MatchFunc:  `Where`,
+	}
+	srcs := []srcio.Source{{File: `db.go`, Src: srcTransitive}}
+	out := &bytes.Buffer{}
+	err := gen.Generate(srcs, &bytes.Buffer{}, `db_iface.go`, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `type BuilderAPI interface {
+	Where(cond string) BuilderAPI
+}`)
+}
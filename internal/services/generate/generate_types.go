@@ -5,16 +5,30 @@ import (
 	"io"
 
 	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
 	"github.com/dexterp/ifaces/internal/resources/tdata"
 )
 
 // target represents the target file
 type target struct {
 	file     string                 // Output file
+	build    string                 // Build constraint of the current source if any
 	src      *bytes.Buffer          // Current source if any
 	pkg      string                 // Package name
 	exported bool                   // True if the source file is exported.
 	imports  map[*parser.Import]any // Imports
 	tdata    *tdata.TData           // Template data
+	excluded []Exclusion            // Methods left out of the common interface
 	output   io.Writer
 }
+
+// outTmplData data passed to the output file name template
+type outTmplData struct {
+	Type  string // Type name of the source type
+	Iface string // Iface name of the generated interface
+	Pkg   string // Pkg package name of the source type
+}
+
+// ParseFunc parses source files. Implementations can return a previously
+// parsed result as the generator does not modify it.
+type ParseFunc func(srcs []srcio.Source) (*parser.Parser, error)
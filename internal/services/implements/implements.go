@@ -0,0 +1,191 @@
+// Package implements reports the types which implement interfaces.
+package implements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/methodset"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+)
+
+//go:generate ifaces type -o implements_iface.go -i ImplementsIface
+
+// Output formats
+const (
+	FormatText = `text`
+	FormatJSON = `json`
+)
+
+// Formats output formats
+var Formats = []string{FormatText, FormatJSON}
+
+var (
+	ErrFormat        = errors.New(`invalid implements format`)
+	ErrIfaceNotFound = errors.New(`could not match interface`)
+	ErrTypeNotFound  = errors.New(`could not match type`)
+)
+
+// Implements lists implementations of interfaces
+type Implements struct {
+	Args     *cli.Args        // Args options of the implements sub command
+	Print    print.PrintIface // Print handler
+	Reporter *diag.Reporter   // Reporter collects warnings instead of printing them with Print
+}
+
+// Report result of a type and an interface. A type which does not implement
+// the interface has one of Pointer, Missing or Has and Want set.
+type Report struct {
+	Type       string `json:"type"`              // Type qualified type name, E.G. "*print.Print" for the pointer method set
+	Interface  string `json:"interface"`         // Interface qualified interface name, E.G. "print.PrintIface"
+	Position   string `json:"position"`          // Position position of the type, or of the method of Pointer or Has
+	Implements bool   `json:"implements"`        // Implements true if the type implements the interface
+	Pointer    string `json:"pointer,omitempty"` // Pointer method with a pointer receiver, the pointer type implements the interface
+	Missing    string `json:"missing,omitempty"` // Missing method of the interface the type does not have
+	Has        string `json:"has,omitempty"`     // Has method of the type with a different signature
+	Want       string `json:"want,omitempty"`    // Want method of the interface Has is compared to
+}
+
+// List writes the types implementing the interface -i, or the interfaces
+// implemented by the type -t, in the packages matching patterns in the
+// --format format. A type which misses one method of an interface with more
+// than one method, or has one method with a different signature, is listed as
+// a near miss with the method.
+func (i Implements) List(patterns []string, output io.Writer) error {
+	format := cond.First(i.Args.Format, FormatText).(string)
+	if !cond.EqualAnyString(format, Formats...) {
+		return diag.Errorf(diag.CodeUsage, diag.Position{}, `%w "%s", expected one of %s`, ErrFormat, format, strings.Join(Formats, `, `))
+	}
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return err
+	}
+	pkgs, err := methodset.Load(dirs)
+	if err != nil {
+		return err
+	}
+	ifaces, concretes := methodset.Declarations(pkgs, func(dg *diag.Diagnostic) { diag.Warn(i.Reporter, i.Print, dg) })
+	var reports []Report
+	if i.Args.Iface != `` {
+		reports, err = i.types(ifaces, concretes)
+	} else {
+		reports, err = i.ifaces(ifaces, concretes)
+	}
+	if err != nil {
+		return err
+	}
+	return write(output, format, reports)
+}
+
+// types returns the reports of the types and the interfaces matching -i
+func (i Implements) types(ifaces []*methodset.Iface, concretes []*methodset.Concrete) (reports []Report, err error) {
+	var found bool
+	for _, ifc := range ifaces {
+		if !methodset.Match(ifc.Pkg, ifc.Type.Name, i.Args.Iface) {
+			continue
+		}
+		found = true
+		diag.Explainf(i.Print, `interface %s selected at %s`, ifc, ifc.Pos())
+		for _, c := range concretes {
+			if r := i.report(c, ifc, methodset.Compare(c, ifc, true), methodset.Compare(c, ifc, false)); r != nil {
+				reports = append(reports, *r)
+			}
+		}
+	}
+	if !found {
+		return nil, diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w %s`, ErrIfaceNotFound, i.Args.Iface).
+			WithFix(`name an interface with methods declared in the packages, E.G. PrintIface or print.PrintIface`)
+	}
+	return reports, nil
+}
+
+// ifaces returns the reports of the types matching -t and the interfaces. A
+// pointer type, E.G. *Print, has the methods of both pointer and value
+// receivers.
+func (i Implements) ifaces(ifaces []*methodset.Iface, concretes []*methodset.Concrete) (reports []Report, err error) {
+	ptr := strings.HasPrefix(i.Args.MatchType, `*`)
+	var found bool
+	for _, c := range concretes {
+		if !methodset.Match(c.Pkg, c.Type.Name, strings.TrimPrefix(i.Args.MatchType, `*`)) {
+			continue
+		}
+		found = true
+		diag.Explainf(i.Print, `type %s selected at %s`, c, c.Pos())
+		for _, ifc := range ifaces {
+			ptrRes := methodset.Compare(c, ifc, true)
+			valRes := ptrRes
+			if !ptr {
+				valRes = methodset.Compare(c, ifc, false)
+			}
+			if r := i.report(c, ifc, ptrRes, valRes); r != nil {
+				reports = append(reports, *r)
+			}
+		}
+	}
+	if !found {
+		return nil, diag.Errorf(diag.CodeTypeNotFound, diag.Position{}, `%w %s`, ErrTypeNotFound, i.Args.MatchType).
+			WithFix(`name a type with methods declared in the packages, E.G. *Print or *print.Print`)
+	}
+	return reports, nil
+}
+
+// report returns the report of a type and an interface, or nil if the type is
+// not a near miss. ptr is the result of the pointer method set and val the
+// result of the value method set.
+func (i Implements) report(c *methodset.Concrete, ifc *methodset.Iface, ptr, val methodset.Result) *Report {
+	r := &Report{Type: c.String(), Interface: ifc.String(), Position: c.Pos().String()}
+	// any type missing the method of a one method interface would be listed
+	near := len(ifc.Methods) > 1
+	switch {
+	case val.Ok() && strings.HasPrefix(i.Args.MatchType, `*`):
+		r.Type, r.Implements = `*`+r.Type, true
+	case val.Ok():
+		r.Implements = true
+	case ptr.Ok() && !strings.HasPrefix(i.Args.MatchType, `*`) && i.Args.MatchType != ``:
+		r.Position, r.Pointer = val.Pointer[0].Pos.String(), val.Pointer[0].Name
+	case ptr.Ok():
+		r.Type, r.Implements = `*`+r.Type, true
+	case near && len(ptr.Missing) == 1 && len(ptr.Mismatched) == 0:
+		r.Missing = ptr.Missing[0].Display
+	case len(ptr.Missing) == 0 && len(ptr.Mismatched) == 1:
+		m := ptr.Mismatched[0]
+		r.Position, r.Has, r.Want = m[0].Pos.String(), m[0].Display, m[1].Display
+	default:
+		diag.Explainf(i.Print, `%s does not implement %s, %d methods missing and %d different`, r.Type, r.Interface, len(ptr.Missing), len(ptr.Mismatched))
+		return nil
+	}
+	return r
+}
+
+// write writes the reports in format
+func write(output io.Writer, format string, reports []Report) error {
+	if format == FormatJSON {
+		if reports == nil {
+			reports = []Report{}
+		}
+		enc := json.NewEncoder(output)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(reports)
+	}
+	for _, r := range reports {
+		switch {
+		case r.Implements:
+			fmt.Fprintf(output, "%s: %s implements %s\n", r.Position, r.Type, r.Interface)
+		case r.Pointer != ``:
+			fmt.Fprintf(output, "%s: %s does not implement %s, %s has a pointer receiver, *%s implements %s\n",
+				r.Position, r.Type, r.Interface, r.Pointer, r.Type, r.Interface)
+		case r.Missing != ``:
+			fmt.Fprintf(output, "%s: %s does not implement %s, missing %s\n", r.Position, r.Type, r.Interface, r.Missing)
+		default:
+			fmt.Fprintf(output, "%s: %s does not implement %s, has %s want %s\n", r.Position, r.Type, r.Interface, r.Has, r.Want)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package implements
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/stretchr/testify/assert"
+)
+
+var printSrc = `package print
+
+import "io"
+
+type Writer interface {
+	Write(p []byte) (int, error)
+}
+
+// PrintIface prints
+type PrintIface interface {
+	Writer
+	Infof(format string, a ...any)
+	Warnf(format string, a ...any)
+}
+
+type Print struct{}
+
+func (p *Print) Write(b []byte) (n int, err error) { return }
+
+func (p *Print) Infof(format string, a ...any) {}
+
+func (p Print) Warnf(format string, a ...any) {}
+
+type Short struct{}
+
+func (s Short) Write(p []byte) (int, error) { return 0, nil }
+
+func (s Short) Infof(format string, a ...any) {}
+
+type Other struct{}
+
+func (o Other) Write(p []byte) (int, error) { return 0, nil }
+
+func (o Other) Infof(format string, a ...any) {}
+
+func (o Other) Warnf(msg string) {}
+
+type Value struct{}
+
+func (v Value) Write(p []byte) (int, error) { return 0, nil }
+
+func (v Value) Infof(format string, a ...any) {}
+
+func (v Value) Warnf(format string, args ...any) {}
+
+type Closer interface {
+	io.Closer
+	Flush() error
+}
+`
+
+var logSrc = `package log
+
+import "example.com/app/print"
+
+type Logger struct{}
+
+func (l *Logger) Write(p []byte) (int, error) { return 0, nil }
+
+func (l *Logger) Infof(format string, a ...any) {}
+
+func (l *Logger) Warnf(format string, a ...any) {}
+
+func (l *Logger) Printer() print.PrintIface { return nil }
+
+type Printer interface {
+	print.Writer
+	Printer() print.PrintIface
+}
+`
+
+func list(t *testing.T, args *cli.Args, reporter *diag.Reporter) string {
+	root := testsrcs.Write(t, map[string]string{
+		`print/print.go`: printSrc,
+		`log/log.go`:     logSrc,
+	})
+	i := Implements{Args: args, Reporter: reporter}
+	out := &bytes.Buffer{}
+	err := i.List([]string{filepath.Join(root, `...`)}, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return strings.ReplaceAll(out.String(), root+string(filepath.Separator), ``)
+}
+
+func TestImplements_List_Iface(t *testing.T) {
+	reporter := &diag.Reporter{}
+	out := list(t, &cli.Args{Iface: `PrintIface`}, reporter)
+	expected := strings.Join([]string{
+		filepath.Join(`log`, `log.go`) + `:5: *log.Logger implements print.PrintIface`,
+		filepath.Join(`print`, `print.go`) + `:16: *print.Print implements print.PrintIface`,
+		filepath.Join(`print`, `print.go`) + `:24: print.Short does not implement print.PrintIface, missing Warnf(format string, a ...any)`,
+		filepath.Join(`print`, `print.go`) + `:36: print.Other does not implement print.PrintIface, has Warnf(msg string) want Warnf(format string, a ...any)`,
+		filepath.Join(`print`, `print.go`) + `:38: print.Value implements print.PrintIface`,
+	}, "\n") + "\n"
+	assert.Equal(t, expected, out)
+	assert.Empty(t, reporter.List())
+}
+
+func TestImplements_List_JSON(t *testing.T) {
+	var reports []Report
+	err := json.Unmarshal([]byte(list(t, &cli.Args{Format: FormatJSON, MatchType: `print.Print`}, nil)), &reports)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := []Report{
+		{
+			Type:      `print.Print`,
+			Interface: `log.Printer`,
+			Position:  filepath.Join(`print`, `print.go`) + `:16`,
+			Missing:   `Printer() print.PrintIface`,
+		},
+		{
+			Type:      `print.Print`,
+			Interface: `print.Writer`,
+			Position:  filepath.Join(`print`, `print.go`) + `:18`,
+			Pointer:   `Write`,
+		},
+		{
+			Type:      `print.Print`,
+			Interface: `print.PrintIface`,
+			Position:  filepath.Join(`print`, `print.go`) + `:18`,
+			Pointer:   `Write`,
+		},
+	}
+	assert.Equal(t, expected, reports)
+
+	root := testsrcs.Write(t, map[string]string{`print/print.go`: printSrc})
+	i := Implements{Args: &cli.Args{Format: `yaml`, Iface: `PrintIface`}}
+	err = i.List([]string{root}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFormat)
+}
+
+func TestImplements_List_Imported(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`: "module example.com/app\n\ngo 1.19\n",
+		`rc/rc.go`: `package rc
+
+import (
+	"io"
+
+	"example.com/app/flush"
+)
+
+type RC interface {
+	io.Reader
+	flush.Flusher
+	Close() error
+}
+
+type File struct{}
+
+func (f *File) Read(b []byte) (int, error) { return 0, nil }
+
+func (f *File) Flush() error { return nil }
+
+func (f *File) Close() error { return nil }
+`,
+		`flush/flush.go`: "package flush\n\ntype Flusher interface {\n\tFlush() error\n}\n",
+	})
+	reporter := &diag.Reporter{}
+	i := Implements{Args: &cli.Args{Iface: `RC`}, Reporter: reporter}
+	out := &bytes.Buffer{}
+	err := i.List([]string{filepath.Join(root, `rc`)}, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, filepath.Join(root, `rc`, `rc.go`)+":15: *rc.File implements rc.RC\n", out.String())
+	assert.Empty(t, reporter.List())
+}
+
+func TestImplements_List_Type(t *testing.T) {
+	out := list(t, &cli.Args{MatchType: `*Logger`}, nil)
+	expected := strings.Join([]string{
+		filepath.Join(`log`, `log.go`) + `:5: *log.Logger implements log.Printer`,
+		filepath.Join(`log`, `log.go`) + `:5: *log.Logger implements print.Writer`,
+		filepath.Join(`log`, `log.go`) + `:5: *log.Logger implements print.PrintIface`,
+	}, "\n") + "\n"
+	assert.Equal(t, expected, out)
+
+	out = list(t, &cli.Args{MatchType: `print.Print`}, nil)
+	expected = strings.Join([]string{
+		filepath.Join(`print`, `print.go`) + `:16: print.Print does not implement log.Printer, missing Printer() print.PrintIface`,
+		filepath.Join(`print`, `print.go`) + `:18: print.Print does not implement print.Writer, Write has a pointer receiver, *print.Print implements print.Writer`,
+		filepath.Join(`print`, `print.go`) + `:18: print.Print does not implement print.PrintIface, Write has a pointer receiver, *print.Print implements print.PrintIface`,
+	}, "\n") + "\n"
+	assert.Equal(t, expected, out)
+}
+
+func TestImplements_List_NotFound(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{`print/print.go`: printSrc})
+	i := Implements{Args: &cli.Args{Iface: `Unknown`}}
+	err := i.List([]string{root + `/...`}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrIfaceNotFound)
+
+	i = Implements{Args: &cli.Args{MatchType: `*Unknown`}}
+	err = i.List([]string{root + `/...`}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrTypeNotFound)
+}
+
+var writerSrc = `package writer
+
+type Writer interface {
+	Write(p []byte) (int, error)
+}
+
+type File struct{}
+
+func (f File) Write(s string) (int, error) { return 0, nil }
+
+type Buffer struct{}
+
+func (b *Buffer) Write(p []byte) (int, error) { return 0, nil }
+
+type Closer struct{}
+
+func (c Closer) Close() error { return nil }
+`
+
+func TestImplements_List_OneMethod(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{`writer/writer.go`: writerSrc})
+	for _, tc := range []struct {
+		args     *cli.Args
+		expected string
+	}{
+		{&cli.Args{Iface: `Writer`}, `writer.go:9: writer.File does not implement writer.Writer, has Write(s string) (int, error) want Write(p []byte) (int, error)
+writer.go:11: *writer.Buffer implements writer.Writer
+`},
+		{&cli.Args{MatchType: `Buffer`}, `writer.go:13: writer.Buffer does not implement writer.Writer, Write has a pointer receiver, *writer.Buffer implements writer.Writer
+`},
+	} {
+		i := Implements{Args: tc.args}
+		out := &bytes.Buffer{}
+		if !assert.NoError(t, i.List([]string{root + `/...`}, out)) {
+			t.FailNow()
+		}
+		assert.Equal(t, tc.expected, strings.ReplaceAll(out.String(), filepath.Join(root, `writer`)+string(filepath.Separator), ``))
+	}
+}
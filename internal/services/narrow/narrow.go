@@ -0,0 +1,348 @@
+// Package narrow generates the minimal interface a consumer needs from a
+// concrete parameter type. The interface has the methods called on the
+// parameter in the function bodies, the "accept interfaces" idiom.
+package narrow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/build/constraint"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/modinfo"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+	"github.com/dexterp/ifaces/internal/resources/uses"
+	"github.com/dexterp/ifaces/internal/services/generate"
+)
+
+//go:generate ifaces type -o narrow_iface.go -i NarrowIface
+
+var (
+	ErrFuncNotFound  = errors.New(`could not match function`)
+	ErrParamNotFound = errors.New(`could not match parameter`)
+	ErrParamType     = errors.New(`unsupported parameter type`)
+	ErrNoMethods     = errors.New(`no methods are called on the parameter`)
+)
+
+// Narrow generates consumer interfaces
+type Narrow struct {
+	Args     *cli.Args                               // Args options of the narrow sub command
+	NewGen   func(args *cli.Args) *generate.Generate // NewGen creates the generator of the interface
+	Print    print.PrintIface                        // Print handler
+	Reporter *diag.Reporter                          // Reporter collects warnings instead of printing them with Print
+}
+
+// pkg parsed consumer package
+type pkg struct {
+	dir    string
+	parsed *uses.Package
+}
+
+// target parameter of a function to narrow
+type target struct {
+	pkg   *pkg
+	fn    *uses.Func
+	param uses.Param
+}
+
+// Generate generates the interface with the methods called on the parameters
+// selected by --func, --param and -t in the packages matching patterns. The
+// interface is generated from the methods of the parameter type in the package
+// declaring it.
+func (n Narrow) Generate(patterns []string, current *bytes.Buffer, outfile string, output io.Writer) error {
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return err
+	}
+	var pkgs []*pkg
+	for _, dir := range dirs {
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		parsed, err := uses.Parse(srcs)
+		if err != nil {
+			return err
+		}
+		pkgs = append(pkgs, &pkg{dir: dir, parsed: parsed})
+	}
+	targets, err := n.targets(pkgs)
+	if err != nil {
+		return err
+	}
+	sel, typ, err := splitType(targets[0].param.Type)
+	if err != nil {
+		return err
+	}
+	called := &calls{n: n, seen: map[string]bool{}}
+	for _, t := range targets {
+		if t.param.Type != targets[0].param.Type {
+			diag.Warn(n.Reporter, n.Print, diag.New(diag.Warning, diag.CodeUntrackedUse, t.fn.Pos,
+				fmt.Sprintf(`%s: parameter %s is a %s, not a %s, it is left out`, t.fn, t.param.Name, t.param.Type, targets[0].param.Type)))
+			continue
+		}
+		called.follow(t.pkg, t.fn, t.param)
+	}
+	dir, err := n.typeDir(targets[0], sel)
+	if err != nil {
+		return err
+	}
+	srcs, err := srcio.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	parsed, err := parser.ParseFiles(srcs)
+	if err != nil {
+		return err
+	}
+	methods := n.methods(parsed, typ, called)
+	if len(methods) == 0 {
+		return diag.Errorf(diag.CodeMethodNotFound, targets[0].fn.Pos, `%w %s`, ErrNoMethods, targets[0].param.Name).
+			WithFix(`check that the function calls methods of ` + typ)
+	}
+	n.ownDoc(parsed, typ, targets)
+	build, err := n.consumerBuild(targets)
+	if err != nil {
+		return err
+	}
+	// The interface is declared in the consumer package, the constraints of the
+	// provider files do not apply.
+	parsed.Builds = nil
+	args := *n.Args
+	if args.Build == `` {
+		args.Build = build
+	}
+	args.CmdNarrow = false
+	args.CmdType = true
+	args.MatchType = typ
+	args.Include = strings.Join(methods, `,`)
+	if args.Iface == `` {
+		args.Iface = n.ifaceName(typ)
+	}
+	if args.Pkg == `` {
+		args.Pkg = targets[0].pkg.parsed.Name
+	}
+	gen := n.NewGen(&args)
+	gen.Parse = func([]srcio.Source) (*parser.Parser, error) {
+		return parsed, nil
+	}
+	return gen.Generate(srcs, current, outfile, output)
+}
+
+// targets returns the parameters selected by the options
+func (n Narrow) targets(pkgs []*pkg) (targets []target, err error) {
+	for _, p := range pkgs {
+		found := len(targets)
+		var fns []*uses.Func
+		if n.Args.Func != `` {
+			fn := p.parsed.Func(n.Args.Func)
+			if fn == nil && strings.HasPrefix(n.Args.Func, p.parsed.Name+`.`) {
+				fn = p.parsed.Func(strings.TrimPrefix(n.Args.Func, p.parsed.Name+`.`))
+			}
+			if fn == nil {
+				continue
+			}
+			fns = append(fns, fn)
+		} else {
+			fns = p.parsed.Funcs
+		}
+		for _, fn := range fns {
+			for _, prm := range fn.Params {
+				if n.Args.Param != `` && prm.Name == n.Args.Param || n.Args.Param == `` && uses.TypeMatch(prm.Type, n.Args.MatchType) {
+					diag.Explainf(n.Print, `%s: parameter %s %s selected at %s`, fn, prm.Name, prm.Type, fn.Pos)
+					targets = append(targets, target{pkg: p, fn: fn, param: prm})
+				}
+			}
+		}
+		if n.Args.Func != `` && len(targets) == found {
+			return nil, diag.Errorf(diag.CodeFuncNotFound, fns[0].Pos, `%w %s of %s`, ErrParamNotFound, cond.First(n.Args.Param, n.Args.MatchType).(string), fns[0]).
+				WithFix(`check the parameter name of --param or the type of -t`)
+		}
+	}
+	if len(targets) == 0 && n.Args.Func != `` {
+		return nil, diag.Errorf(diag.CodeFuncNotFound, diag.Position{}, `%w %s`, ErrFuncNotFound, n.Args.Func).
+			WithFix(`name a function "Handle" or a method "Server.Handle" declared in the packages`)
+	} else if len(targets) == 0 {
+		return nil, diag.Errorf(diag.CodeFuncNotFound, diag.Position{}, `%w of type %s`, ErrParamNotFound, n.Args.MatchType).
+			WithFix(`check that -t is written as in the function declarations, E.G. '*store.Store'`)
+	}
+	return targets, nil
+}
+
+// typeDir returns the directory of the package declaring the parameter type.
+// sel is the package name of the type in the consumer or empty.
+func (n Narrow) typeDir(t target, sel string) (string, error) {
+	if sel == `` {
+		return t.pkg.dir, nil
+	}
+	imp, ok := t.fn.Imports[sel]
+	if !ok {
+		return ``, fmt.Errorf(`%w %s: no import named %s`, ErrParamType, t.param.Type, sel)
+	}
+	dir, err := modinfo.ImportDir(t.pkg.dir, imp)
+	if err != nil {
+		return ``, fmt.Errorf(`can not find package directory %s: %w`, imp, err)
+	}
+	diag.Explainf(n.Print, `type %s declared in %s`, t.param.Type, dir)
+	return dir, nil
+}
+
+// methods returns the names of the called methods of typ in the order they are
+// first called. Selected names which are not exported methods are reported.
+func (n Narrow) methods(parsed *parser.Parser, typ string, called *calls) (methods []string) {
+	declared := map[string]bool{}
+	for _, m := range parser.NewQuery(parsed).GetRecvsByType(typ) {
+		declared[m.Name] = true
+	}
+	for _, u := range called.uses {
+		if !declared[u.Name] {
+			diag.Warn(n.Reporter, n.Print, diag.New(diag.Warning, diag.CodeNotMethod, u.Pos,
+				fmt.Sprintf(`%s is not an exported method of %s, it can not be added to the interface`, u.Name, typ),
+			).WithFix(`add a method to `+typ+` which returns `+u.Name))
+			continue
+		}
+		diag.Explainf(n.Print, `%s called at %s`, u.Name, u.Pos)
+		methods = append(methods, u.Name)
+	}
+	return
+}
+
+// ownDoc replaces the document of typ, the interface gets a document of its
+// own instead of the provider document and its "Deprecated:" notice
+func (n Narrow) ownDoc(parsed *parser.Parser, typ string, targets []target) {
+	var fns []string
+	seen := map[string]bool{}
+	for _, t := range targets {
+		if fn := t.fn.String(); t.param.Type == targets[0].param.Type && !seen[fn] {
+			seen[fn] = true
+			fns = append(fns, fn)
+		}
+	}
+	for i := range parsed.Types {
+		if parsed.Types[i].Name == typ {
+			parsed.Types[i].Doc = fmt.Sprintf(`%s has the methods of %s called by %s`, typ, strings.TrimPrefix(targets[0].param.Type, `*`), strings.Join(fns, `, `))
+		}
+	}
+}
+
+// consumerBuild returns the build constraint of the consumer files declaring
+// the functions of targets. The interface is needed wherever one of the
+// functions is compiled, a function in a file without a constraint needs no
+// constraint.
+func (n Narrow) consumerBuild(targets []target) (string, error) {
+	var expr constraint.Expr
+	seen := map[string]bool{}
+	for _, t := range targets {
+		file := t.fn.Pos.File
+		if seen[file] || t.param.Type != targets[0].param.Type {
+			continue
+		}
+		seen[file] = true
+		parsed, err := parser.Parse(file, nil, 0)
+		if err != nil {
+			return ``, err
+		}
+		build := parsed.Builds[filepath.Base(file)]
+		if build == `` {
+			return ``, nil
+		}
+		e, err := constraint.Parse(`//go:build ` + build)
+		if err != nil {
+			return ``, err
+		}
+		if expr == nil {
+			expr = e
+		} else {
+			expr = &constraint.OrExpr{X: expr, Y: e}
+		}
+	}
+	if expr == nil {
+		return ``, nil
+	}
+	diag.Explainf(n.Print, `build constraint %s of the consumer files`, expr)
+	return expr.String(), nil
+}
+
+// ifaceName returns the default interface name
+func (n Narrow) ifaceName(typ string) string {
+	if n.Args.Param != `` {
+		return stringx.PascalCase(n.Args.Param) + `Iface`
+	}
+	return typ + `Iface`
+}
+
+// calls collects the names selected on a parameter. Parameters passed to
+// functions of the same package are followed.
+type calls struct {
+	n     Narrow
+	seen  map[string]bool
+	names map[string]bool
+	uses  []uses.Use
+}
+
+// follow adds the uses of param in fn
+func (c *calls) follow(p *pkg, fn *uses.Func, param uses.Param) {
+	key := fn.Pos.String() + `:` + param.Name
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	if c.names == nil {
+		c.names = map[string]bool{}
+	}
+	for _, u := range fn.Uses(param.Name) {
+		switch u.Kind {
+		case uses.Call, uses.Select:
+			if !c.names[u.Name] {
+				c.names[u.Name] = true
+				c.uses = append(c.uses, u)
+			}
+		case uses.Compare, uses.Set:
+		case uses.Arg:
+			callee := p.parsed.Func(u.Name)
+			if callee != nil && u.Index < len(callee.Params) && callee.Params[u.Index].Type == param.Type {
+				diag.Explainf(c.n.Print, `%s: %s passed to %s at %s`, fn, param.Name, callee, u.Pos)
+				c.follow(p, callee, callee.Params[u.Index])
+				continue
+			}
+			diag.Warn(c.n.Reporter, c.n.Print, diag.New(diag.Warning, diag.CodeUntrackedUse, u.Pos,
+				fmt.Sprintf(`%s: %s is passed to %s, the methods called there are not added`, fn, param.Name, u.Name),
+			).WithFix(`narrow the parameter of `+u.Name+` too or add the methods with -a`))
+		default:
+			diag.Warn(c.n.Reporter, c.n.Print, diag.New(diag.Warning, diag.CodeUntrackedUse, u.Pos,
+				fmt.Sprintf(`%s: %s is %s, the methods called on copies are not added`, fn, param.Name, useKind(u.Kind)),
+			).WithFix(`call the methods on `+param.Name+` directly`))
+		}
+	}
+}
+
+// useKind describes a use kind in a warning
+func useKind(kind int) string {
+	switch kind {
+	case uses.Assign:
+		return `assigned`
+	case uses.Assert:
+		return `type asserted`
+	}
+	return `used as a value`
+}
+
+// splitType returns the package name and type name of a parameter type, E.G.
+// "*store.Store" returns "store" and "Store"
+func splitType(expr string) (sel, typ string, err error) {
+	sel, typ, ok := uses.SplitType(expr)
+	if !ok {
+		return ``, ``, fmt.Errorf(`%w %s, expected a named type or a pointer to a named type`, ErrParamType, expr)
+	}
+	return sel, typ, nil
+}
@@ -0,0 +1,200 @@
+package narrow
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/stretchr/testify/assert"
+)
+
+var store = `package store
+
+import "context"
+
+// Store stores users
+type Store struct {
+	Name string
+}
+
+// Get gets a user
+func (s *Store) Get(ctx context.Context, id string) (string, error) { return "", nil }
+
+// List lists users
+func (s *Store) List(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (s *Store) Delete(ctx context.Context, id string) error { return nil }
+
+func (s *Store) Close() error { return nil }
+`
+
+var handler = `package handler
+
+import (
+	"context"
+
+	"example.com/app/store"
+)
+
+type Server struct{}
+
+func (s *Server) Handle(ctx context.Context, st *store.Store, id string) error {
+	if st == nil {
+		return nil
+	}
+	_, err := st.Get(ctx, id)
+	_ = st.Name
+	audit(ctx, st)
+	keep(st)
+	return err
+}
+
+func audit(ctx context.Context, st *store.Store) {
+	st.List(ctx)
+}
+
+func Cleanup(st *store.Store) error {
+	return st.Close()
+}
+
+func keep(v any) {}
+`
+
+func TestNarrow_Generate_Func(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`:             "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`:     store,
+		`handler/handler.go`: handler,
+	})
+	reporter := &diag.Reporter{}
+	n := Narrow{
+		Args:     &cli.Args{Cmt: `DO NOT EDIT`, Func: `(*Server).Handle`, Param: `st`},
+		NewGen:   generate.FromArgs,
+		Reporter: reporter,
+	}
+	out := &bytes.Buffer{}
+	err := n.Generate([]string{filepath.Join(root, `handler`)}, &bytes.Buffer{}, ``, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+package handler
+
+import "context"
+
+// StIface has the methods of store.Store called by Server.Handle
+type StIface interface {
+	// Get gets a user
+	Get(ctx context.Context, id string) (string, error)
+	// List lists users
+	List(ctx context.Context) ([]string, error)
+}
+`
+	assert.Equal(t, expected, out.String())
+	var codes []string
+	for _, d := range reporter.List() {
+		codes = append(codes, d.Code)
+	}
+	assert.Equal(t, []string{diag.CodeUntrackedUse, diag.CodeNotMethod}, codes)
+}
+
+func TestNarrow_Generate_Type(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`:             "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`:     store,
+		`handler/handler.go`: handler,
+	})
+	n := Narrow{
+		Args:   &cli.Args{MatchType: `*Store`, Iface: `Store`},
+		NewGen: generate.FromArgs,
+	}
+	out := &bytes.Buffer{}
+	err := n.Generate([]string{filepath.Join(root, `...`)}, &bytes.Buffer{}, ``, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `type Store interface {
+	// Get gets a user
+	Get(ctx context.Context, id string) (string, error)
+	// List lists users
+	List(ctx context.Context) ([]string, error)
+	Close() error
+}`)
+}
+
+func TestNarrow_Generate_NotFound(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`handler/handler.go`: handler,
+	})
+	n := Narrow{
+		Args:   &cli.Args{Func: `handler.Unknown`, Param: `st`},
+		NewGen: generate.FromArgs,
+	}
+	err := n.Generate([]string{filepath.Join(root, `handler`)}, &bytes.Buffer{}, ``, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFuncNotFound)
+
+	n.Args = &cli.Args{Func: `handler.Cleanup`, Param: `store`}
+	err = n.Generate([]string{filepath.Join(root, `handler`)}, &bytes.Buffer{}, ``, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrParamNotFound)
+
+	n.Args = &cli.Args{Func: `keep`, Param: `v`}
+	err = n.Generate([]string{filepath.Join(root, `handler`)}, &bytes.Buffer{}, ``, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrNoMethods)
+}
+
+func TestNarrow_Generate_Consumer(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`go.mod`: "module example.com/app\n\ngo 1.19\n",
+		`store/store.go`: `//go:build !js
+
+package store
+
+// Store stores users
+//
+// Deprecated: use the v2 store
+type Store struct{}
+
+// Get gets a user
+func (s *Store) Get(id string) string { return "" }
+
+func (s *Store) Close() error { return nil }
+`,
+		`handler/handler.go`: `//go:build !plan9
+
+package handler
+
+import "example.com/app/store"
+
+func Handle(st *store.Store) string {
+	return st.Get("id")
+}
+`,
+	})
+	n := Narrow{
+		Args:   &cli.Args{Cmt: `DO NOT EDIT`, Func: `Handle`, Param: `st`},
+		NewGen: generate.FromArgs,
+	}
+	out := &bytes.Buffer{}
+	err := n.Generate([]string{filepath.Join(root, `handler`)}, &bytes.Buffer{}, ``, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// DO NOT EDIT
+
+//go:build !plan9
+
+package handler
+
+// StIface has the methods of store.Store called by Handle
+type StIface interface {
+	// Get gets a user
+	Get(id string) string
+}
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,324 @@
+// Package runner runs the ifaces go:generate directives of a module in
+// process.
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/modinfo"
+	"github.com/dexterp/ifaces/internal/resources/parser"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/stringx"
+	"github.com/dexterp/ifaces/internal/services/generate"
+)
+
+//go:generate ifaces type -o runner_iface.go -i RunnerIface
+
+var (
+	ErrNoCommand  = errors.New(`no ifaces command found in directive`)
+	ErrNoOutput   = errors.New(`directive has no output file`)
+	ErrConflict   = errors.New(`directives write the same output file`)
+	ErrSubCommand = errors.New(`sub command can not be used in a directive`)
+)
+
+// Runner runs go:generate directives
+type Runner struct {
+	Current func(file string) (*bytes.Buffer, error) // Current returns a previously generated file when appending
+	Jobs    int                                      // Jobs number of directives run concurrently. Defaults to the number of CPUs.
+	NewGen  func(args *cli.Args) *generate.Generate  // NewGen creates a generator from the directive options
+	Print   print.PrintIface                         // Print handler, see --explain
+}
+
+// directive an ifaces go:generate directive
+type directive struct {
+	dir  string    // dir package directory
+	file string    // file source file of the directive
+	line int       // line line number of the directive
+	args *cli.Args // args options from the directive
+	out  string    // out output file relative to the working directory
+	pkg  *pkg      // pkg package of the directive
+}
+
+// Run runs the ifaces go:generate directives in the packages matching
+// patterns. Packages are expanded with paths.PackageDirs and each package is
+// parsed once. Directives writing to the same output file, or to the
+// directory of an output file template, run in sequence in the order of go
+// generate, other directives run concurrently. Output files written by
+// directives which run concurrently are a conflict. Directives of
+// other commands which mention ifaces, E.G. "mockgen -source=store_ifaces.go",
+// are skipped. The generated source is returned in a map keyed by the file
+// name.
+func (r Runner) Run(patterns []string) (map[string]*bytes.Buffer, error) {
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	c := &cache{}
+	var directives []*directive
+	for _, dir := range dirs {
+		p, err := c.get(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, cmt := range p.parsed.Comments {
+			d, err := r.directive(c, dir, p, cmt)
+			if errors.Is(err, ErrNoCommand) {
+				diag.Explainf(r.Print, `%s:%d: skipped, the directive does not run ifaces: %s`, cmt.File, cmt.Line, cmt.Text)
+				continue
+			} else if err != nil {
+				return nil, diag.At(diag.Position{File: cmt.File, Line: cmt.Line}, err)
+			}
+			directives = append(directives, d)
+		}
+	}
+	chains := chain(directives)
+	results := make([]generate.Files, len(chains))
+	errs := make([]error, len(chains))
+	jobs := make(chan int)
+	wg := &sync.WaitGroup{}
+	workers := r.Jobs
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = r.runChain(chains[i])
+			}
+		}()
+	}
+	for i := range chains {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	files := map[string]*bytes.Buffer{}
+	owners := map[string]int{}
+	for i := range chains {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for file, buf := range results[i] {
+			if o, ok := owners[file]; ok {
+				first, second := chains[o][0], chains[i][0]
+				return nil, diag.Errorf(diag.CodeUsage, diag.Position{File: second.file, Line: second.line}, `%w %s, %s:%d writes it too`, ErrConflict, file, first.file, first.line).
+					WithFix(`write the output files of the directives to different files or directories`)
+			}
+			owners[file] = i
+			files[file] = buf
+		}
+	}
+	return files, nil
+}
+
+// directive parses a go:generate comment. The directive is split and
+// expanded in the same way as go generate and the arguments following the
+// ifaces command are parsed as ifaces options.
+func (r Runner) directive(c *cache, dir string, p *pkg, cmt parser.Comment) (*directive, error) {
+	fields, err := stringx.SplitArgs(strings.TrimPrefix(cmt.Text, `//go:generate`))
+	if err != nil {
+		return nil, err
+	}
+	cmd := -1
+	for i, f := range fields {
+		fields[i] = os.Expand(f, func(v string) string {
+			switch v {
+			case `GOFILE`:
+				return filepath.Base(cmt.File)
+			case `GOLINE`:
+				return strconv.Itoa(cmt.Line)
+			case `GOPACKAGE`:
+				return p.parsed.Package
+			case `DOLLAR`:
+				return `$`
+			}
+			return os.Getenv(v)
+		})
+		if base := path.Base(fields[i]); cmd < 0 && (base == `ifaces` || strings.HasPrefix(base, `ifaces@`)) {
+			cmd = i
+		}
+	}
+	if cmd < 0 {
+		return nil, ErrNoCommand
+	}
+	args, err := cli.ParseArgs(fields[cmd+1:], ``, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid directive options: %w`, err)
+	} else if args == nil || !(args.CmdType || args.CmdStruct || args.CmdFunc) {
+		return nil, ErrSubCommand
+	}
+	d := &directive{
+		dir:  dir,
+		file: cmt.File,
+		line: cmt.Line,
+		args: args,
+		pkg:  p,
+	}
+	if args.OutTmpl != `` {
+		args.OutTmpl = filepath.Join(dir, args.OutTmpl)
+	} else if args.Out == `` {
+		return nil, ErrNoOutput
+	} else {
+		d.out = args.Out
+		if !filepath.IsAbs(d.out) {
+			d.out = filepath.Join(dir, d.out)
+		}
+	}
+	if args.HeaderFile != `` && !filepath.IsAbs(args.HeaderFile) {
+		args.HeaderFile = filepath.Join(dir, args.HeaderFile)
+	}
+	if args.Src != `` {
+		d.pkg, err = r.srcPkg(c, dir, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// srcPkg returns the package of the -f option. The path is relative to the
+// module directory if -x is set, otherwise to the directive directory.
+func (r Runner) srcPkg(c *cache, dir string, args *cli.Args) (*pkg, error) {
+	src := args.Src
+	if args.Module != `` {
+		mi, err := modinfo.LoadFromParents(dir)
+		if err != nil {
+			return nil, fmt.Errorf(`error loading go.mod file: %w`, err)
+		}
+		modpath, err := mi.GetPath(args.Module)
+		if err != nil {
+			return nil, fmt.Errorf(`can not find module directory %s: %w`, args.Module, err)
+		}
+		src = filepath.Join(modpath, src)
+	} else if !filepath.IsAbs(src) {
+		src = filepath.Join(dir, src)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		src = filepath.Dir(src)
+	}
+	return c.get(filepath.Clean(src))
+}
+
+// runChain runs directives in sequence
+func (r Runner) runChain(ds []*directive) (generate.Files, error) {
+	files := generate.Files{}
+	for _, d := range ds {
+		gen := r.NewGen(d.args)
+		gen.Parse = func([]srcio.Source) (*parser.Parser, error) {
+			return d.pkg.parsed, nil
+		}
+		err := files.Add(gen, d.srcs(), d.out, d.args.Append, r.Current)
+		if err != nil {
+			return nil, diag.At(diag.Position{File: d.file, Line: d.line}, err)
+		}
+	}
+	return files, nil
+}
+
+// srcs returns the sources of the directive. The line of the directive is set
+// on its source file unless the sources are from the -f option.
+func (d directive) srcs() []srcio.Source {
+	srcs := append([]srcio.Source{}, d.pkg.srcs...)
+	if d.args.Src != `` {
+		return srcs
+	}
+	for i := range srcs {
+		if srcs[i].File == d.file {
+			srcs[i].Line = d.line
+		}
+	}
+	return srcs
+}
+
+// chain groups directives that write to the same output file. Directives using
+// an output file template are grouped by the directory of the template with
+// the directives writing an output file to the directory, the names of the
+// files of a template are only known once it runs. Chains are in the order of
+// their first directive.
+func chain(ds []*directive) (chains [][]*directive) {
+	sort.SliceStable(ds, func(i, j int) bool {
+		if ds[i].file != ds[j].file {
+			return ds[i].file < ds[j].file
+		}
+		return ds[i].line < ds[j].line
+	})
+	tmplDirs := map[string]bool{}
+	for _, d := range ds {
+		if d.out == `` {
+			tmplDirs[filepath.Dir(d.args.OutTmpl)] = true
+		}
+	}
+	index := map[string]int{}
+	for _, d := range ds {
+		key := d.out
+		if key == `` {
+			key = `dir:` + filepath.Dir(d.args.OutTmpl)
+		} else if tmplDirs[filepath.Dir(d.out)] {
+			key = `dir:` + filepath.Dir(d.out)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(chains)
+			index[key] = i
+			chains = append(chains, nil)
+		}
+		chains[i] = append(chains[i], d)
+	}
+	return
+}
+
+// pkg a parsed package
+type pkg struct {
+	srcs   []srcio.Source
+	parsed *parser.Parser
+	err    error
+	once   sync.Once
+}
+
+// cache parsed packages by directory
+type cache struct {
+	mu   sync.Mutex
+	pkgs map[string]*pkg
+}
+
+// get returns the parsed package in dir
+func (c *cache) get(dir string) (*pkg, error) {
+	c.mu.Lock()
+	if c.pkgs == nil {
+		c.pkgs = map[string]*pkg{}
+	}
+	p, ok := c.pkgs[dir]
+	if !ok {
+		p = &pkg{}
+		c.pkgs[dir] = p
+	}
+	c.mu.Unlock()
+	p.once.Do(func() {
+		p.srcs, p.err = srcio.ReadDir(dir)
+		if p.err == nil {
+			p.parsed, p.err = parser.ParseFiles(p.srcs)
+		}
+	})
+	return p, p.err
+}
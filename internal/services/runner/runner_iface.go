@@ -0,0 +1,18 @@
+// Code generated by ifaces DO NOT EDIT.
+
+package runner
+
+import "bytes"
+
+// RunnerIface runs go:generate directives
+type RunnerIface interface {
+	// Run runs the ifaces go:generate directives in the packages matching
+	// patterns. Packages are expanded with paths.PackageDirs and each package is
+	// parsed once. Directives writing to the same output file, or to the directory
+	// of an output file template, run in sequence in the order of go generate,
+	// other directives run concurrently. Output files written by directives which
+	// run concurrently are a conflict. Directives of other commands which mention
+	// ifaces, E.G. "mockgen -source=store_ifaces.go", are skipped. The generated
+	// source is returned in a map keyed by the file name.
+	Run(patterns []string) (map[string]*bytes.Buffer, error)
+}
@@ -0,0 +1,226 @@
+package runner
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/dexterp/ifaces/internal/services/generate"
+	"github.com/stretchr/testify/assert"
+)
+
+var src = `package store
+
+//` + `go:generate ifaces type -o ifaces.go -i StoreIface
+
+// Store stores items
+type Store struct{}
+
+// Get gets an item
+func (s *Store) Get(id string) error { return nil }
+
+//` + `go:generate go run github.com/dexterp/ifaces/cmd/ifaces type -o ifaces.go -a -i CacheIface
+
+// Cache caches items
+type Cache struct{}
+
+// Put puts an item
+func (c Cache) Put(id string) {}
+
+//` + `go:generate ifaces func -o ${GOPACKAGE}_func.go -i Closer
+
+// Close closes the cache
+func (c Cache) Close() {}
+`
+
+var other = `package other
+
+//` + `go:generate ifaces type -o ifaces.go -a -i OtherIface -f ../store/store.go -t Store
+`
+
+func TestRunner_Run(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`store/store.go`: src,
+		`other/other.go`: other,
+	})
+	r := &Runner{
+		Current: func(file string) (*bytes.Buffer, error) {
+			return bytes.NewBufferString("// Code generated by ifaces DO NOT EDIT.\n\npackage other\n\ntype Iface interface {\n\tClose()\n}\n"), nil
+		},
+		Jobs:   2,
+		NewGen: generate.FromArgs,
+	}
+	files, err := r.Run([]string{root + `/...`})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, files, 3) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+// StoreIface stores items
+type StoreIface interface {
+	// Get gets an item
+	Get(id string) error
+}
+
+// CacheIface caches items
+type CacheIface interface {
+	// Put puts an item
+	Put(id string)
+	// Close closes the cache
+	Close()
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(root, `store`, `ifaces.go`)].String())
+	expected = `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+type Closer interface {
+	// Close closes the cache
+	Close()
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(root, `store`, `store_func.go`)].String())
+	expected = `// Code generated by ifaces DO NOT EDIT.
+
+package other
+
+type Iface interface {
+	Close()
+}
+
+// OtherIface stores items
+type OtherIface interface {
+	// Get gets an item
+	Get(id string) error
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(root, `other`, `ifaces.go`)].String())
+}
+
+func TestRunner_Run_OutTemplate(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`store/store.go`: `package store
+
+//` + `go:generate ifaces type --out-template {{.Type|snake}}_iface.go -i StoreIface -f store.go -t Store
+
+type Store struct{}
+
+func (s *Store) Get(id string) error { return nil }
+
+//` + `go:generate ifaces type -o store_iface.go -a -i CacheIface
+
+type Cache struct{}
+
+func (c Cache) Put(id string) {}
+`,
+	})
+	r := &Runner{
+		Current: func(file string) (*bytes.Buffer, error) {
+			return &bytes.Buffer{}, nil
+		},
+		Jobs:   2,
+		NewGen: generate.FromArgs,
+	}
+	files, err := r.Run([]string{filepath.Join(root, `store`)})
+	if !assert.NoError(t, err) || !assert.Len(t, files, 1) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+type StoreIface interface {
+	Get(id string) error
+}
+
+type CacheIface interface {
+	Put(id string)
+}
+`
+	assert.Equal(t, expected, files[filepath.Join(root, `store`, `store_iface.go`)].String())
+}
+
+func TestRunner_Run_Conflict(t *testing.T) {
+	root := testsrcs.Write(t, map[string]string{
+		`store/store.go`: `package store
+
+//` + `go:generate ifaces type --out-template ../{{.Pkg}}/ifaces.go -i StoreIface -f store.go -t Store
+
+type Store struct{}
+
+func (s *Store) Get(id string) error { return nil }
+
+//` + `go:generate ifaces type -o ifaces.go -i CacheIface
+
+type Cache struct{}
+
+func (c Cache) Put(id string) {}
+`,
+	})
+	r := &Runner{NewGen: generate.FromArgs}
+	_, err := r.Run([]string{filepath.Join(root, `store`)})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestRunner_Run_OtherCommands(t *testing.T) {
+	src := `package store
+
+//` + `go:generate ifaces type -o store_ifaces.go -i StoreIface
+
+// Store stores items
+type Store struct{}
+
+// Get gets an item
+func (s *Store) Get(id string) error { return nil }
+
+//` + `go:generate mockgen -source=store_ifaces.go -destination=mocks/store.go
+`
+	root := testsrcs.Write(t, map[string]string{`store/store.go`: src})
+	files, err := Runner{NewGen: generate.FromArgs}.Run([]string{root + `/...`})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, files, 1)
+	assert.Contains(t, files[filepath.Join(root, `store`, `store_ifaces.go`)].String(), `type StoreIface interface`)
+}
+
+func TestRunner_Run_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  error
+	}{
+		{
+			name: `no output`,
+			src:  "package store\n\n//" + "go:generate ifaces type -i Iface\ntype Store struct{}\n",
+			err:  ErrNoOutput,
+		},
+		{
+			name: `sub command`,
+			src:  "package store\n\n//" + "go:generate ifaces run ./...\n",
+			err:  ErrSubCommand,
+		},
+		{
+			name: `type not found`,
+			src:  "package store\n\n//" + "go:generate ifaces type -o x.go -p store -i Iface -f store.go -t Missing\n",
+			err:  generate.ErrTypeNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testsrcs.Write(t, map[string]string{`store.go`: tt.src})
+			_, err := Runner{NewGen: generate.FromArgs}.Run([]string{root})
+			assert.ErrorIs(t, err, tt.err)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), `store.go:3:`)
+			}
+		})
+	}
+}
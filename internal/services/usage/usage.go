@@ -0,0 +1,274 @@
+// Package usage reports how the interfaces of packages are used: the types
+// implementing them, the mocks, and the methods which are never called
+// through the interface. Interfaces with a single implementation and no mocks
+// are candidates for removal.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/diag"
+	"github.com/dexterp/ifaces/internal/resources/match"
+	"github.com/dexterp/ifaces/internal/resources/methodset"
+	"github.com/dexterp/ifaces/internal/resources/paths"
+	"github.com/dexterp/ifaces/internal/resources/print"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/resources/uses"
+)
+
+//go:generate ifaces type -o usage_iface.go -i UsageIface
+
+// Output formats
+const (
+	FormatTable    = `table`
+	FormatJSON     = `json`
+	FormatMarkdown = `markdown`
+)
+
+// Formats output formats
+var Formats = []string{FormatTable, FormatJSON, FormatMarkdown}
+
+var ErrFormat = errors.New(`invalid usage format`)
+
+// Usage reports the usage of interfaces
+type Usage struct {
+	Args     *cli.Args        // Args options of the usage sub command
+	Print    print.PrintIface // Print handler
+	Reporter *diag.Reporter   // Reporter collects warnings instead of printing them with Print
+}
+
+// Report usage of an interface
+type Report struct {
+	Interface       string   `json:"interface"`       // Interface qualified interface name, E.G. "print.PrintIface"
+	Position        string   `json:"position"`        // Position position of the interface declaration
+	Implementations []string `json:"implementations"` // Implementations types implementing the interface which are not mocks
+	Mocks           []string `json:"mocks"`           // Mocks mocks implementing the interface
+	Uncalled        []string `json:"uncalled"`        // Uncalled methods never called through the interface
+	Candidate       bool     `json:"candidate"`       // Candidate true if the interface has one implementation and no mocks
+}
+
+// Analyse writes the usage of the interfaces declared in the packages
+// matching patterns in the --format format. Types declared in test files and
+// types or packages named like a mock, E.G. MockStore or package mocks, are
+// mocks. Methods are called through an interface when they are called on a
+// parameter, struct field, function result or local variable of the interface
+// type. A local variable has the type it is declared with, converted or
+// asserted to, E.G. "var g Getter = Impl{}".
+func (u Usage) Analyse(patterns []string, output io.Writer) error {
+	format := u.Args.Format
+	if format == `` {
+		format = FormatTable
+	}
+	if !cond.EqualAnyString(format, Formats...) {
+		return diag.Errorf(diag.CodeUsage, diag.Position{}, `%w "%s", expected one of %s`, ErrFormat, format, strings.Join(Formats, `, `))
+	}
+	dirs, err := paths.PackageDirs(patterns...)
+	if err != nil {
+		return err
+	}
+	pkgs, err := methodset.Load(dirs)
+	if err != nil {
+		return err
+	}
+	tests, err := methodset.LoadTests(dirs)
+	if err != nil {
+		return err
+	}
+	ifaces, concretes := methodset.Declarations(pkgs, func(dg *diag.Diagnostic) { diag.Warn(u.Reporter, u.Print, dg) })
+	_, testConcretes := methodset.Declarations(tests, func(*diag.Diagnostic) {})
+	for _, c := range testConcretes {
+		if strings.HasSuffix(c.Type.File, `_test.go`) {
+			concretes = append(concretes, c)
+		}
+	}
+	called, err := u.calls(dirs, ifaces)
+	if err != nil {
+		return err
+	}
+	var reports []Report
+	for _, ifc := range ifaces {
+		r := Report{
+			Interface: ifc.String(),
+			Position:  ifc.Pos().String(),
+		}
+		for _, c := range concretes {
+			if !methodset.Compare(c, ifc, true).Ok() {
+				continue
+			} else if isMock(c) {
+				r.Mocks = append(r.Mocks, c.String())
+			} else {
+				r.Implementations = append(r.Implementations, c.String())
+			}
+		}
+		for _, m := range ifc.Methods {
+			if !called[ifc][m.Name] {
+				r.Uncalled = append(r.Uncalled, m.Name)
+			}
+		}
+		r.Candidate = len(r.Implementations) == 1 && len(r.Mocks) == 0
+		diag.Explainf(u.Print, `%s: %d implementations, %d mocks, %d methods never called`, r.Interface, len(r.Implementations), len(r.Mocks), len(r.Uncalled))
+		reports = append(reports, r)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Interface < reports[j].Interface
+	})
+	return write(output, format, reports)
+}
+
+// calls returns the names of the methods called through the interfaces keyed
+// by interface. Test files are included.
+func (u Usage) calls(dirs []string, ifaces []*methodset.Iface) (map[*methodset.Iface]map[string]bool, error) {
+	called := map[*methodset.Iface]map[string]bool{}
+	for _, ifc := range ifaces {
+		called[ifc] = map[string]bool{}
+	}
+	var parsed []*uses.Package
+	for _, dir := range dirs {
+		srcs, err := srcio.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		tests, err := srcio.ReadTestDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		p, err := uses.Parse(append(srcs, tests...))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	mark := func(ifc *methodset.Iface, us []uses.Use) {
+		for _, use := range us {
+			if use.Kind == uses.Call || use.Kind == uses.Select {
+				called[ifc][use.Name] = true
+			}
+		}
+	}
+	for n, p := range parsed {
+		for _, fn := range p.Funcs {
+			for _, prm := range fn.Params {
+				if ifc := find(ifaces, dirs[n], prm.Type); ifc != nil {
+					mark(ifc, fn.Uses(prm.Name))
+				}
+			}
+			for _, l := range fn.Locals {
+				if ifc := find(ifaces, dirs[n], l.Type); ifc != nil {
+					mark(ifc, fn.LocalUses(l))
+				}
+			}
+			for i, res := range fn.Results {
+				ifc := find(ifaces, dirs[n], res)
+				if ifc == nil {
+					continue
+				}
+				for _, o := range parsed {
+					if o == p || match.Capitalized(fn.Name) {
+						mark(ifc, o.ResultUses(fn.Name, i))
+					}
+				}
+			}
+		}
+		for _, st := range p.Structs {
+			for _, prm := range st.Fields {
+				ifc := find(ifaces, dirs[n], prm.Type)
+				if ifc == nil {
+					continue
+				}
+				for _, o := range parsed {
+					if o == p || match.Capitalized(prm.Name) {
+						mark(ifc, o.FieldUses(prm.Name))
+					}
+				}
+			}
+		}
+	}
+	return called, nil
+}
+
+// find returns the interface of the type expression expr declared in the
+// package in dir or nil. Qualified types are matched by package name.
+func find(ifaces []*methodset.Iface, dir, expr string) *methodset.Iface {
+	if strings.HasPrefix(expr, `*`) {
+		return nil
+	}
+	sel, typ, ok := uses.SplitType(expr)
+	if !ok {
+		return nil
+	}
+	for _, ifc := range ifaces {
+		if ifc.Type.Name != typ {
+			continue
+		} else if sel == `` && ifc.Pkg.Dir == dir || sel != `` && ifc.Pkg.Name == sel {
+			return ifc
+		}
+	}
+	return nil
+}
+
+// isMock returns true if the type is declared in a test file, or the type or
+// package is named like a mock
+func isMock(c *methodset.Concrete) bool {
+	if strings.HasSuffix(c.Type.File, `_test.go`) {
+		return true
+	}
+	for _, name := range []string{c.Type.Name, c.Pkg.Name} {
+		lower := strings.ToLower(name)
+		for _, m := range []string{`mock`, `fake`, `stub`} {
+			if strings.Contains(lower, m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// write writes the reports in format
+func write(output io.Writer, format string, reports []Report) error {
+	switch format {
+	case FormatJSON:
+		if reports == nil {
+			reports = []Report{}
+		}
+		enc := json.NewEncoder(output)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(reports)
+	case FormatMarkdown:
+		fmt.Fprintln(output, `| Interface | Implementations | Mocks | Never called | Candidate | Position |`)
+		fmt.Fprintln(output, `|-----------|-----------------|-------|--------------|-----------|----------|`)
+		for _, r := range reports {
+			fmt.Fprintf(output, "| %s | %s | %s | %s | %s | %s |\n", r.Interface, list(r.Implementations, `, `), list(r.Mocks, `, `),
+				list(r.Uncalled, `, `), yesNo(r.Candidate), r.Position)
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INTERFACE\tIMPLS\tMOCKS\tNEVER CALLED\tCANDIDATE\tPOSITION")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", r.Interface, len(r.Implementations), len(r.Mocks), list(r.Uncalled, `,`), yesNo(r.Candidate), r.Position)
+	}
+	return w.Flush()
+}
+
+// list joins names with sep or returns "-" if there are no names
+func list(names []string, sep string) string {
+	if len(names) == 0 {
+		return `-`
+	}
+	return strings.Join(names, sep)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return `yes`
+	}
+	return `no`
+}
@@ -0,0 +1,185 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dexterp/ifaces/internal/resources/cli"
+	"github.com/dexterp/ifaces/internal/resources/testtools/testsrcs"
+	"github.com/stretchr/testify/assert"
+)
+
+var storeSrc = `package store
+
+// StoreIface stores users
+type StoreIface interface {
+	Get(id string) (string, error)
+	List() ([]string, error)
+	Delete(id string) error
+}
+
+// ClockIface tells the time
+type ClockIface interface {
+	Now() int64
+}
+
+type Store struct{}
+
+func (s *Store) Get(id string) (string, error) { return "", nil }
+
+func (s *Store) List() ([]string, error) { return nil, nil }
+
+func (s *Store) Delete(id string) error { return nil }
+
+type Clock struct{}
+
+func (c Clock) Now() int64 { return 0 }
+
+type Cache struct{}
+
+func (c *Cache) Get(id string) (string, error) { return "", nil }
+
+func (c *Cache) List() ([]string, error) { return nil, nil }
+
+func (c *Cache) Delete(id string) error { return nil }
+
+func NewClock() ClockIface { return Clock{} }
+`
+
+var storeTestSrc = `package store_test
+
+type mockStore struct{}
+
+func (m *mockStore) Get(id string) (string, error) { return "", nil }
+
+func (m *mockStore) List() ([]string, error) { return nil, nil }
+
+func (m *mockStore) Delete(id string) error { return nil }
+`
+
+var handlerSrc = `package handler
+
+import "example.com/app/store"
+
+type Server struct {
+	st store.StoreIface
+}
+
+func (s *Server) Handle(id string) {
+	s.st.Get(id)
+	_ = store.NewClock().Now()
+}
+
+func list(st store.StoreIface) {
+	st.List()
+}
+`
+
+func analyse(t *testing.T, format string) string {
+	return analyseSrcs(t, format, map[string]string{
+		`store/store.go`:      storeSrc,
+		`store/store_test.go`: storeTestSrc,
+		`handler/handler.go`:  handlerSrc,
+	})
+}
+
+func analyseSrcs(t *testing.T, format string, srcs map[string]string) string {
+	root := testsrcs.Write(t, srcs)
+	u := Usage{Args: &cli.Args{Format: format}}
+	out := &bytes.Buffer{}
+	err := u.Analyse([]string{filepath.Join(root, `...`)}, out)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return strings.ReplaceAll(out.String(), root+string(filepath.Separator), ``)
+}
+
+func TestUsage_Analyse_JSON(t *testing.T) {
+	var reports []Report
+	err := json.Unmarshal([]byte(analyse(t, FormatJSON)), &reports)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := []Report{
+		{
+			Interface:       `store.ClockIface`,
+			Position:        filepath.Join(`store`, `store.go`) + `:11`,
+			Implementations: []string{`store.Clock`},
+			Candidate:       true,
+		},
+		{
+			Interface:       `store.StoreIface`,
+			Position:        filepath.Join(`store`, `store.go`) + `:4`,
+			Implementations: []string{`store.Store`, `store.Cache`},
+			Mocks:           []string{`store_test.mockStore`},
+			Uncalled:        []string{`Delete`},
+		},
+	}
+	assert.Equal(t, expected, reports)
+}
+
+func TestUsage_Analyse_Table(t *testing.T) {
+	expected := `INTERFACE         IMPLS  MOCKS  NEVER CALLED  CANDIDATE  POSITION
+store.ClockIface  1      0      -             yes        ` + filepath.Join(`store`, `store.go`) + `:11
+store.StoreIface  2      1      Delete        no         ` + filepath.Join(`store`, `store.go`) + `:4
+`
+	assert.Equal(t, expected, analyse(t, ``))
+}
+
+func TestUsage_Analyse_Markdown(t *testing.T) {
+	expected := `| Interface | Implementations | Mocks | Never called | Candidate | Position |
+|-----------|-----------------|-------|--------------|-----------|----------|
+| store.ClockIface | store.Clock | - | - | yes | ` + filepath.Join(`store`, `store.go`) + `:11 |
+| store.StoreIface | store.Store, store.Cache | store_test.mockStore | Delete | no | ` + filepath.Join(`store`, `store.go`) + `:4 |
+`
+	assert.Equal(t, expected, analyse(t, FormatMarkdown))
+}
+
+func TestUsage_Analyse_Locals(t *testing.T) {
+	out := analyseSrcs(t, FormatJSON, map[string]string{
+		`store/store.go`: `package store
+
+type Getter interface {
+	Get(id string) string
+	Put(id string)
+	Delete(id string)
+	Close() error
+}
+
+type Impl struct{}
+
+func (i Impl) Get(id string) string { return "" }
+func (i Impl) Put(id string)        {}
+func (i Impl) Delete(id string)     {}
+func (i Impl) Close() error         { return nil }
+`,
+		`handler/handler.go`: `package handler
+
+import "example.com/app/store"
+
+func Handle(v any) {
+	var g store.Getter = store.Impl{}
+	g.Put("x")
+	h := store.Getter(store.Impl{})
+	h.Get("x")
+	if d, ok := v.(store.Getter); ok {
+		d.Delete("x")
+	}
+}
+`,
+	})
+	var reports []Report
+	err := json.Unmarshal([]byte(out), &reports)
+	if assert.NoError(t, err) && assert.Len(t, reports, 1) {
+		assert.Equal(t, []string{`Close`}, reports[0].Uncalled)
+	}
+}
+
+func TestUsage_Analyse_Format(t *testing.T) {
+	u := Usage{Args: &cli.Args{Format: `xml`}}
+	err := u.Analyse([]string{t.TempDir()}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrFormat)
+}
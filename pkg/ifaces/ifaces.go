@@ -0,0 +1,149 @@
+// Package ifaces generates Go interfaces from the types and receiver methods
+// found in Go source files. It is the library used by the ifaces command and
+// allows interfaces to be generated in-process.
+//
+//	err := ifaces.Options{}.Run().
+//		Src(srcs, dests).
+//		Type(`Store`, `StoreIface`, ``, ``)
+package ifaces
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dexterp/ifaces/internal/resources/cond"
+	"github.com/dexterp/ifaces/internal/resources/srcio"
+	"github.com/dexterp/ifaces/internal/services/generate"
+)
+
+// DefaultComment comment added to the top of generated files if no comment is
+// set in Options.
+const DefaultComment = `Code generated by ifaces DO NOT EDIT.`
+
+var (
+	ErrNoOutput     = errors.New(`no destination output writer`)
+	ErrNoSource     = errors.New(`no source files`)
+	ErrRecvNotFound = generate.ErrRecvNotFound
+	ErrTypeNotFound = generate.ErrTypeNotFound
+)
+
+// Source a Go source file to parse. If Src is nil the source is read from File.
+// Line is the line number of a go:generate comment and is used to find the
+// type or method that follows it when no type is given.
+type Source = srcio.Source
+
+// Destination a generated file. File is used to determine the package name
+// unless Package is set. Current holds the contents of any previously generated
+// source which is added to, and the generated source is written to Output.
+type Destination = srcio.Destination
+
+// Options options for a run
+type Options struct {
+	Build      string   // Build build constraint of the output. Defaults to the constraints of the sources.
+	Comment    string   // Comment comment at the top of the file. Defaults to DefaultComment.
+	DocWidth   int      // DocWidth wrap width of documents, 76 if zero. A negative width keeps the line breaks.
+	Exclude    []string // Exclude omit methods matching any of the wildcards
+	Format     string   // Format output format, "go" or "json" for the model package JSON model. Defaults to "go".
+	FromFiles  []string // FromFiles only use methods declared in files matching any of the wildcards
+	HeaderFile string   // HeaderFile path of a file with a header, E.G. a license, added after the comment. Not the header text.
+	Include    []string // Include only use methods matching any of the wildcards
+	NoFuncDoc  bool     // NoFuncDoc omit copying function documentation
+	NoTypeDoc  bool     // NoTypeDoc omit copying type documentation
+	Post       string   // Post suffix added to interface names
+	Pre        string   // Pre prefix added to interface names
+}
+
+// Run return run struct
+func (o Options) Run() *Run {
+	return &Run{
+		Options: o,
+	}
+}
+
+// Run generates interfaces for a set of sources and destinations.
+type Run struct {
+	Options Options
+	srcs    []srcio.Source
+	dests   []srcio.Destination
+}
+
+// Src set the source files to parse and the destination files to generate. At
+// least one destination with an Output is needed.
+func (r *Run) Src(srcs []Source, dests []Destination) *Run {
+	r.srcs = srcs
+	r.dests = dests
+	return r
+}
+
+// Type generate an interface from the exported receiver methods of typ. typ
+// can be a wildcard. If typ is empty the first type after the go:generate line
+// of a source is used. iface is the interface name, pkg overrides the package
+// of the destinations and tdoc is a template which replaces the type document,
+// E.G. "{{.Name}} wraps {{.Source}}. {{.OrigDoc}}".
+func (r Run) Type(typ, iface, pkg, tdoc string) error {
+	return r.run(func(g *generate.Generate) {
+		g.Type = true
+		g.MatchType = typ
+		g.Iface = iface
+		g.Pkg = pkg
+		g.TDoc = tdoc
+	})
+}
+
+// Recv generate code by parsing receivers type and method. If typ is empty the
+// first method after the go:generate line of a source is used. iface is the
+// interface name, pkg overrides the package of the destinations and mdoc is a
+// template which replaces the method document.
+func (r Run) Recv(typ, method, iface, pkg, mdoc string) error {
+	return r.run(func(g *generate.Generate) {
+		g.Method = true
+		g.MatchType = typ
+		g.MatchFunc = method
+		g.Iface = iface
+		g.Pkg = pkg
+		g.FDoc = mdoc
+	})
+}
+
+// run generates each destination with a generator configured by setup.
+func (r Run) run(setup func(g *generate.Generate)) error {
+	if len(r.srcs) == 0 {
+		return ErrNoSource
+	} else if len(r.dests) == 0 {
+		return ErrNoOutput
+	}
+	for _, d := range r.dests {
+		if d.Output == nil {
+			return fmt.Errorf(`%s: %w`, d.File, ErrNoOutput)
+		}
+		g := r.generator()
+		setup(g)
+		g.Pkg = cond.First(g.Pkg, d.Package).(string)
+		current := d.Current
+		if current == nil {
+			current = &bytes.Buffer{}
+		}
+		err := g.Generate(r.srcs, current, d.File, d.Output)
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, d.File, err)
+		}
+	}
+	return nil
+}
+
+func (r Run) generator() *generate.Generate {
+	return &generate.Generate{
+		Build:      r.Options.Build,
+		Comment:    cond.First(r.Options.Comment, DefaultComment).(string),
+		DocWidth:   r.Options.DocWidth,
+		Exclude:    r.Options.Exclude,
+		FromFiles:  r.Options.FromFiles,
+		HeaderFile: r.Options.HeaderFile,
+		Include:    r.Options.Include,
+		NoFDoc:     r.Options.NoFuncDoc,
+		NoTDoc:     r.Options.NoTypeDoc,
+		Post:       r.Options.Post,
+		Pre:        r.Options.Pre,
+	}
+}
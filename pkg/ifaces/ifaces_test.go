@@ -0,0 +1,150 @@
+package ifaces
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var src = `package store
+
+import "context"
+
+// Store persists items
+type Store struct {
+}
+
+// Get get an item
+func (s *Store) Get(ctx context.Context, id string) (*Item, error) {
+	return nil, nil
+}
+
+// Put put an item
+func (s *Store) Put(ctx context.Context, item *Item) error {
+	return nil
+}
+
+func (s *Store) close() {
+}
+
+// Item stored item
+type Item struct {
+}
+`
+
+func TestRun_Type(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Options{}.Run().Src(
+		[]Source{{File: `store.go`, Src: src}},
+		[]Destination{{File: `store_iface.go`, Package: `store`, Output: out}},
+	).Type(`Store`, `StoreIface`, ``, ``)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+import "context"
+
+// StoreIface persists items
+type StoreIface interface {
+	// Get get an item
+	Get(ctx context.Context, id string) (*Item, error)
+	// Put put an item
+	Put(ctx context.Context, item *Item) error
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRun_Type_Current(t *testing.T) {
+	current := bytes.NewBufferString(`// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+type Closer interface {
+	Close() error
+}
+`)
+	out := &bytes.Buffer{}
+	err := Options{NoFuncDoc: true, NoTypeDoc: true}.Run().Src(
+		[]Source{{File: `store.go`, Src: src}},
+		[]Destination{{File: `store_iface.go`, Package: `store`, Current: current, Output: out}},
+	).Type(`Store`, `StoreIface`, ``, ``)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// Code generated by ifaces DO NOT EDIT.
+
+package store
+
+import "context"
+
+type Closer interface {
+	Close() error
+}
+
+type StoreIface interface {
+	Get(ctx context.Context, id string) (*Item, error)
+	Put(ctx context.Context, item *Item) error
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRun_Recv(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Options{Comment: `generated`}.Run().Src(
+		[]Source{{File: `store.go`, Src: src}},
+		[]Destination{{File: `getter.go`, Package: `store`, Output: out}},
+	).Recv(`Store`, `Get`, `Getter`, ``, `Get fetch an item`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `// generated
+
+package store
+
+import "context"
+
+type Getter interface {
+	// Get fetch an item
+	Get(ctx context.Context, id string) (*Item, error)
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRun_Errors(t *testing.T) {
+	srcs := []Source{{File: `store.go`, Src: src}}
+	err := Options{}.Run().Src(srcs, []Destination{{File: `out.go`, Output: &bytes.Buffer{}}}).Type(`Missing`, `Iface`, `store`, ``)
+	assert.ErrorIs(t, err, ErrTypeNotFound)
+	err = Options{}.Run().Src(srcs, []Destination{{File: `out.go`, Output: &bytes.Buffer{}}}).Recv(`Store`, `Missing`, `Iface`, `store`, ``)
+	assert.ErrorIs(t, err, ErrRecvNotFound)
+	err = Options{}.Run().Src(srcs, []Destination{{File: `out.go`}}).Type(`Store`, `Iface`, `store`, ``)
+	assert.ErrorIs(t, err, ErrNoOutput)
+	err = Options{}.Run().Src(srcs, nil).Type(`Store`, `Iface`, `store`, ``)
+	assert.ErrorIs(t, err, ErrNoOutput)
+	err = Options{}.Run().Src(nil, nil).Type(`Store`, `Iface`, `store`, ``)
+	assert.ErrorIs(t, err, ErrNoSource)
+}
+
+func TestRun_Type_HeaderFile(t *testing.T) {
+	header := filepath.Join(t.TempDir(), `LICENSE.hdr`)
+	if !assert.NoError(t, os.WriteFile(header, []byte("Copyright Example\n"), 0600)) {
+		t.FailNow()
+	}
+	out := &bytes.Buffer{}
+	err := Options{HeaderFile: header, NoFuncDoc: true, NoTypeDoc: true}.Run().Src(
+		[]Source{{File: `store.go`, Src: src}},
+		[]Destination{{File: `store_iface.go`, Package: `store`, Output: out}},
+	).Type(`Store`, `StoreIface`, ``, ``)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), "// Code generated by ifaces DO NOT EDIT.\n\n// Copyright Example\n")
+}
@@ -0,0 +1,67 @@
+// Package model is the JSON model of generated interfaces written by
+// "ifaces --format json". The model describes the interfaces ifaces would
+// generate, with the structured parameters, documents and source positions of
+// their methods.
+//
+// The model is also the spec read by "ifaces from-spec", in YAML or JSON, to
+// generate interfaces from a declarative description.
+//
+// The model is versioned. Fields are only added within a version, a field is
+// never removed, renamed or given a different meaning without incrementing
+// Version.
+package model
+
+// Version version of the model
+const Version = 1
+
+// File interfaces generated for one output file
+type File struct {
+	Version    int         `json:"version" yaml:"version"`                 // Version model version, see Version. Optional in a spec.
+	File       string      `json:"file,omitempty" yaml:"file,omitempty"`   // File output file name
+	Package    string      `json:"package" yaml:"package"`                 // Package package name of the output
+	Build      string      `json:"build,omitempty" yaml:"build,omitempty"` // Build build constraint expression
+	Imports    []Import    `json:"imports" yaml:"imports"`                 // Imports imports required by the interfaces
+	Interfaces []Interface `json:"interfaces" yaml:"interfaces"`           // Interfaces generated interfaces
+}
+
+// Import package import
+type Import struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"` // Name import name, empty unless the import is renamed
+	Path string `json:"path" yaml:"path"`                     // Path import path
+}
+
+// Interface generated interface
+type Interface struct {
+	Name       string   `json:"name" yaml:"name"`                                 // Name interface name
+	TypeParams string   `json:"typeParams,omitempty" yaml:"typeParams,omitempty"` // TypeParams type parameter list of a generic interface, E.G. "[T any]"
+	Doc        string   `json:"doc,omitempty" yaml:"doc,omitempty"`               // Doc document text without comment markers
+	Source     *Source  `json:"source,omitempty" yaml:"source,omitempty"`         // Source type the interface is generated from
+	Embeds     []string `json:"embeds,omitempty" yaml:"embeds,omitempty"`         // Embeds embedded interfaces
+	Methods    []Method `json:"methods" yaml:"methods"`                           // Methods interface methods
+}
+
+// Method interface method
+type Method struct {
+	Name      string  `json:"name" yaml:"name"`                         // Name method name
+	Signature string  `json:"signature" yaml:"signature"`               // Signature method signature as written in the interface. Optional in a spec, it is built from Params and Results if empty.
+	Doc       string  `json:"doc,omitempty" yaml:"doc,omitempty"`       // Doc document text without comment markers
+	Params    []Param `json:"params" yaml:"params"`                     // Params parameters
+	Results   []Param `json:"results" yaml:"results"`                   // Results results
+	Recv      string  `json:"recv,omitempty" yaml:"recv,omitempty"`     // Recv receiver kind, "pointer", "value" or empty for interface methods
+	Source    *Source `json:"source,omitempty" yaml:"source,omitempty"` // Source method the interface method is generated from
+}
+
+// Param method parameter or result
+type Param struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`         // Name parameter name, empty if unnamed
+	Type     string `json:"type" yaml:"type"`                             // Type type expression, the element type if variadic
+	Variadic bool   `json:"variadic,omitempty" yaml:"variadic,omitempty"` // Variadic true if the parameter is variadic
+	Pkg      string `json:"pkg,omitempty" yaml:"pkg,omitempty"`           // Pkg package of a named type
+}
+
+// Source position of the declaration an interface or method is generated from
+type Source struct {
+	Type string `json:"type" yaml:"type"`                     // Type type name
+	File string `json:"file,omitempty" yaml:"file,omitempty"` // File source file
+	Line int    `json:"line,omitempty" yaml:"line,omitempty"` // Line line number in the source file
+}
@@ -0,0 +1,85 @@
+// Package plugin is the protocol between ifaces and generator plugins. A
+// plugin is an executable started by "ifaces --plugin <exe>". ifaces writes a
+// JSON Request with the interfaces it found to the standard input of the
+// plugin and reads a JSON Response with the files to write from its standard
+// output. Anything the plugin writes to its standard error is reported if it
+// fails.
+//
+// A plugin written in Go only needs to call Main.
+//
+//	func main() {
+//		plugin.Main(func(req *plugin.Request) ([]plugin.File, error) {
+//			...
+//		})
+//	}
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dexterp/ifaces/pkg/model"
+)
+
+// Version version of the protocol
+const Version = 1
+
+// Request request sent to a plugin
+type Request struct {
+	Version int          `json:"version"` // Version protocol version, see Version
+	Options Options      `json:"options"` // Options ifaces options
+	Files   []model.File `json:"files"`   // Files interfaces of each output file
+}
+
+// Options ifaces options of a request
+type Options struct {
+	Command string `json:"command"`          // Command sub command, "type", "struct" or "func"
+	Iface   string `json:"iface,omitempty"`  // Iface interface name option
+	Type    string `json:"type,omitempty"`   // Type type name or wildcard option
+	Method  string `json:"method,omitempty"` // Method method name or wildcard option
+	Pkg     string `json:"pkg,omitempty"`    // Pkg package name option
+	Param   string `json:"param,omitempty"`  // Param plugin parameter, see --plugin-param
+}
+
+// Response response returned by a plugin
+type Response struct {
+	Files []File `json:"files"`           // Files files to write
+	Error string `json:"error,omitempty"` // Error error message if the plugin failed
+}
+
+// File file generated by a plugin. Name is relative to the working directory
+// of ifaces and can not be absolute or refer to a parent directory.
+type File struct {
+	Name    string `json:"name"`    // Name file name
+	Content string `json:"content"` // Content file content
+}
+
+// Run reads a request from r, calls gen and writes the response to w. An error
+// returned by gen is sent in the response.
+func Run(r io.Reader, w io.Writer, gen func(req *Request) ([]File, error)) error {
+	req := &Request{}
+	err := json.NewDecoder(r).Decode(req)
+	if err != nil {
+		return fmt.Errorf(`can not read request: %w`, err)
+	}
+	resp := &Response{}
+	resp.Files, err = gen(req)
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if resp.Files == nil {
+		resp.Files = []File{}
+	}
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// Main runs a plugin with the standard input and output
+func Main(gen func(req *Request) ([]File, error)) {
+	err := Run(os.Stdin, os.Stdout, gen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/author/mymodule
+
+go 1.19
\ No newline at end of file